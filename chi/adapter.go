@@ -10,7 +10,9 @@ import (
 	"reflect"
 
 	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/internal/capture"
 	"github.com/Infra-Forge/infra-apix/internal/errorhandler"
+	"github.com/Infra-Forge/infra-apix/openapi"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -23,27 +25,46 @@ type ResponseEncoder func(ctx context.Context, w http.ResponseWriter, r *http.Re
 // ErrorHandler handles errors from the typed handler.
 type ErrorHandler func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
 
-// Validator validates request payloads.
-type Validator interface {
-	Validate(any) error
-}
+// Validator validates request payloads. It is an alias of apix.Validator.
+type Validator = apix.Validator
 
 // Options configures adapter behaviour.
 type Options struct {
 	Decoder         RequestDecoder
 	ResponseEncoder ResponseEncoder
 	ErrorHandler    ErrorHandler
-	Validator       Validator
+	// Validator checks decoded requests after parameters are bound. Defaults to
+	// apix.DefaultValidator(), which enforces validate/binding struct tags. Validation also
+	// runs when a custom Decoder is set; use apix.NoValidation() to turn it off.
+	Validator Validator
+	// MaxMultipartMemory is the number of bytes of multipart/form-data file content kept in
+	// memory; the rest is stored in temporary files. Defaults to apix.DefaultMaxMultipartMemory.
+	MaxMultipartMemory int64
 	// UseProblemDetails enables RFC 9457 Problem Details encoding for errors.
 	// When enabled, errors implementing StatusCoder will be serialized as
 	// application/problem+json instead of plain text.
 	UseProblemDetails bool
+	// Registry receives route metadata for this adapter. Defaults to apix.DefaultRegistry().
+	// Use a dedicated registry per API surface to generate separate OpenAPI documents.
+	Registry *apix.Registry
+	// ResponseValidation checks every response against the generated document. Responses
+	// are buffered while it is set, so enable it in development and tests only. Streaming
+	// routes are not validated.
+	ResponseValidation *openapi.ResponseValidator
+	// Interceptors run around every handler of the adapter, before the interceptors of
+	// groups and routes.
+	Interceptors []apix.Interceptor
+	// AutoHead also answers HEAD requests for every GET route registered with Register
+	// or Get, using the GET handler's status and headers without the body, and documents
+	// the HEAD operations.
+	AutoHead bool
 }
 
 // ChiAdapter integrates apix route registration with chi.Router.
 type ChiAdapter struct {
-	r    chi.Router
-	opts Options
+	r     chi.Router
+	opts  Options
+	group apix.Group
 }
 
 // New constructs a ChiAdapter with optional overrides.
@@ -58,49 +79,93 @@ func New(r chi.Router, opts ...Options) *ChiAdapter {
 // Router exposes the underlying chi.Router instance.
 func (a *ChiAdapter) Router() chi.Router { return a.r }
 
+// Group returns an adapter registering routes below prefix on a chi sub-router created
+// with chi.Router.Route. See apix.Group for how opts and middleware apply.
+func (a *ChiAdapter) Group(prefix string, opts ...apix.RouteOption) *ChiAdapter {
+	var sub chi.Router
+	a.r.Route(prefix, func(r chi.Router) { sub = r })
+	return &ChiAdapter{r: sub, opts: a.opts, group: a.group.Group(prefix, opts...)}
+}
+
+// Use appends middleware to the adapter's router. chi requires middleware to be added
+// before the router's first route. See apix.Group for the security it documents.
+func (a *ChiAdapter) Use(middleware ...func(http.Handler) http.Handler) {
+	detect := make([]any, len(middleware))
+	for i, mw := range middleware {
+		detect[i] = mw
+	}
+	a.group = a.group.With(apix.MiddlewareSecurity(detect...))
+	a.r.Use(middleware...)
+}
+
+// UseSecured appends middleware marked with apix.SecuredBy, adding its security
+// requirement to the routes registered afterwards.
+func (a *ChiAdapter) UseSecured(middleware ...apix.SecuredMiddleware[func(http.Handler) http.Handler]) {
+	for _, mw := range middleware {
+		a.group = a.group.With(apix.MiddlewareSecurity(mw))
+		a.r.Use(mw.Middleware)
+	}
+}
+
+// Registry returns the route registry this adapter writes to.
+func (a *ChiAdapter) Registry() *apix.Registry {
+	if a.opts.Registry != nil {
+		return a.opts.Registry
+	}
+	return apix.DefaultRegistry()
+}
+
 // Register adds a handler for the provided method and path.
 func Register[TReq any, TResp any](a *ChiAdapter, method apix.RouteMethod, path string, handler apix.HandlerFunc[TReq, TResp], opts ...apix.RouteOption) {
 	if a == nil || a.r == nil {
 		panic("apix/chi: adapter not initialised")
 	}
 
-	ref := &apix.RouteRef{
-		Method:         method,
-		Path:           path,
-		Responses:      make(map[int]*apix.ResponseRef),
-		SuccessHeaders: make(map[int][]apix.HeaderRef),
-		HandlerType:    reflect.TypeOf(handler),
-	}
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
 
-	reqType := typeOf[TReq]()
-	if reqType != nil && !isNoBody(reqType) {
-		ref.RequestType = reqType
-		if ref.RequestContentType == "" {
-			ref.RequestContentType = "application/json"
-		}
+	respType := apix.ResponseBodyType(typeOf[TResp]())
+	if respType == nil {
+		respType = reflect.TypeOf(struct{}{})
 	}
+	apix.EnsureSuccessResponses(ref, respType)
 
-	for _, opt := range opts {
-		opt(ref)
+	if ref.OperationID == "" {
+		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	if ref.SuccessStatus == 0 {
-		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildChiHandler(a, handler, ref))
+
+	if method == apix.MethodGet && a.opts.AutoHead {
+		head := apix.HeadRoute(ref)
+		a.Registry().Register(head)
+		a.handle(head, path, buildChiHandler(a, handler, head))
 	}
+}
 
-	respType := typeOf[TResp]()
-	if respType == nil {
-		respType = reflect.TypeOf(struct{}{})
+// Stream adds a server-sent events handler for the provided method and path.
+func Stream[TReq any, TEvent any](a *ChiAdapter, method apix.RouteMethod, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	if a == nil || a.r == nil {
+		panic("apix/chi: adapter not initialised")
 	}
-	apix.EnsureResponse(ref, ref.SuccessStatus, respType)
+
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
+	apix.EnsureEventStreamResponse(ref, typeOf[TEvent]())
 
 	if ref.OperationID == "" {
 		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	apix.RegisterRoute(ref)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildChiStreamHandler(a, handler, ref))
+}
 
-	a.r.Method(string(method), path, buildChiHandler(a, handler, ref))
+// SSE registers a GET handler streaming server-sent events. TReq carries the route's
+// path/query/header parameters.
+func SSE[TReq any, TEvent any](a *ChiAdapter, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	Stream[TReq, TEvent](a, apix.MethodGet, path, handler, opts...)
 }
 
 // Get registers a GET handler.
@@ -128,40 +193,204 @@ func Delete[TResp any](a *ChiAdapter, path string, handler apix.HandlerFunc[apix
 	Register[apix.NoBody, TResp](a, apix.MethodDelete, path, handler, opts...)
 }
 
+// Head registers a HEAD handler. Its responses are documented without bodies.
+func Head[TResp any](a *ChiAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodHead, path, handler, opts...)
+}
+
+// OptionsRoute registers an OPTIONS handler. It is not called Options, the name of the
+// adapter configuration type.
+func OptionsRoute[TResp any](a *ChiAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodOptions, path, handler, opts...)
+}
+
+// Trace registers a TRACE handler.
+func Trace[TResp any](a *ChiAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodTrace, path, handler, opts...)
+}
+
+// handle routes method and path to h. chi only routes the standard methods, so others are
+// registered with it first.
+func (a *ChiAdapter) handle(ref *apix.RouteRef, path string, h http.Handler) {
+	middleware := routeMiddleware(ref)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	chi.RegisterMethod(string(ref.Method))
+	a.r.Method(string(ref.Method), path, h)
+}
+
+// routeMiddleware returns the middleware attached to ref with apix.WithMiddleware.
+func routeMiddleware(ref *apix.RouteRef) []func(http.Handler) http.Handler {
+	out := make([]func(http.Handler) http.Handler, 0, len(ref.Middleware))
+	for _, mw := range ref.Middleware {
+		fn, ok := apix.UnwrapMiddleware(mw).(func(http.Handler) http.Handler)
+		if !ok {
+			panic(fmt.Sprintf("apix/chi: middleware %T of %s %s is not func(http.Handler) http.Handler", mw, ref.Method, ref.Path))
+		}
+		out = append(out, fn)
+	}
+	return out
+}
+
 func buildChiHandler[TReq any, TResp any](a *ChiAdapter, handler apix.HandlerFunc[TReq, TResp], ref *apix.RouteRef) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	serve := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		var reqPtr *TReq
-		if ref.RequestType != nil {
-			reqVal := new(TReq)
-			if err := a.decode(ctx, w, r, reqVal); err != nil {
-				a.handleError(ctx, w, r, err)
-				return
-			}
-			reqPtr = reqVal
+		call := newCall(ref, r)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, w, r, ref, hasBody, call, interceptors)
+		if err != nil {
+			capture.Reject(w)
+			a.handleError(ctx, w, r, err)
+			return
 		}
 
-		resp, err := handler(ctx, reqPtr)
+		resp, err := apix.InterceptHandler(ctx, call, interceptors, reqPtr, handler)
 		if err != nil {
 			a.handleError(ctx, w, r, err)
 			return
 		}
 
-		if err := a.encode(ctx, w, r, ref.SuccessStatus, resp, ref); err != nil {
+		status, header, body, err := apix.ResolveResponse(resp, ref.SuccessStatus)
+		if err != nil {
+			a.handleError(ctx, w, r, err)
+			return
+		}
+		addHeaders(w.Header(), header)
+		if err := a.encode(ctx, w, r, status, body, ref); err != nil {
 			a.handleError(ctx, w, r, err)
 		}
 	}
+
+	if a.opts.ResponseValidation == nil {
+		return serve
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := capture.NewRecorder()
+		serve(rec, r)
+		validate := a.opts.ResponseValidation.ValidateResponse
+		if rec.Rejected() {
+			validate = a.opts.ResponseValidation.ValidateRejection
+		}
+		if err := validate(r.Context(), a.Registry(), ref, rec.Status(), rec.Header(), rec.Body()); err != nil {
+			a.handleError(r.Context(), w, r, err)
+			return
+		}
+		rec.FlushTo(w)
+	}
+}
+
+func buildChiStreamHandler[TReq any, TEvent any](a *ChiAdapter, handler apix.StreamHandlerFunc[TReq, TEvent], ref *apix.RouteRef) http.HandlerFunc {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		call := newCall(ref, r)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, w, r, ref, hasBody, call, interceptors)
+		if err != nil {
+			a.handleError(ctx, w, r, err)
+			return
+		}
+
+		events := apix.NewEventSender[TEvent](ctx, w, func() {
+			apix.SetEventStreamHeaders(w.Header())
+			w.WriteHeader(ref.SuccessStatus)
+		}, http.NewResponseController(w).Flush)
+		err = handler(ctx, reqPtr, events)
+		if err != nil {
+			err = apix.InterceptError(ctx, call, interceptors, err)
+		}
+		if err != nil && !events.Opened() {
+			a.handleError(ctx, w, r, err)
+			return
+		}
+		_ = events.Close(err)
+	}
 }
 
-func (a *ChiAdapter) decode(ctx context.Context, w http.ResponseWriter, r *http.Request, dst any) error {
+// newRouteRef captures the route metadata shared by typed and streaming handlers. path is
+// relative to the adapter's group, whose options apply before opts.
+func newRouteRef[TReq any](group apix.Group, method apix.RouteMethod, path string, handler any, opts []apix.RouteOption) *apix.RouteRef {
+	ref := &apix.RouteRef{
+		Method:         method,
+		Path:           group.Path(path),
+		Responses:      make(map[int]*apix.ResponseRef),
+		SuccessHeaders: make(map[int][]apix.HeaderRef),
+		HandlerType:    reflect.TypeOf(handler),
+	}
+
+	reqType := typeOf[TReq]()
+	if reqType != nil && !isNoBody(reqType) {
+		ref.RequestType = reqType
+		if ref.RequestContentType == "" && apix.HasBodyFields(reqType) {
+			ref.RequestContentType = "application/json"
+		}
+	}
+
+	group.Apply(ref, opts)
+
+	if ref.SuccessStatus == 0 {
+		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	}
+	return ref
+}
+
+// decodeRequest decodes the body, binds parameters and validates the result, running the
+// interceptors' decode hooks around it. The request is nil for routes without a request type.
+func decodeRequest[TReq any](a *ChiAdapter, ctx context.Context, w http.ResponseWriter, r *http.Request, ref *apix.RouteRef, hasBody bool, call *apix.Call, interceptors []apix.Interceptor) (context.Context, *TReq, error) {
+	return apix.InterceptDecode(ctx, call, interceptors, func(ctx context.Context) (*TReq, error) {
+		if ref.RequestType == nil {
+			return nil, nil
+		}
+		reqVal := new(TReq)
+		if hasBody {
+			if err := a.decode(ctx, w, r, ref.RequestContentType, reqVal); err != nil {
+				return nil, err
+			}
+		}
+		if err := apix.BindParameters(reqVal, call.Params); err != nil {
+			return nil, err
+		}
+		if err := apix.Validate(a.opts.Validator, reqVal); err != nil {
+			return nil, err
+		}
+		return reqVal, nil
+	})
+}
+
+// interceptors returns the route's interceptor pipeline, led by the enforcement of its
+// security requirements.
+func (a *ChiAdapter) interceptors(ref *apix.RouteRef) []apix.Interceptor {
+	interceptors := apix.RouteInterceptors(a.opts.Interceptors, ref)
+	if security, ok := apix.SecurityInterceptor(a.Registry(), ref); ok {
+		interceptors = append([]apix.Interceptor{security}, interceptors...)
+	}
+	return interceptors
+}
+
+// newCall describes the request to interceptors.
+func newCall(ref *apix.RouteRef, r *http.Request) *apix.Call {
+	return &apix.Call{Route: ref, Params: apix.NewRequestSource(r, func(name string) string { return chi.URLParam(r, name) }), TLS: r.TLS}
+}
+
+func (a *ChiAdapter) decode(ctx context.Context, w http.ResponseWriter, r *http.Request, contentType string, dst any) error {
 	if dec := a.opts.Decoder; dec != nil {
 		return dec(ctx, w, r, dst)
 	}
-	return defaultDecoder(ctx, w, r, dst, a.opts.Validator)
+	return defaultDecoder(ctx, w, r, contentType, a.opts.MaxMultipartMemory, dst)
 }
 
 func (a *ChiAdapter) encode(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, payload any, ref *apix.RouteRef) error {
+	// Files and raw bodies bypass the encoder: they are written as they are.
+	if content, ok := apix.AsBinary(payload); ok {
+		return content.Write(w, r, status)
+	}
 	if enc := a.opts.ResponseEncoder; enc != nil {
 		return enc(ctx, w, r, status, payload, ref)
 	}
@@ -179,13 +408,17 @@ func (a *ChiAdapter) handleError(ctx context.Context, w http.ResponseWriter, r *
 	defaultErrorHandler(ctx, w, r, err, a.opts.UseProblemDetails)
 }
 
-func defaultDecoder(ctx context.Context, w http.ResponseWriter, r *http.Request, dst any, validator Validator) error {
+// defaultDecoder binds form bodies for routes declaring a form content type and decodes
+// JSON otherwise.
+func defaultDecoder(ctx context.Context, w http.ResponseWriter, r *http.Request, contentType string, maxMemory int64, dst any) error {
+	if apix.IsFormContentType(contentType) {
+		return apix.DecodeForm(r, contentType, maxMemory, dst)
+	}
 	if r.Body == nil {
 		return &httpError{status: http.StatusBadRequest, message: "request body required"}
 	}
 	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(dst); err != nil {
+	if err := apix.DecodeJSON(decoder, dst, true); err != nil {
 		if errors.Is(err, io.EOF) {
 			return &httpError{status: http.StatusBadRequest, message: "request body required"}
 		}
@@ -194,11 +427,6 @@ func defaultDecoder(ctx context.Context, w http.ResponseWriter, r *http.Request,
 	if decoder.More() {
 		return &httpError{status: http.StatusBadRequest, message: "unexpected additional JSON content"}
 	}
-	if validator != nil {
-		if err := validator.Validate(dst); err != nil {
-			return &httpError{status: http.StatusUnprocessableEntity, message: err.Error()}
-		}
-	}
 	return nil
 }
 
@@ -241,6 +469,15 @@ func typeOf[T any]() reflect.Type {
 	return t
 }
 
+// addHeaders copies headers set by an apix.Response onto the outgoing response.
+func addHeaders(dst, src http.Header) {
+	for key, values := range src {
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
+
 var noBodyType = reflect.TypeOf(apix.NoBody{})
 
 func isNoBody(t reflect.Type) bool {
@@ -1,16 +1,23 @@
 package chi_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
 	apix "github.com/Infra-Forge/infra-apix"
 	chiadapter "github.com/Infra-Forge/infra-apix/chi"
+	"github.com/Infra-Forge/infra-apix/openapi"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -418,3 +425,770 @@ func TestChiAdapterProblemDetailsEncoding(t *testing.T) {
 		t.Errorf("expected type 'about:blank#NOT_FOUND', got %v", problem["type"])
 	}
 }
+
+type itemQuery struct {
+	ID     string `path:"id"`
+	Limit  int    `query:"limit"`
+	Tenant string `header:"X-Tenant"`
+}
+
+func TestChiAdapterBindsTypedParameters(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	var captured *itemQuery
+	chiadapter.Register(adapter, apix.MethodGet, "/items/{id}", func(ctx context.Context, req *itemQuery) (createItemResponse, error) {
+		captured = req
+		return createItemResponse{ID: req.ID}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42?limit=5", nil)
+	req.Header.Set("X-Tenant", "acme")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if captured == nil || captured.ID != "42" || captured.Limit != 5 || captured.Tenant != "acme" {
+		t.Fatalf("unexpected bound request: %+v", captured)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/items/42?limit=five", nil)
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid query parameter, got %d", resp.Code)
+	}
+}
+
+func TestChiAdapterUsesConfiguredRegistry(t *testing.T) {
+	apix.ResetRegistry()
+	registry := apix.NewRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{Registry: registry})
+
+	chiadapter.Get(adapter, "/scoped", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "scoped"}, nil
+	})
+
+	if adapter.Registry() != registry {
+		t.Fatalf("expected adapter to expose configured registry")
+	}
+	if len(registry.Snapshot()) != 1 {
+		t.Fatalf("expected route in scoped registry")
+	}
+	if len(apix.Snapshot()) != 0 {
+		t.Fatalf("expected default registry to stay empty")
+	}
+}
+
+type signupRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"gte=18"`
+}
+
+func serveSignup(t *testing.T, opts chiadapter.Options) *httptest.ResponseRecorder {
+	t.Helper()
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, opts)
+	chiadapter.Post(adapter, "/signup", func(ctx context.Context, req *signupRequest) (createItemResponse, error) {
+		return createItemResponse{ID: req.Email}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"email":"nope","age":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestChiAdapterValidatesRequestTags(t *testing.T) {
+	resp := serveSignup(t, chiadapter.Options{})
+	if resp.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var plain struct {
+		Code    string            `json:"code"`
+		Details []apix.FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &plain); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if plain.Code != "VALIDATION_FAILED" || len(plain.Details) != 2 || plain.Details[0].Path != "/email" || plain.Details[1].Rule != "gte" {
+		t.Fatalf("unexpected validation body: %s", resp.Body.String())
+	}
+
+	resp = serveSignup(t, chiadapter.Options{UseProblemDetails: true})
+	if resp.Code != http.StatusUnprocessableEntity || resp.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("expected problem details 422, got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	var problem struct {
+		Errors []apix.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if len(problem.Errors) != 2 || problem.Errors[1].Path != "/age" {
+		t.Fatalf("unexpected problem body: %s", resp.Body.String())
+	}
+}
+
+type driftingCount struct {
+	Count int `json:"count"`
+}
+
+// MarshalJSON drifts from the reflected schema, which documents count as an integer.
+func (driftingCount) MarshalJSON() ([]byte, error) {
+	return []byte(`{"count":"many"}`), nil
+}
+
+func TestChiAdapterValidatesResponses(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{UseProblemDetails: true, ResponseValidation: &openapi.ResponseValidator{FailOnViolation: true}})
+	chiadapter.Get(adapter, "/item", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "1"}, nil
+	})
+	chiadapter.Get(adapter, "/count", func(ctx context.Context, _ *apix.NoBody) (driftingCount, error) {
+		return driftingCount{Count: 1}, nil
+	})
+	chiadapter.Get(adapter, "/teapot", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{}, &apix.HTTPError{Status: http.StatusTeapot, Message: "short and stout"}
+	})
+	serve := func(t *testing.T, path string) (int, string) {
+		t.Helper()
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp.Code, resp.Body.String()
+	}
+
+	status, body := serve(t, "/item")
+	if status != http.StatusOK || !strings.Contains(body, `"id":"1"`) {
+		t.Fatalf("expected documented response to pass, got %d: %s", status, body)
+	}
+
+	status, body = serve(t, "/count")
+	if status != http.StatusInternalServerError || !strings.Contains(body, "RESPONSE_VALIDATION_FAILED") || !strings.Contains(body, `"path":"/count"`) {
+		t.Fatalf("expected schema violation to fail, got %d: %s", status, body)
+	}
+
+	status, body = serve(t, "/teapot")
+	if status != http.StatusInternalServerError || !strings.Contains(body, "status 418 is not documented") {
+		t.Fatalf("expected undocumented status to fail, got %d: %s", status, body)
+	}
+}
+
+type pet interface{ sound() string }
+
+type dog struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+func (dog) sound() string { return "woof" }
+
+type cat struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+func (*cat) sound() string { return "meow" }
+
+type adoptRequest struct {
+	Pet pet `json:"pet"`
+}
+
+func TestChiAdapterDecodesUnions(t *testing.T) {
+	apix.RegisterUnion[pet](apix.Variant(dog{}, "dog"), apix.Variant(&cat{}, "cat"), apix.Discriminator("kind"))
+	defer apix.UnregisterUnion[pet]()
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+	chiadapter.Post(adapter, "/adopt", func(ctx context.Context, req *adoptRequest) (createItemResponse, error) {
+		return createItemResponse{ID: req.Pet.sound()}, nil
+	})
+
+	serve := func(payload string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/adopt", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp.Code, resp.Body.String()
+	}
+
+	status, body := serve(`{"pet":{"kind":"cat","name":"Tom"}}`)
+	if status != http.StatusCreated || !strings.Contains(body, `"id":"meow"`) {
+		t.Fatalf("expected cat variant, got %d: %s", status, body)
+	}
+	status, body = serve(`{"pet":{"kind":"fish"}}`)
+	if status != http.StatusBadRequest || !strings.Contains(body, `unknown kind`) {
+		t.Fatalf("expected unknown variant to be rejected, got %d: %s", status, body)
+	}
+}
+
+func TestChiAdapterWritesResponseEnvelope(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	chiadapter.Put(adapter, "/api/items", func(ctx context.Context, req *createItemRequest) (apix.Response[createItemResponse], error) {
+		if req.Name == "new" {
+			return apix.Created(createItemResponse{ID: "42"}, "/api/items/42"), nil
+		}
+		return apix.Response[createItemResponse]{Body: createItemResponse{ID: "7"}}.
+			WithHeader("ETag", `"v2"`).
+			WithCookie(&http.Cookie{Name: "session", Value: "abc"}), nil
+	}, apix.WithResponseStatus(http.StatusCreated))
+
+	send := func(name string) *http.Response {
+		req := httptest.NewRequest(http.MethodPut, "/api/items", strings.NewReader(`{"name":"`+name+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	created := send("new")
+	if created.StatusCode != http.StatusCreated || created.Header.Get("Location") != "/api/items/42" {
+		t.Fatalf("expected 201 with Location, got %d %v", created.StatusCode, created.Header)
+	}
+	updated := send("old")
+	if updated.StatusCode != http.StatusOK || updated.Header.Get("ETag") != `"v2"` {
+		t.Fatalf("expected 200 with ETag, got %d %v", updated.StatusCode, updated.Header)
+	}
+	if cookies := updated.Header.Values("Set-Cookie"); len(cookies) != 1 || cookies[0] != "session=abc" {
+		t.Fatalf("expected session cookie, got %v", cookies)
+	}
+	var decoded createItemResponse
+	if err := json.NewDecoder(updated.Body).Decode(&decoded); err != nil || decoded.ID != "7" {
+		t.Fatalf("expected unwrapped body, got %+v (%v)", decoded, err)
+	}
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	op := doc.Paths.Find("/api/items").Put
+	for _, status := range []int{http.StatusOK, http.StatusCreated} {
+		resp := op.Responses.Status(status)
+		if resp == nil || resp.Value.Content.Get("application/json") == nil {
+			t.Fatalf("expected %d to document the body", status)
+		}
+	}
+}
+
+func TestChiAdapterStreamsServerSentEvents(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	chiadapter.SSE(adapter, "/items/{id}/events", func(ctx context.Context, req *itemQuery, events *apix.EventSender[createItemResponse]) error {
+		if req.ID == "missing" {
+			return apix.NotFound("item not found")
+		}
+		for i := 0; i < req.Limit; i++ {
+			if err := events.Send(createItemResponse{ID: fmt.Sprint(i)}); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("feed closed")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/42/events?limit=2", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != apix.ContentTypeEventStream {
+		t.Fatalf("expected event stream, got %d %v", rec.Code, rec.Header())
+	}
+	want := "data: {\"id\":\"0\"}\n\ndata: {\"id\":\"1\"}\n\nevent: error\ndata: {\"error\":\"feed closed\"}\n\n"
+	if rec.Body.String() != want || !rec.Flushed {
+		t.Fatalf("unexpected stream (flushed=%v):\n%q", rec.Flushed, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/missing/events", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected errors before the first event to use the error handler, got %d", rec.Code)
+	}
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	resp := doc.Paths.Find("/items/{id}/events").Get.Responses.Status(http.StatusOK)
+	if resp == nil || resp.Value.Content.Get(apix.ContentTypeEventStream) == nil {
+		t.Fatalf("expected text/event-stream success response")
+	}
+}
+
+func TestChiAdapterStreamsSkipAfterHandlerHooks(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	var hooks []string
+	record := apix.Interceptor{
+		AfterDecode: func(ctx context.Context, call *apix.Call) (context.Context, error) {
+			hooks = append(hooks, "AfterDecode")
+			return ctx, nil
+		},
+		AfterHandler: func(ctx context.Context, call *apix.Call) error {
+			hooks = append(hooks, "AfterHandler")
+			return nil
+		},
+		OnError: func(ctx context.Context, call *apix.Call, err error) error {
+			hooks = append(hooks, "OnError")
+			return nil
+		},
+	}
+	adapter := chiadapter.New(r, chiadapter.Options{Interceptors: []apix.Interceptor{record}})
+	chiadapter.SSE(adapter, "/items/{id}/events", func(ctx context.Context, req *itemQuery, events *apix.EventSender[createItemResponse]) error {
+		if req.Limit == 0 {
+			return fmt.Errorf("feed closed")
+		}
+		return events.Send(createItemResponse{ID: req.ID})
+	})
+
+	for _, target := range []string{"/items/42/events?limit=1", "/items/42/events"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	if want := []string{"AfterDecode", "AfterDecode", "OnError"}; !reflect.DeepEqual(hooks, want) {
+		t.Fatalf("expected streams to run decode and error hooks only, got %v", hooks)
+	}
+}
+
+func TestChiAdapterServesFiles(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	chiadapter.Get(adapter, "/exports/users", func(ctx context.Context, _ *apix.NoBody) (apix.File, error) {
+		return apix.File{Name: "users.csv", ContentType: "text/csv", Reader: strings.NewReader("id,name\n1,ada\n")}, nil
+	}, apix.WithResponseContentType("text/csv"))
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/users", nil)
+	req.Header.Set("Range", "bytes=0-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "id" {
+		t.Fatalf("expected ranged file content, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "text/csv" || rec.Header().Get("Content-Disposition") != "attachment; filename=users.csv" {
+		t.Fatalf("unexpected file headers %v", rec.Header())
+	}
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	media := doc.Paths.Find("/exports/users").Get.Responses.Status(http.StatusOK).Value.Content.Get("text/csv")
+	if media == nil || media.Schema.Value.Format != "binary" {
+		t.Fatalf("expected text/csv binary response")
+	}
+}
+
+type uploadAvatarRequest struct {
+	ID     string           `path:"id"`
+	Note   string           `form:"note"`
+	Avatar *apix.FileUpload `form:"avatar"`
+}
+
+type uploadAvatarResponse struct {
+	ID       string `json:"id"`
+	Note     string `json:"note"`
+	Filename string `json:"filename,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+func uploadAvatar(ctx context.Context, req *uploadAvatarRequest) (uploadAvatarResponse, error) {
+	resp := uploadAvatarResponse{ID: req.ID, Note: req.Note}
+	if req.Avatar != nil {
+		f, err := req.Avatar.Open()
+		if err != nil {
+			return resp, err
+		}
+		defer f.Close()
+		content, _ := io.ReadAll(f)
+		resp.Filename, resp.Content = req.Avatar.Filename, string(content)
+	}
+	return resp, nil
+}
+
+func TestChiAdapterDecodesFormBodies(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	chiadapter.Post(adapter, "/users/{id}/avatar", uploadAvatar, apix.WithMultipartFormData())
+	chiadapter.Post(adapter, "/users/{id}/note", uploadAvatar, apix.WithFormURLEncoded())
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("note", "new avatar")
+	part, _ := mw.CreateFormFile("avatar", "me.png")
+	_, _ = part.Write([]byte("png-bytes"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42/avatar", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got uploadAvatarResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got != (uploadAvatarResponse{ID: "42", Note: "new avatar", Filename: "me.png", Content: "png-bytes"}) {
+		t.Fatalf("unexpected upload response %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/42/note", strings.NewReader(url.Values{"note": {"hello world"}}.Encode()))
+	req.Header.Set("Content-Type", apix.ContentTypeFormURLEncoded)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"note":"hello world"`) {
+		t.Fatalf("unexpected urlencoded response %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestChiAdapterSupportsHeadOptionsAndCustomMethods(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{AutoHead: true})
+
+	chiadapter.Get(adapter, "/items/{id}", func(ctx context.Context, _ *apix.NoBody) (apix.Response[createItemResponse], error) {
+		return apix.NewResponse(http.StatusOK, createItemResponse{ID: "42"}).WithHeader("ETag", `"v1"`), nil
+	})
+	chiadapter.OptionsRoute(adapter, "/items", func(ctx context.Context, _ *apix.NoBody) (apix.Response[apix.NoBody], error) {
+		return apix.NewResponse(http.StatusNoContent, apix.NoBody{}).WithHeader("Allow", "GET, HEAD, OPTIONS"), nil
+	}, apix.WithSuccessStatus(http.StatusNoContent))
+	chiadapter.Register(adapter, "PURGE", "/items/{id}", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "purged"}, nil
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	send := func(method, path string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := send(http.MethodHead, "/items/42")
+	if resp.StatusCode != http.StatusOK || body != "" || resp.Header.Get("ETag") != `"v1"` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected HEAD response %d %v %q", resp.StatusCode, resp.Header, body)
+	}
+	resp, _ = send(http.MethodOptions, "/items")
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Fatalf("unexpected OPTIONS response %d %v", resp.StatusCode, resp.Header)
+	}
+	resp, body = send("PURGE", "/items/42")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "purged") {
+		t.Fatalf("unexpected PURGE response %d %q", resp.StatusCode, body)
+	}
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	item := doc.Paths.Find("/items/{id}")
+	if item.Head == nil || item.Head.Responses.Status(http.StatusOK).Value.Content != nil {
+		t.Fatalf("expected documented HEAD operation without content")
+	}
+	if doc.Paths.Find("/items").Options == nil || openapi.Operation(item, "PURGE") == nil {
+		t.Fatalf("expected OPTIONS and PURGE operations to be documented")
+	}
+}
+
+type documentRequest struct {
+	ID string `path:"id"`
+}
+
+func TestChiAdapterGroupsRoutes(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r)
+
+	api := adapter.Group("/api", apix.WithTags("API"), apix.WithSecurity("BearerAuth"))
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Group", "api")
+			next.ServeHTTP(w, r)
+		})
+	})
+	docs := api.Group("/documents", apix.WithTags("Documents"), apix.WithStandardErrors())
+
+	chiadapter.Register(docs, apix.MethodGet, "/{id}", func(ctx context.Context, req *documentRequest) (createItemResponse, error) {
+		return createItemResponse{ID: req.ID}, nil
+	}, apix.WithTags("Documents"))
+	chiadapter.Register(docs, apix.MethodDelete, "/{id}", func(ctx context.Context, req *documentRequest) (apix.NoBody, error) {
+		return apix.NoBody{}, nil
+	}, apix.WithSecurity("BearerAuth", "documents:delete"))
+	chiadapter.Get(adapter, "/health", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "ok"}, nil
+	})
+
+	send := func(method, path string) (int, http.Header, string) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code, rec.Header(), rec.Body.String()
+	}
+
+	status, header, body := send(http.MethodGet, "/api/documents/7")
+	if status != http.StatusOK || !strings.Contains(body, `"id":"7"`) || header.Get("X-Group") != "api" {
+		t.Fatalf("unexpected grouped response %d %v %q", status, header, body)
+	}
+	status, header, _ = send(http.MethodGet, "/health")
+	if status != http.StatusOK || header.Get("X-Group") != "" {
+		t.Fatalf("expected group middleware to skip other routes, got %d %v", status, header)
+	}
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	item := doc.Paths.Find("/api/documents/{id}")
+	if item == nil || item.Get == nil || item.Delete == nil {
+		t.Fatalf("expected prefixed document operations")
+	}
+	if strings.Join(item.Get.Tags, ",") != "API,Documents" || item.Get.Responses.Status(http.StatusBadRequest) == nil {
+		t.Fatalf("expected group tags and errors, got %v", item.Get.Tags)
+	}
+	if sec := *item.Get.Security; len(sec) != 1 || len(sec[0]) != 1 || len(sec[0]["BearerAuth"]) != 0 {
+		t.Fatalf("expected inherited security, got %v", sec)
+	}
+	if sec := *item.Delete.Security; len(sec) != 1 || strings.Join(sec[0]["BearerAuth"], ",") != "documents:delete" {
+		t.Fatalf("expected route security to replace the group's, got %v", sec)
+	}
+	if health := doc.Paths.Find("/health").Get; len(health.Tags) != 0 || health.Security != nil {
+		t.Fatalf("expected root route without group options")
+	}
+}
+
+func TestChiAdapterRunsInterceptors(t *testing.T) {
+	apix.ResetRegistry()
+	var trace []string
+	record := func(name string) apix.Interceptor {
+		return apix.Interceptor{
+			BeforeDecode: func(ctx context.Context, call *apix.Call) (context.Context, error) {
+				trace = append(trace, name+":before")
+				return ctx, nil
+			},
+			AfterHandler: func(ctx context.Context, call *apix.Call) error {
+				trace = append(trace, name+":after")
+				return nil
+			},
+		}
+	}
+	guard := apix.Interceptor{
+		AfterDecode: func(ctx context.Context, call *apix.Call) (context.Context, error) {
+			if call.Request.(*createItemRequest).Name == "forbidden" {
+				return ctx, &apix.HTTPError{Status: http.StatusForbidden, Message: "name not allowed"}
+			}
+			return ctx, nil
+		},
+		AfterHandler: func(ctx context.Context, call *apix.Call) error {
+			resp := call.Response.(createItemResponse)
+			resp.ID = "redacted"
+			call.Response = resp
+			return nil
+		},
+	}
+
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{Interceptors: []apix.Interceptor{record("global")}})
+	items := adapter.Group("/items", apix.WithInterceptors(record("group")))
+	chiadapter.Post(items, "/drafts", func(ctx context.Context, req *createItemRequest) (createItemResponse, error) {
+		return createItemResponse{ID: "secret-" + req.Name}, nil
+	}, apix.WithInterceptors(guard))
+
+	send := func(body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/items/drafts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	status, body := send(`{"name":"widget"}`)
+	if status != http.StatusCreated || !strings.Contains(body, `"id":"redacted"`) {
+		t.Fatalf("expected redacted response, got %d %q", status, body)
+	}
+	if got := strings.Join(trace, ","); got != "global:before,group:before,group:after,global:after" {
+		t.Fatalf("unexpected interceptor order %s", got)
+	}
+
+	status, body = send(`{"name":"forbidden"}`)
+	if status != http.StatusForbidden || strings.Contains(body, "secret") {
+		t.Fatalf("expected interceptor to reject the request, got %d %q", status, body)
+	}
+}
+
+func TestChiAdapterEnforcesSecurity(t *testing.T) {
+	apix.ResetRegistry()
+	bearer := apix.HTTPBearer("BearerAuth", "JWT", apix.VerifierFunc(func(ctx context.Context, creds apix.Credentials) (*apix.Principal, error) {
+		switch creds.Token {
+		case "reader":
+			return &apix.Principal{Subject: "ada", Scopes: []string{"items:read"}}, nil
+		case "guest":
+			return &apix.Principal{Subject: "guest"}, nil
+		}
+		return nil, errors.New("unknown token")
+	}))
+
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{})
+	items := adapter.Group("/items", bearer.Require("items:read"))
+	chiadapter.Register(items, apix.MethodGet, "/{id}", func(ctx context.Context, req *documentRequest) (createItemResponse, error) {
+		principal, ok := apix.PrincipalFromContext(ctx)
+		if !ok {
+			t.Fatalf("expected principal in handler context")
+		}
+		return createItemResponse{ID: principal.Subject + ":" + req.ID}, nil
+	})
+
+	send := func(token string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "forged": http.StatusUnauthorized, "guest": http.StatusForbidden} {
+		if status, body := send(token); status != want {
+			t.Fatalf("token %q: expected %d, got %d %q", token, want, status, body)
+		}
+	}
+	if status, body := send("reader"); status != http.StatusOK || !strings.Contains(body, `"id":"ada:42"`) {
+		t.Fatalf("expected authenticated response, got %d %q", status, body)
+	}
+}
+
+func TestChiAdapterDocumentsMiddlewareSecurity(t *testing.T) {
+	apix.ResetRegistry()
+	var trace []string
+	mark := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{})
+	adapter.UseSecured(apix.SecuredBy(mark("session"), "SessionCookie"))
+	admin := adapter.Group("/admin", apix.WithMiddleware(apix.SecuredBy(mark("jwt"), "BearerAuth", "admin")))
+	chiadapter.Get(admin, "/stats", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "stats"}, nil
+	}, apix.WithMiddleware(mark("audit")), apix.WithSecurity("ApiKey"))
+	chiadapter.Get(adapter, "/health", func(ctx context.Context, _ *apix.NoBody) (createItemResponse, error) {
+		return createItemResponse{ID: "ok"}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := strings.Join(trace, ","); got != "session,jwt,audit" {
+		t.Fatalf("unexpected middleware order %s", got)
+	}
+
+	routes := map[string]*apix.RouteRef{}
+	for _, ref := range apix.Snapshot() {
+		routes[ref.Path] = ref
+	}
+	stats := routes["/admin/stats"]
+	want := []apix.SecurityRequirement{{Name: "SessionCookie"}, {Name: "BearerAuth", Scopes: []string{"admin"}}}
+	if !reflect.DeepEqual(stats.MiddlewareSecurity, want) || len(stats.Security) != 1 || stats.Security[0].Name != "ApiKey" {
+		t.Fatalf("expected adapter and group middleware security next to the route's, got %v %v", stats.MiddlewareSecurity, stats.Security)
+	}
+	if got := routes["/health"].MiddlewareSecurity; len(got) != 1 || got[0].Name != "SessionCookie" {
+		t.Fatalf("expected adapter middleware security, got %v", got)
+	}
+}
+
+type tenantItemRequest struct {
+	Tenant string `header:"X-Tenant"`
+	Name   string `json:"name"`
+}
+
+func TestChiAdapterIgnoresParameterFieldsInBody(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{})
+	chiadapter.Post(adapter, "/items", func(ctx context.Context, req *tenantItemRequest) (createItemResponse, error) {
+		return createItemResponse{ID: req.Tenant + "/" + req.Name}, nil
+	})
+
+	send := func(req *http.Request) (int, string) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+	post := func(body, tenant string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		return req
+	}
+
+	if status, body := send(post(`{"name":"widget","Tenant":"evil"}`, "")); status != http.StatusBadRequest {
+		t.Fatalf("expected body parameter field to be rejected, got %d %q", status, body)
+	}
+	if status, body := send(post(`{"name":"widget"}`, "acme")); status != http.StatusCreated || !strings.Contains(body, `"id":"acme/widget"`) {
+		t.Fatalf("expected header to bind, got %d %q", status, body)
+	}
+}
+
+func TestChiAdapterValidatesPostAndClientErrorResponses(t *testing.T) {
+	apix.ResetRegistry()
+	r := chi.NewRouter()
+	adapter := chiadapter.New(r, chiadapter.Options{UseProblemDetails: true, ResponseValidation: &openapi.ResponseValidator{FailOnViolation: true}})
+	chiadapter.Post(adapter, "/signup", func(ctx context.Context, req *signupRequest) (createItemResponse, error) {
+		return createItemResponse{ID: req.Email}, nil
+	})
+	chiadapter.Post(adapter, "/duplicate", func(ctx context.Context, req *signupRequest) (createItemResponse, error) {
+		return createItemResponse{}, apix.UnprocessableEntity("already signed up")
+	})
+	send := func(path, body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	cases := map[string]struct {
+		path   string
+		body   string
+		status int
+	}{
+		"created without Location": {"/signup", `{"email":"a@example.com","age":30}`, http.StatusCreated},
+		"malformed body":           {"/signup", `{"email":`, http.StatusBadRequest},
+		"failed validation":        {"/signup", `{"email":"not-an-email","age":12}`, http.StatusUnprocessableEntity},
+		"undocumented handler 422": {"/duplicate", `{"email":"a@example.com","age":30}`, http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if status, body := send(tc.path, tc.body); status != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, status, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// entryMarker flags an exported function that registers the project's routes.
+const entryMarker = "//apix:entry"
+
+// entryPoint identifies the function the generator program calls to populate the registry.
+type entryPoint struct {
+	moduleRoot string
+	importPath string
+	funcName   string
+}
+
+// resolveEntry locates the registration entry point either from the --entry flag
+// (`./internal/api.RegisterRoutes` or `example.com/svc/api.RegisterRoutes`) or from a
+// function annotated with //apix:entry. It returns nil when neither is present.
+func resolveEntry(projectPath, entryFlag string) (*entryPoint, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve project path: %w", err)
+	}
+
+	entryFlag = strings.TrimSpace(entryFlag)
+	if entryFlag == "" {
+		dir, funcName, err := findEntryMarker(absProject)
+		if err != nil || dir == "" {
+			return nil, err
+		}
+		moduleRoot, modulePath, err := findModule(absProject)
+		if err != nil {
+			return nil, err
+		}
+		importPath, err := importPathFor(moduleRoot, modulePath, dir)
+		if err != nil {
+			return nil, err
+		}
+		return &entryPoint{moduleRoot: moduleRoot, importPath: importPath, funcName: funcName}, nil
+	}
+
+	idx := strings.LastIndex(entryFlag, ".")
+	if idx <= 0 || idx == len(entryFlag)-1 || strings.Contains(entryFlag[idx:], "/") {
+		return nil, fmt.Errorf("invalid --entry %q; expected <package>.<Func>", entryFlag)
+	}
+	pkg, funcName := entryFlag[:idx], entryFlag[idx+1:]
+	if !token.IsExported(funcName) {
+		return nil, fmt.Errorf("entry function %s must be exported", funcName)
+	}
+
+	moduleRoot, modulePath, err := findModule(absProject)
+	if err != nil {
+		return nil, err
+	}
+	importPath := pkg
+	if pkg == "." || strings.HasPrefix(pkg, "./") || strings.HasPrefix(pkg, "../") || filepath.IsAbs(pkg) {
+		dir := pkg
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(absProject, dir)
+		}
+		if importPath, err = importPathFor(moduleRoot, modulePath, dir); err != nil {
+			return nil, err
+		}
+	}
+	return &entryPoint{moduleRoot: moduleRoot, importPath: importPath, funcName: funcName}, nil
+}
+
+// findEntryMarker walks root looking for a single function annotated with //apix:entry.
+func findEntryMarker(root string) (dir string, funcName string, err error) {
+	type match struct{ dir, funcName string }
+	var matches []match
+
+	fset := token.NewFileSet()
+	walkErr := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(src, []byte(entryMarker)) {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", p, err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Doc == nil || !hasEntryMarker(fn.Doc) {
+				continue
+			}
+			if file.Name.Name == "main" {
+				return fmt.Errorf("%s: entry function %s must live in an importable package, not main", p, fn.Name.Name)
+			}
+			if !fn.Name.IsExported() {
+				return fmt.Errorf("%s: entry function %s must be exported", p, fn.Name.Name)
+			}
+			matches = append(matches, match{dir: filepath.Dir(p), funcName: fn.Name.Name})
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return "", "", walkErr
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return matches[0].dir, matches[0].funcName, nil
+	default:
+		return "", "", fmt.Errorf("found %d %s markers; use --entry to choose one", len(matches), entryMarker)
+	}
+}
+
+func hasEntryMarker(doc *ast.CommentGroup) bool {
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == entryMarker {
+			return true
+		}
+	}
+	return false
+}
+
+// findModule walks up from dir to the nearest go.mod and returns its directory and module path.
+func findModule(dir string) (root string, modulePath string, err error) {
+	for current := dir; ; {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			modulePath = parseModulePath(data)
+			if modulePath == "" {
+				return "", "", fmt.Errorf("no module directive in %s", filepath.Join(current, "go.mod"))
+			}
+			return current, modulePath, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", "", fmt.Errorf("read go.mod: %w", err)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+		current = parent
+	}
+}
+
+func parseModulePath(gomod []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(gomod))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+func importPathFor(moduleRoot, modulePath, dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(moduleRoot, absDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("package %s is outside module %s", dir, modulePath)
+	}
+	if rel == "." {
+		return modulePath, nil
+	}
+	return path.Join(modulePath, filepath.ToSlash(rel)), nil
+}
+
+var generatorTemplate = template.Must(template.New("generator").Parse(`// Code generated by apix. DO NOT EDIT.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+
+	entry {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	out := flag.String("out", "", "")
+	title := flag.String("title", "API", "")
+	version := flag.String("version", "1.0.0", "")
+	openAPIVersion := flag.String("openapi-version", "", "")
+	flattenEmbedded := flag.Bool("flatten-embedded", false, "")
+	flag.Parse()
+
+	b := openapi.NewBuilder()
+	b.Info.Title = *title
+	b.Info.Version = *version
+	b.OpenAPIVersion = *openAPIVersion
+	b.FlattenEmbedded = *flattenEmbedded
+
+	registry := apix.DefaultRegistry()
+	var err error
+	switch fn := any(entry.{{.FuncName}}).(type) {
+	case func():
+		fn()
+	case func() error:
+		err = fn()
+	case func(*apix.Registry):
+		fn(registry)
+	case func(*apix.Registry) error:
+		err = fn(registry)
+	case func(*apix.Registry, *openapi.Builder):
+		fn(registry, b)
+	case func(*apix.Registry, *openapi.Builder) error:
+		err = fn(registry, b)
+	default:
+		fail(fmt.Errorf("entry {{.FuncName}} has unsupported signature %T", fn))
+	}
+	if err != nil {
+		fail(fmt.Errorf("entry {{.FuncName}}: %w", err))
+	}
+
+	doc, err := b.BuildRegistry(registry)
+	if err != nil {
+		fail(err)
+	}
+	data, _, err := openapi.EncodeDocument(doc, "json")
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+`))
+
+// loadProjectDocument builds and runs a throwaway generator program inside the target module.
+// The program calls the entry point, builds the OpenAPI document with the module's own apix
+// version and hands it back encoded like a served document, so routes never need to be
+// compiled into the CLI. Entry points taking an *openapi.Builder configure it as the service
+// does, for options the CLI flags do not cover.
+func loadProjectDocument(ctx context.Context, cfg generateConfig, entry *entryPoint) (*openapi3.T, error) {
+	genDir, err := os.MkdirTemp(entry.moduleRoot, ".apix-gen-")
+	if err != nil {
+		return nil, fmt.Errorf("create generator dir: %w", err)
+	}
+	defer os.RemoveAll(genDir)
+
+	var src bytes.Buffer
+	if err := generatorTemplate.Execute(&src, struct{ ImportPath, FuncName string }{entry.importPath, entry.funcName}); err != nil {
+		return nil, fmt.Errorf("render generator: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(genDir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write generator: %w", err)
+	}
+
+	outPath := filepath.Join(genDir, "openapi.json")
+	cmd := exec.CommandContext(ctx, "go", "run", "./"+filepath.Base(genDir),
+		"-out", outPath,
+		"-title", cfg.title,
+		"-version", cfg.version,
+		"-openapi-version", cfg.openAPIVersion,
+		fmt.Sprintf("-flatten-embedded=%t", cfg.flattenEmbedded),
+	)
+	cmd.Dir = entry.moduleRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("run generator for %s.%s: %w\n%s", entry.importPath, entry.funcName, err, strings.TrimSpace(stderr.String()))
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("read generator output: %w", err)
+	}
+	doc, err := openapi.DecodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode generator output: %w", err)
+	}
+	for _, srv := range cfg.servers {
+		doc.Servers = append(doc.Servers, &openapi3.Server{URL: srv})
+	}
+	return doc, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestResolveEntryFromMarker(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/svc\n\ngo 1.25\n")
+	writeFile(t, filepath.Join(root, "internal", "api", "routes.go"), `package api
+
+//apix:entry
+func RegisterRoutes() {}
+`)
+	writeFile(t, filepath.Join(root, "testdata", "ignored.go"), "package ignored\n\n//apix:entry\nfunc Ignored() {}\n")
+
+	entry, err := resolveEntry(root, "")
+	if err != nil {
+		t.Fatalf("resolve entry: %v", err)
+	}
+	if entry == nil {
+		t.Fatalf("expected marker to be found")
+	}
+	if entry.importPath != "example.com/svc/internal/api" || entry.funcName != "RegisterRoutes" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestResolveEntryFromFlag(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/svc\n")
+
+	cases := map[string]string{
+		"./api.Register":                "example.com/svc/api",
+		".Register":                     "",
+		"example.com/other/pkg.Routes":  "example.com/other/pkg",
+		"./api.register":                "",
+		"example.com/svc/api/v1.Mount":  "example.com/svc/api/v1",
+		"example.com/svc/api.v1/x.Func": "example.com/svc/api.v1/x",
+	}
+	for flag, want := range cases {
+		entry, err := resolveEntry(root, flag)
+		if want == "" {
+			if err == nil {
+				t.Fatalf("%s: expected error", flag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", flag, err)
+		}
+		if entry.importPath != want {
+			t.Fatalf("%s: expected %s, got %s", flag, want, entry.importPath)
+		}
+	}
+}
+
+func TestResolveEntryWithoutMarker(t *testing.T) {
+	entry, err := resolveEntry(t.TempDir(), "")
+	if err != nil || entry != nil {
+		t.Fatalf("expected no entry, got %+v, %v", entry, err)
+	}
+}
+
+func TestRunGenerateLoadsProjectEntry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generator program")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("repo root: %v", err)
+	}
+	sum, err := os.ReadFile(filepath.Join(repoRoot, "go.sum"))
+	if err != nil {
+		t.Fatalf("read go.sum: %v", err)
+	}
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), `module example.com/svc
+
+go 1.25.3
+
+require github.com/Infra-Forge/infra-apix v0.0.0
+
+replace github.com/Infra-Forge/infra-apix => `+repoRoot+"\n")
+	writeFile(t, filepath.Join(root, "go.sum"), string(sum))
+	writeFile(t, filepath.Join(root, "api", "routes.go"), `package api
+
+import (
+	"net/http"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//apix:entry
+func RegisterRoutes(registry *apix.Registry, b *openapi.Builder) {
+	b.SecuritySchemes = openapi3.SecuritySchemes{"BearerAuth": {Value: openapi3.NewJWTSecurityScheme()}}
+	b.GlobalSecurity = openapi3.SecurityRequirements{{"BearerAuth": []string{}}}
+	registry.Register(&apix.RouteRef{
+		Method:      apix.MethodGet,
+		Path:        "/projects",
+		OperationID: "getProject",
+		Responses:   map[int]*apix.ResponseRef{http.StatusOK: {}},
+	})
+}
+`)
+
+	out := filepath.Join(root, "docs", "openapi.yaml")
+	cfg := generateConfig{
+		projectPath: root,
+		outputPath:  out,
+		format:      "yaml",
+		title:       "Projects",
+		version:     "2.0.0",
+		servers:     []string{"https://api.example.com"},
+		validate:    true,
+	}
+	if err := runGenerate(context.Background(), cfg); err != nil {
+		t.Fatalf("runGenerate: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read spec: %v", err)
+	}
+	spec := string(data)
+	for _, want := range []string{"/projects", "getProject", "title: Projects", "https://api.example.com", "openapi: 3.1.0", "BearerAuth"} {
+		if !strings.Contains(spec, want) {
+			t.Fatalf("expected %q in generated spec:\n%s", want, spec)
+		}
+	}
+
+	if err := runSpecGuard(context.Background(), cfg, "", false); err != nil {
+		t.Fatalf("spec-guard should pass on freshly generated spec: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(root, ".apix-gen-*"))
+	if len(matches) != 0 {
+		t.Fatalf("generator directory not cleaned up: %v", matches)
+	}
+}
@@ -56,10 +56,17 @@ func runCLI(ctx context.Context, args []string) error {
 	flagFormat := fs.String("format", "yaml", "Output format: yaml or json")
 	flagTitle := fs.String("title", "API", "API title")
 	flagVersion := fs.String("version", "1.0.0", "API version")
+	flagOpenAPIVersion := fs.String("openapi-version", "", "OpenAPI version of the generated spec, 3.1.0 (default) or 3.0.3")
+	flagFlattenEmbedded := fs.Bool("flatten-embedded", false, "Document promoted fields of embedded structs as properties (openapi.Builder.FlattenEmbedded)")
 	flagServers := fs.String("servers", "", "Comma-separated server URLs")
 	flagStdout := fs.Bool("stdout", false, "Write spec to stdout instead of file")
 	flagValidate := fs.Bool("validate", true, "Validate generated spec")
 	flagExisting := fs.String("existing", "", "Existing spec to compare against (defaults to --out)")
+	flagEntry := fs.String("entry", "", "Route registration entry point, e.g. ./internal/api.RegisterRoutes (defaults to the //apix:entry marker)")
+	flagAllowNonBreaking := fs.Bool("allow-non-breaking", false, "spec-guard: accept drift that contains only non-breaking changes")
+	flagBase := fs.String("base", "", "diff: base (previous) spec")
+	flagHead := fs.String("head", "", "diff: head (proposed) spec")
+	flagReport := fs.String("report", "text", "diff: report format, text or json")
 
 	var command string
 	rest := args
@@ -82,14 +89,17 @@ func runCLI(ctx context.Context, args []string) error {
 	}
 
 	cfg := generateConfig{
-		projectPath: *flagProject,
-		outputPath:  *flagOut,
-		format:      *flagFormat,
-		title:       *flagTitle,
-		version:     *flagVersion,
-		servers:     parseServers(*flagServers),
-		stdout:      *flagStdout,
-		validate:    *flagValidate,
+		projectPath:     *flagProject,
+		outputPath:      *flagOut,
+		format:          *flagFormat,
+		title:           *flagTitle,
+		version:         *flagVersion,
+		openAPIVersion:  *flagOpenAPIVersion,
+		flattenEmbedded: *flagFlattenEmbedded,
+		servers:         parseServers(*flagServers),
+		stdout:          *flagStdout,
+		validate:        *flagValidate,
+		entry:           *flagEntry,
 	}
 
 	switch command {
@@ -99,10 +109,15 @@ func runCLI(ctx context.Context, args []string) error {
 		}
 		return nil
 	case "spec-guard":
-		if err := runSpecGuard(ctx, cfg, *flagExisting); err != nil {
+		if err := runSpecGuard(ctx, cfg, *flagExisting, *flagAllowNonBreaking); err != nil {
 			return commandError{command: "spec-guard", err: err}
 		}
 		return nil
+	case "diff":
+		if err := runDiff(*flagBase, *flagHead, *flagReport, os.Stdout); err != nil {
+			return commandError{command: "diff", err: err}
+		}
+		return nil
 	case "help", "-h", "--help":
 		fs.Usage()
 		return nil
@@ -117,9 +132,13 @@ type generateConfig struct {
 	format      string
 	title       string
 	version     string
-	servers     []string
-	stdout      bool
-	validate    bool
+	// openAPIVersion and flattenEmbedded set the builder options of the same name.
+	openAPIVersion  string
+	flattenEmbedded bool
+	servers         []string
+	stdout          bool
+	validate        bool
+	entry           string
 }
 
 func runGenerate(ctx context.Context, cfg generateConfig) error {
@@ -149,6 +168,39 @@ func runGenerate(ctx context.Context, cfg generateConfig) error {
 }
 
 func buildSpecPayload(ctx context.Context, cfg generateConfig) ([]byte, error) {
+	entry, err := resolveEntry(cfg.projectPath, cfg.entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *openapi3.T
+	if entry != nil {
+		doc, err = loadProjectDocument(ctx, cfg, entry)
+	} else {
+		doc, err = buildInProcessDocument(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.validate {
+		if err := doc.Validate(ctx); err != nil {
+			return nil, fmt.Errorf("validate openapi: %w", err)
+		}
+	}
+
+	data, _, err := encodeDoc(doc, cfg.format)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := append([]byte(doNotEditHeader+"\n\n"), data...)
+	return payload, nil
+}
+
+// buildInProcessDocument documents routes registered in the CLI's own process. This covers
+// binaries that compile their handlers into a custom apix command.
+func buildInProcessDocument(cfg generateConfig) (*openapi3.T, error) {
 	oldWD, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("determine working directory: %w", err)
@@ -160,14 +212,15 @@ func buildSpecPayload(ctx context.Context, cfg generateConfig) ([]byte, error) {
 		_ = os.Chdir(oldWD)
 	}()
 
-	routes := apix.Snapshot()
-	if len(routes) == 0 {
-		return nil, errors.New("no routes registered; ensure your handlers register with apix")
+	if len(apix.Snapshot()) == 0 {
+		return nil, errors.New("no routes registered; mark your registration function with //apix:entry or pass --entry")
 	}
 
 	b := openapi.NewBuilder()
 	b.Info.Title = cfg.title
 	b.Info.Version = cfg.version
+	b.OpenAPIVersion = cfg.openAPIVersion
+	b.FlattenEmbedded = cfg.flattenEmbedded
 	for _, srv := range cfg.servers {
 		if strings.TrimSpace(srv) == "" {
 			continue
@@ -175,27 +228,14 @@ func buildSpecPayload(ctx context.Context, cfg generateConfig) ([]byte, error) {
 		b.Servers = append(b.Servers, &openapi3.Server{URL: srv})
 	}
 
-	doc, err := b.Build(routes)
+	doc, err := b.BuildRegistry(apix.DefaultRegistry())
 	if err != nil {
 		return nil, fmt.Errorf("build openapi: %w", err)
 	}
-
-	if cfg.validate {
-		if err := doc.Validate(ctx); err != nil {
-			return nil, fmt.Errorf("validate openapi: %w", err)
-		}
-	}
-
-	data, _, err := encodeDoc(doc, cfg.format)
-	if err != nil {
-		return nil, err
-	}
-
-	payload := append([]byte(doNotEditHeader+"\n\n"), data...)
-	return payload, nil
+	return doc, nil
 }
 
-func runSpecGuard(ctx context.Context, cfg generateConfig, existingPathFlag string) error {
+func runSpecGuard(ctx context.Context, cfg generateConfig, existingPathFlag string, allowNonBreaking bool) error {
 	tmp, err := os.CreateTemp("", "apix-spec-*.tmp")
 	if err != nil {
 		return fmt.Errorf("create temp file: %w", err)
@@ -229,13 +269,85 @@ func runSpecGuard(ctx context.Context, cfg generateConfig, existingPathFlag stri
 		return fmt.Errorf("read existing spec: %w", err)
 	}
 
-	if !bytes.Equal(expected, current) {
-		return fmt.Errorf("spec drift detected at %s; run 'apix generate' to update the committed spec", existingPath)
+	if bytes.Equal(expected, current) {
+		return nil
+	}
+
+	driftErr := fmt.Errorf("spec drift detected at %s; run 'apix generate' to update the committed spec", existingPath)
+	if !allowNonBreaking {
+		return driftErr
+	}
+
+	base, err := loadSpec(current)
+	if err != nil {
+		return fmt.Errorf("%w (committed spec could not be parsed for comparison: %v)", driftErr, err)
+	}
+	head, err := loadSpec(expected)
+	if err != nil {
+		return fmt.Errorf("parse generated spec: %w", err)
+	}
+
+	report := openapi.Diff(base, head)
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		return err
+	}
+	if report.HasBreaking() {
+		return fmt.Errorf("breaking spec changes at %s; run 'apix generate' to update the committed spec\n%s", existingPath, strings.TrimRight(buf.String(), "\n"))
+	}
+	fmt.Fprintf(os.Stderr, "non-breaking spec drift at %s:\n%s", existingPath, buf.String())
+	return nil
+}
+
+// runDiff compares two spec files and writes a report to w. It fails only when breaking changes are found.
+func runDiff(basePath, headPath, reportFormat string, w io.Writer) error {
+	if strings.TrimSpace(basePath) == "" || strings.TrimSpace(headPath) == "" {
+		return errors.New("both --base and --head are required")
+	}
+	base, err := loadSpecFile(basePath)
+	if err != nil {
+		return err
+	}
+	head, err := loadSpecFile(headPath)
+	if err != nil {
+		return err
+	}
+
+	report := openapi.Diff(base, head)
+	switch strings.ToLower(reportFormat) {
+	case "", "text":
+		err = report.WriteText(w)
+	case "json":
+		err = report.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported report format %q", reportFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
 	}
 
+	if breaking := report.Breaking(); len(breaking) > 0 {
+		return fmt.Errorf("%d breaking change(s) detected", len(breaking))
+	}
 	return nil
 }
 
+func loadSpecFile(path string) (*openapi3.T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read spec: %w", err)
+	}
+	doc, err := loadSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+func loadSpec(data []byte) (*openapi3.T, error) {
+	return openapi.DecodeDocument(data)
+}
+
 func encodeDoc(doc *openapi3.T, format string) ([]byte, string, error) {
 	return openapi.EncodeDocument(doc, format)
 }
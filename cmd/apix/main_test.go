@@ -56,7 +56,7 @@ func TestRunSpecGuardMatches(t *testing.T) {
 		toFail(t, "initial generate", err)
 	}
 
-	if err := runSpecGuard(context.Background(), cfg, ""); err != nil {
+	if err := runSpecGuard(context.Background(), cfg, "", false); err != nil {
 		toFail(t, "spec guard should pass", err)
 	}
 }
@@ -83,7 +83,7 @@ func TestRunSpecGuardDetectsDrift(t *testing.T) {
 	})
 
 	cfg := generateConfig{projectPath: root, outputPath: out, format: "yaml"}
-	err := runSpecGuard(context.Background(), cfg, "")
+	err := runSpecGuard(context.Background(), cfg, "", false)
 	if err == nil {
 		toFail(t, "expected drift detection", errors.New("no error"))
 	}
@@ -229,3 +229,80 @@ func TestParseServers(t *testing.T) {
 		t.Fatalf("expected nil slice for empty input")
 	}
 }
+
+func TestRunSpecGuardAllowNonBreaking(t *testing.T) {
+	t.Cleanup(apix.ResetRegistry)
+	root := t.TempDir()
+	out := filepath.Join(root, "openapi.yaml")
+
+	ready := &apix.RouteRef{Method: apix.MethodGet, Path: "/ready", Responses: map[int]*apix.ResponseRef{200: {}}}
+	apix.RegisterRoute(ready)
+	cfg := generateConfig{projectPath: root, outputPath: out, format: "yaml"}
+	if err := runGenerate(context.Background(), cfg); err != nil {
+		toFail(t, "initial generate", err)
+	}
+
+	apix.RegisterRoute(&apix.RouteRef{Method: apix.MethodGet, Path: "/live", Responses: map[int]*apix.ResponseRef{200: {}}})
+	if err := runSpecGuard(context.Background(), cfg, "", false); err == nil {
+		toFail(t, "expected drift without --allow-non-breaking", errors.New("no error"))
+	}
+	if err := runSpecGuard(context.Background(), cfg, "", true); err != nil {
+		toFail(t, "additive drift should be allowed", err)
+	}
+
+	apix.ResetRegistry()
+	apix.RegisterRoute(&apix.RouteRef{Method: apix.MethodGet, Path: "/live", Responses: map[int]*apix.ResponseRef{200: {}}})
+	err := runSpecGuard(context.Background(), cfg, "", true)
+	if err == nil || !strings.Contains(err.Error(), "GET /ready: operation removed") {
+		toFail(t, "expected breaking drift to fail", err)
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "base.yaml")
+	head := filepath.Join(root, "head.yaml")
+	spec := `openapi: 3.0.3
+info: {title: API, version: 1.0.0}
+paths:
+  /ping:
+    get:
+      responses:
+        "200": {description: OK}
+`
+	if err := os.WriteFile(base, []byte(doNotEditHeader+"\n\n"+spec), 0o644); err != nil {
+		toFail(t, "write base", err)
+	}
+	extended := spec + `  /pong:
+    get:
+      responses:
+        "200": {description: OK}
+`
+	if err := os.WriteFile(head, []byte(extended), 0o644); err != nil {
+		toFail(t, "write head", err)
+	}
+
+	var buf strings.Builder
+	if err := runDiff(base, head, "text", &buf); err != nil {
+		toFail(t, "non-breaking diff should succeed", err)
+	}
+	if !strings.Contains(buf.String(), "GET /pong: operation added") {
+		toFail(t, "unexpected report", errors.New(buf.String()))
+	}
+
+	buf.Reset()
+	err := runDiff(head, base, "json", &buf)
+	if err == nil || !strings.Contains(err.Error(), "1 breaking change(s)") {
+		toFail(t, "expected breaking diff to fail", err)
+	}
+	if !strings.Contains(buf.String(), `"kind": "operation-removed"`) {
+		toFail(t, "unexpected json report", errors.New(buf.String()))
+	}
+
+	if err := runDiff(base, "", "text", io.Discard); err == nil {
+		toFail(t, "expected missing --head error", errors.New("no error"))
+	}
+	if err := runDiff(base, head, "xml", io.Discard); err == nil {
+		toFail(t, "expected unsupported report error", errors.New("no error"))
+	}
+}
@@ -0,0 +1,309 @@
+package apix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DecodeJSON decodes the next JSON value from dec into dst, which must be a non-nil pointer.
+// Interface-typed values whose type was registered with RegisterUnion are decoded into the
+// concrete variant named by the discriminator. Fields tagged path, query, header or cookie
+// are not part of the body: their keys are ignored, or rejected as unknown when
+// disallowUnknown is set. When disallowUnknown is set, object keys that match no field are
+// rejected, as with json.Decoder.DisallowUnknownFields.
+//
+// Types without registered unions or parameter fields are decoded by dec directly.
+func DecodeJSON(dec *json.Decoder, dst any, disallowUnknown bool) error {
+	if disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !needsFieldDecode(rv.Type().Elem()) {
+		return dec.Decode(dst)
+	}
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	return decodeValue(raw, rv.Elem(), disallowUnknown)
+}
+
+var (
+	fieldDecodeCache sync.Map // reflect.Type -> bool
+	unmarshalerType  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// needsFieldDecode reports whether decoding t reaches a registered union interface or a
+// parameter-tagged field, which encoding/json would decode from the body.
+func needsFieldDecode(t reflect.Type) bool {
+	if cached, ok := fieldDecodeCache.Load(t); ok {
+		return cached.(bool)
+	}
+	found := needsFieldDecodeIn(t, map[reflect.Type]bool{})
+	fieldDecodeCache.Store(t, found)
+	return found
+}
+
+func needsFieldDecodeIn(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		_, ok := LookupUnion(t)
+		return ok
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return needsFieldDecodeIn(t.Elem(), seen)
+	case reflect.Struct:
+		if hasParameterTags(t) {
+			return true
+		}
+		for _, f := range jsonFields(t) {
+			if needsFieldDecodeIn(t.FieldByIndex(f.index).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeValue(raw json.RawMessage, v reflect.Value, strict bool) error {
+	if !needsFieldDecode(v.Type()) {
+		return unmarshal(raw, v.Addr().Interface(), strict)
+	}
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		v.SetZero()
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(raw, v.Elem(), strict)
+	case reflect.Interface:
+		return decodeUnion(raw, v, strict)
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := decodeValue(items[i], v.Index(i), strict); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("json: unsupported map key type %s", v.Type().Key())
+		}
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(entries)))
+		}
+		for key, item := range entries {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(item, elem, strict); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Struct:
+		return decodeStruct(raw, v, strict)
+	}
+	return unmarshal(raw, v.Addr().Interface(), strict)
+}
+
+func decodeStruct(raw json.RawMessage, v reflect.Value, strict bool) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return err
+	}
+	fields := jsonFields(v.Type())
+	for key, item := range object {
+		field, ok := matchField(fields, key)
+		if !ok {
+			if strict {
+				return fmt.Errorf("json: unknown field %q", key)
+			}
+			continue
+		}
+		target, err := fieldForDecode(v, field.index)
+		if err != nil {
+			return err
+		}
+		if err := decodeValue(item, target, strict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeUnion(raw json.RawMessage, v reflect.Value, strict bool) error {
+	info, _ := LookupUnion(v.Type())
+
+	if info.Discriminator == "" {
+		for _, variant := range info.Variants {
+			candidate := reflect.New(derefType(variant.Type))
+			if err := decodeValue(raw, candidate.Elem(), true); err == nil {
+				setVariant(v, variant, candidate)
+				return nil
+			}
+		}
+		return fmt.Errorf("json: value matches no variant of %s", v.Type())
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return err
+	}
+	tag, ok := probe[info.Discriminator]
+	if !ok {
+		return fmt.Errorf("json: missing discriminator %q for %s", info.Discriminator, v.Type())
+	}
+	var name string
+	if err := json.Unmarshal(tag, &name); err != nil {
+		return fmt.Errorf("json: discriminator %q for %s must be a string", info.Discriminator, v.Type())
+	}
+	variant, ok := info.variantFor(name)
+	if !ok {
+		return fmt.Errorf("json: unknown %s %q for %s", info.Discriminator, name, v.Type())
+	}
+	candidate := reflect.New(derefType(variant.Type))
+	if err := decodeValue(raw, candidate.Elem(), strict); err != nil {
+		return err
+	}
+	setVariant(v, variant, candidate)
+	return nil
+}
+
+func setVariant(v reflect.Value, variant UnionVariant, ptr reflect.Value) {
+	if variant.Type.Kind() == reflect.Pointer {
+		v.Set(ptr)
+		return
+	}
+	v.Set(ptr.Elem())
+}
+
+func unmarshal(raw json.RawMessage, dst any, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(dst)
+}
+
+type jsonField struct {
+	name  string
+	index []int
+}
+
+// jsonFields lists the JSON-visible fields of struct type t, promoting fields of untagged
+// embedded structs like encoding/json. Shallower fields win over promoted ones. Parameter
+// fields are left out: they are bound from the request, never from the body.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	seen := map[string]bool{}
+	var walk func(t reflect.Type, prefix []int)
+	level := []func(){}
+	walk = func(t reflect.Type, prefix []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			index := append(append([]int{}, prefix...), i)
+			tag := f.Tag.Get("json")
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" && tag == "-" {
+				continue
+			}
+			if f.Anonymous && name == "" && derefType(f.Type).Kind() == reflect.Struct {
+				embedded := derefType(f.Type)
+				level = append(level, func() { walk(embedded, index) })
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if _, _, ok := ParameterTag(f); ok {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if !seen[name] {
+				seen[name] = true
+				fields = append(fields, jsonField{name: name, index: index})
+			}
+		}
+	}
+	walk(t, nil)
+	for len(level) > 0 {
+		next := level
+		level = nil
+		for _, fn := range next {
+			fn()
+		}
+	}
+	return fields
+}
+
+// hasParameterTags reports whether t or one of its embedded structs declares a parameter field.
+func hasParameterTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, _, ok := ParameterTag(f); ok {
+			return true
+		}
+		if f.Anonymous && derefType(f.Type).Kind() == reflect.Struct && hasParameterTags(derefType(f.Type)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// fieldForDecode walks index, allocating nil embedded pointers along the way.
+func fieldForDecode(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("json: cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"reflect"
 
 	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/internal/capture"
+	"github.com/Infra-Forge/infra-apix/openapi"
 	"github.com/labstack/echo/v4"
 )
 
@@ -26,21 +29,44 @@ type Options struct {
 	Decoder         RequestDecoder
 	ResponseEncoder ResponseEncoder
 	ErrorHandler    ErrorTransformer
+	// Validator checks decoded requests after parameters are bound. Defaults to
+	// apix.DefaultValidator(), which enforces validate/binding struct tags. Validation also
+	// runs when a custom Decoder is set; use apix.NoValidation() to turn it off.
+	Validator apix.Validator
+	// MaxMultipartMemory is the number of bytes of multipart/form-data file content kept in
+	// memory; the rest is stored in temporary files. Defaults to apix.DefaultMaxMultipartMemory.
+	MaxMultipartMemory int64
 	// UseProblemDetails enables RFC 9457 Problem Details encoding for errors.
 	// When enabled, errors implementing StatusCoder will be serialized as
 	// application/problem+json instead of plain text.
 	UseProblemDetails bool
+	// Registry receives route metadata for this adapter. Defaults to apix.DefaultRegistry().
+	// Use a dedicated registry per API surface to generate separate OpenAPI documents.
+	Registry *apix.Registry
+	// ResponseValidation checks every response against the generated document. Responses
+	// are buffered while it is set, so enable it in development and tests only. Streaming
+	// routes are not validated.
+	ResponseValidation *openapi.ResponseValidator
+	// Interceptors run around every handler of the adapter, before the interceptors of
+	// groups and routes.
+	Interceptors []apix.Interceptor
+	// AutoHead also answers HEAD requests for every GET route registered with Register
+	// or Get, using the GET handler's status and headers without the body, and documents
+	// the HEAD operations.
+	AutoHead bool
 }
 
 // EchoAdapter integrates apix route registration with echo.Echo.
 type EchoAdapter struct {
-	e    *echo.Echo
-	opts Options
+	e      *echo.Echo
+	routes echoRouter
+	opts   Options
+	group  apix.Group
 }
 
 // New constructs an EchoAdapter with optional overrides.
 func New(e *echo.Echo, opts ...Options) *EchoAdapter {
-	adapter := &EchoAdapter{e: e}
+	adapter := &EchoAdapter{e: e, routes: e}
 	if len(opts) > 0 {
 		adapter.opts = opts[0]
 	}
@@ -50,49 +76,98 @@ func New(e *echo.Echo, opts ...Options) *EchoAdapter {
 // Echo exposes the underlying echo.Echo instance.
 func (a *EchoAdapter) Echo() *echo.Echo { return a.e }
 
+// Group returns an adapter registering routes below prefix on an echo.Group. See
+// apix.Group for how opts and middleware apply.
+func (a *EchoAdapter) Group(prefix string, opts ...apix.RouteOption) *EchoAdapter {
+	return &EchoAdapter{e: a.e, routes: a.routes.Group(prefix), opts: a.opts, group: a.group.Group(prefix, opts...)}
+}
+
+// Use appends middleware to the adapter's echo instance or group. See apix.Group for the
+// security it documents.
+func (a *EchoAdapter) Use(middleware ...echo.MiddlewareFunc) {
+	detect := make([]any, len(middleware))
+	for i, mw := range middleware {
+		detect[i] = mw
+	}
+	a.group = a.group.With(apix.MiddlewareSecurity(detect...))
+	a.routes.Use(middleware...)
+}
+
+// UseSecured appends middleware marked with apix.SecuredBy, adding its security
+// requirement to the routes registered afterwards.
+func (a *EchoAdapter) UseSecured(middleware ...apix.SecuredMiddleware[echo.MiddlewareFunc]) {
+	for _, mw := range middleware {
+		a.group = a.group.With(apix.MiddlewareSecurity(mw))
+		a.routes.Use(mw.Middleware)
+	}
+}
+
+// echoRouter is the routing API shared by *echo.Echo and *echo.Group.
+type echoRouter interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+	Group(prefix string, middleware ...echo.MiddlewareFunc) *echo.Group
+	Use(middleware ...echo.MiddlewareFunc)
+}
+
+// Registry returns the route registry this adapter writes to.
+func (a *EchoAdapter) Registry() *apix.Registry {
+	if a.opts.Registry != nil {
+		return a.opts.Registry
+	}
+	return apix.DefaultRegistry()
+}
+
 // Register adds a handler for the provided method and path.
 func Register[TReq any, TResp any](a *EchoAdapter, method apix.RouteMethod, path string, handler apix.HandlerFunc[TReq, TResp], opts ...apix.RouteOption) {
 	if a == nil || a.e == nil {
 		panic("apix/echo: adapter not initialised")
 	}
 
-	ref := &apix.RouteRef{
-		Method:         method,
-		Path:           path,
-		Responses:      make(map[int]*apix.ResponseRef),
-		SuccessHeaders: make(map[int][]apix.HeaderRef),
-		HandlerType:    reflect.TypeOf(handler),
-	}
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
 
-	reqType := typeOf[TReq]()
-	if reqType != nil && !isNoBody(reqType) {
-		ref.RequestType = reqType
-		if ref.RequestContentType == "" {
-			ref.RequestContentType = "application/json"
-		}
+	respType := apix.ResponseBodyType(typeOf[TResp]())
+	if respType == nil {
+		respType = reflect.TypeOf(struct{}{})
 	}
+	apix.EnsureSuccessResponses(ref, respType)
 
-	for _, opt := range opts {
-		opt(ref)
+	if ref.OperationID == "" {
+		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	if ref.SuccessStatus == 0 {
-		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildEchoHandler(a, handler, ref))
+
+	if method == apix.MethodGet && a.opts.AutoHead {
+		head := apix.HeadRoute(ref)
+		a.Registry().Register(head)
+		a.handle(head, path, buildEchoHandler(a, handler, head))
 	}
+}
 
-	respType := typeOf[TResp]()
-	if respType == nil {
-		respType = reflect.TypeOf(struct{}{})
+// Stream adds a server-sent events handler for the provided method and path.
+func Stream[TReq any, TEvent any](a *EchoAdapter, method apix.RouteMethod, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	if a == nil || a.e == nil {
+		panic("apix/echo: adapter not initialised")
 	}
-	apix.EnsureResponse(ref, ref.SuccessStatus, respType)
+
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
+	apix.EnsureEventStreamResponse(ref, typeOf[TEvent]())
 
 	if ref.OperationID == "" {
 		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	apix.RegisterRoute(ref)
+	a.Registry().Register(ref)
 
-	a.e.Add(string(method), path, buildEchoHandler(a, handler, ref))
+	a.handle(ref, path, buildEchoStreamHandler(a, handler, ref))
+}
+
+// SSE registers a GET handler streaming server-sent events. TReq carries the route's
+// path/query/header parameters.
+func SSE[TReq any, TEvent any](a *EchoAdapter, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	Stream[TReq, TEvent](a, apix.MethodGet, path, handler, opts...)
 }
 
 // Get registers a GET handler.
@@ -120,36 +195,204 @@ func Delete[TResp any](a *EchoAdapter, path string, handler apix.HandlerFunc[api
 	Register[apix.NoBody, TResp](a, apix.MethodDelete, path, handler, opts...)
 }
 
+// Head registers a HEAD handler. Its responses are documented without bodies.
+func Head[TResp any](a *EchoAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodHead, path, handler, opts...)
+}
+
+// OptionsRoute registers an OPTIONS handler. It is not called Options, the name of the
+// adapter configuration type.
+func OptionsRoute[TResp any](a *EchoAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodOptions, path, handler, opts...)
+}
+
+// Trace registers a TRACE handler.
+func Trace[TResp any](a *EchoAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodTrace, path, handler, opts...)
+}
+
+func (a *EchoAdapter) handle(ref *apix.RouteRef, path string, h echo.HandlerFunc) {
+	a.routes.Add(string(ref.Method), path, h, routeMiddleware(ref)...)
+}
+
+// routeMiddleware returns the middleware attached to ref with apix.WithMiddleware.
+func routeMiddleware(ref *apix.RouteRef) []echo.MiddlewareFunc {
+	out := make([]echo.MiddlewareFunc, 0, len(ref.Middleware))
+	for _, mw := range ref.Middleware {
+		switch fn := apix.UnwrapMiddleware(mw).(type) {
+		case echo.MiddlewareFunc:
+			out = append(out, fn)
+		case func(echo.HandlerFunc) echo.HandlerFunc:
+			out = append(out, fn)
+		default:
+			panic(fmt.Sprintf("apix/echo: middleware %T of %s %s is not an echo.MiddlewareFunc", mw, ref.Method, ref.Path))
+		}
+	}
+	return out
+}
+
 func buildEchoHandler[TReq any, TResp any](a *EchoAdapter, handler apix.HandlerFunc[TReq, TResp], ref *apix.RouteRef) echo.HandlerFunc {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	serve := func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		if err != nil {
+			capture.Reject(c.Response().Writer)
+			return a.transformError(err)
+		}
+
+		resp, err := apix.InterceptHandler(ctx, call, interceptors, reqPtr, handler)
+		if err != nil {
+			return a.transformError(err)
+		}
+
+		status, header, body, err := apix.ResolveResponse(resp, ref.SuccessStatus)
+		if err != nil {
+			return a.transformError(err)
+		}
+		addHeaders(c.Response().Header(), header)
+		return a.encode(ctx, c, status, body, ref)
+	}
+
+	if a.opts.ResponseValidation == nil {
+		return serve
+	}
+
+	return func(c echo.Context) error {
+		res := c.Response()
+		w := res.Writer
+		rec := capture.NewRecorder()
+		res.Writer = rec
+		if err := serve(c); err != nil {
+			// Render the error while capturing so error responses are validated too.
+			c.Error(err)
+		}
+		res.Writer = w
+
+		validate := a.opts.ResponseValidation.ValidateResponse
+		if rec.Rejected() {
+			validate = a.opts.ResponseValidation.ValidateRejection
+		}
+		if err := validate(c.Request().Context(), a.Registry(), ref, rec.Status(), rec.Header(), rec.Body()); err != nil {
+			res.Committed = false
+			res.Size = 0
+			return a.transformError(err)
+		}
+		return rec.FlushTo(w)
+	}
+}
+
+func buildEchoStreamHandler[TReq any, TEvent any](a *EchoAdapter, handler apix.StreamHandlerFunc[TReq, TEvent], ref *apix.RouteRef) echo.HandlerFunc {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
-		var reqPtr *TReq
-		if ref.RequestType != nil {
-			reqVal := new(TReq)
-			if err := a.decode(ctx, c, reqVal); err != nil {
-				return a.transformError(err)
-			}
-			reqPtr = reqVal
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		if err != nil {
+			return a.transformError(err)
 		}
 
-		resp, err := handler(ctx, reqPtr)
+		res := c.Response()
+		events := apix.NewEventSender[TEvent](ctx, res, func() {
+			apix.SetEventStreamHeaders(res.Header())
+			res.WriteHeader(ref.SuccessStatus)
+		}, http.NewResponseController(res).Flush)
+		err = handler(ctx, reqPtr, events)
 		if err != nil {
+			err = apix.InterceptError(ctx, call, interceptors, err)
+		}
+		if err != nil && !events.Opened() {
 			return a.transformError(err)
 		}
+		_ = events.Close(err)
+		return nil
+	}
+}
+
+// newRouteRef captures the route metadata shared by typed and streaming handlers. path is
+// relative to the adapter's group, whose options apply before opts.
+func newRouteRef[TReq any](group apix.Group, method apix.RouteMethod, path string, handler any, opts []apix.RouteOption) *apix.RouteRef {
+	ref := &apix.RouteRef{
+		Method:         method,
+		Path:           group.Path(path),
+		Responses:      make(map[int]*apix.ResponseRef),
+		SuccessHeaders: make(map[int][]apix.HeaderRef),
+		HandlerType:    reflect.TypeOf(handler),
+	}
+
+	reqType := typeOf[TReq]()
+	if reqType != nil && !isNoBody(reqType) {
+		ref.RequestType = reqType
+		if ref.RequestContentType == "" && apix.HasBodyFields(reqType) {
+			ref.RequestContentType = "application/json"
+		}
+	}
+
+	group.Apply(ref, opts)
+
+	if ref.SuccessStatus == 0 {
+		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	}
+	return ref
+}
+
+// decodeRequest decodes the body, binds parameters and validates the result, running the
+// interceptors' decode hooks around it. The request is nil for routes without a request type.
+func decodeRequest[TReq any](a *EchoAdapter, ctx context.Context, c echo.Context, ref *apix.RouteRef, hasBody bool, call *apix.Call, interceptors []apix.Interceptor) (context.Context, *TReq, error) {
+	return apix.InterceptDecode(ctx, call, interceptors, func(ctx context.Context) (*TReq, error) {
+		if ref.RequestType == nil {
+			return nil, nil
+		}
+		reqVal := new(TReq)
+		if hasBody {
+			if err := a.decode(ctx, c, ref.RequestContentType, reqVal); err != nil {
+				return nil, err
+			}
+		}
+		if err := apix.BindParameters(reqVal, call.Params); err != nil {
+			return nil, err
+		}
+		if err := apix.Validate(a.opts.Validator, reqVal); err != nil {
+			return nil, err
+		}
+		return reqVal, nil
+	})
+}
 
-		return a.encode(ctx, c, ref.SuccessStatus, resp, ref)
+// interceptors returns the route's interceptor pipeline, led by the enforcement of its
+// security requirements.
+func (a *EchoAdapter) interceptors(ref *apix.RouteRef) []apix.Interceptor {
+	interceptors := apix.RouteInterceptors(a.opts.Interceptors, ref)
+	if security, ok := apix.SecurityInterceptor(a.Registry(), ref); ok {
+		interceptors = append([]apix.Interceptor{security}, interceptors...)
 	}
+	return interceptors
+}
+
+// newCall describes the request to interceptors.
+func newCall(ref *apix.RouteRef, c echo.Context) *apix.Call {
+	return &apix.Call{Route: ref, Params: apix.NewRequestSource(c.Request(), c.Param), TLS: c.Request().TLS}
 }
 
-func (a *EchoAdapter) decode(ctx context.Context, c echo.Context, dst any) error {
+func (a *EchoAdapter) decode(ctx context.Context, c echo.Context, contentType string, dst any) error {
 	if dec := a.opts.Decoder; dec != nil {
 		return dec(ctx, c, dst)
 	}
-	return defaultDecoder(ctx, c, dst)
+	return defaultDecoder(ctx, c, contentType, a.opts.MaxMultipartMemory, dst)
 }
 
 func (a *EchoAdapter) encode(ctx context.Context, c echo.Context, status int, payload any, ref *apix.RouteRef) error {
+	// Files and raw bodies bypass the encoder: they are written as they are.
+	if content, ok := apix.AsBinary(payload); ok {
+		return content.Write(c.Response(), c.Request(), status)
+	}
 	if enc := a.opts.ResponseEncoder; enc != nil {
 		return enc(ctx, c, status, payload, ref)
 	}
@@ -169,6 +412,12 @@ func (a *EchoAdapter) transformError(err error) error {
 // defaultErrorTransformer converts apix errors to Echo HTTPError format.
 // If useProblemDetails is true, wraps the error with ProblemDetails metadata.
 func defaultErrorTransformer(err error, useProblemDetails bool) error {
+	// Validation errors carry per-field details
+	var validationErr *apix.ValidationError
+	if errors.As(err, &validationErr) && !useProblemDetails {
+		return echo.NewHTTPError(validationErr.HTTPStatus(), validationErr.ErrorResponse())
+	}
+
 	// Check for StatusCoder interface (new pattern)
 	var statusCoder apix.StatusCoder
 	if errors.As(err, &statusCoder) {
@@ -183,21 +432,28 @@ func defaultErrorTransformer(err error, useProblemDetails bool) error {
 	return err
 }
 
-func defaultDecoder(ctx context.Context, c echo.Context, dst any) error {
+// defaultDecoder binds form bodies for routes declaring a form content type and decodes
+// JSON otherwise.
+func defaultDecoder(ctx context.Context, c echo.Context, contentType string, maxMemory int64, dst any) error {
 	req := c.Request()
-	if req.Body == nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "request body required")
-	}
-	decoder := json.NewDecoder(req.Body)
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(dst); err != nil {
-		if errors.Is(err, io.EOF) {
+	if apix.IsFormContentType(contentType) {
+		if err := apix.DecodeForm(req, contentType, maxMemory, dst); err != nil {
+			return err
+		}
+	} else {
+		if req.Body == nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "request body required")
 		}
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-	if decoder.More() {
-		return echo.NewHTTPError(http.StatusBadRequest, "unexpected additional JSON content")
+		decoder := json.NewDecoder(req.Body)
+		if err := apix.DecodeJSON(decoder, dst, true); err != nil {
+			if errors.Is(err, io.EOF) {
+				return echo.NewHTTPError(http.StatusBadRequest, "request body required")
+			}
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if decoder.More() {
+			return echo.NewHTTPError(http.StatusBadRequest, "unexpected additional JSON content")
+		}
 	}
 	if v := c.Echo().Validator; v != nil {
 		if err := v.Validate(dst); err != nil {
@@ -222,6 +478,15 @@ func typeOf[T any]() reflect.Type {
 	return t
 }
 
+// addHeaders copies headers set by an apix.Response onto the outgoing response.
+func addHeaders(dst, src http.Header) {
+	for key, values := range src {
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
+
 var noBodyType = reflect.TypeOf(apix.NoBody{})
 
 func isNoBody(t reflect.Type) bool {
@@ -234,6 +499,30 @@ func isNoBody(t reflect.Type) bool {
 	return t == noBodyType
 }
 
+// RequestValidator checks live requests against the API description, such as
+// *runtime.RequestValidator.
+type RequestValidator interface {
+	Validate(r *http.Request) error
+}
+
+// ValidateRequests returns middleware that rejects requests v reports as invalid with an
+// application/problem+json response.
+func ValidateRequests(v RequestValidator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := v.Validate(c.Request()); err != nil {
+				problem := apix.ToProblemDetails(err)
+				data, marshalErr := json.Marshal(problem)
+				if marshalErr != nil {
+					return marshalErr
+				}
+				return c.Blob(problem.HTTPStatus(), "application/problem+json", data)
+			}
+			return next(c)
+		}
+	}
+}
+
 // ProblemDetailsErrorHandler returns an Echo error handler that serializes
 // ProblemDetails errors as application/problem+json.
 // Use this with e.HTTPErrorHandler when UseProblemDetails is enabled.
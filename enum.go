@@ -0,0 +1,138 @@
+package apix
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Enum is implemented by types with a fixed set of allowed values, such as string-typed
+// status codes. The OpenAPI builder documents named enum types as reusable components with
+// an `enum` list.
+//
+// Example:
+//
+//	type ProcessStatus string
+//
+//	func (ProcessStatus) EnumValues() []any {
+//		return []any{ProcessPending, ProcessRunning, ProcessDone}
+//	}
+type Enum interface {
+	EnumValues() []any
+}
+
+// EnumVarNamer optionally names each value returned by EnumValues, in the same order.
+// Names are emitted as `x-enum-varnames` so generated clients get readable constants.
+type EnumVarNamer interface {
+	EnumVarNames() []string
+}
+
+// EnumDescriber optionally describes each value returned by EnumValues, in the same order.
+// Descriptions are emitted as `x-enum-descriptions`.
+type EnumDescriber interface {
+	EnumDescriptions() []string
+}
+
+// EnumMember describes one value of an enum registered with RegisterEnumMembers.
+type EnumMember[T any] struct {
+	Value       T
+	Name        string
+	Description string
+}
+
+// EnumInfo is the resolved metadata of an enum type.
+type EnumInfo struct {
+	Values       []any
+	VarNames     []string
+	Descriptions []string
+}
+
+var enumRegistry = struct {
+	mu    sync.RWMutex
+	enums map[reflect.Type]EnumInfo
+}{enums: make(map[reflect.Type]EnumInfo)}
+
+// RegisterEnum declares the allowed values of T, for types that cannot implement Enum
+// (for example types from another module). Registration replaces any earlier one for T and
+// takes precedence over an Enum implementation.
+func RegisterEnum[T any](values ...T) {
+	info := EnumInfo{Values: make([]any, len(values))}
+	for i, v := range values {
+		info.Values[i] = v
+	}
+	registerEnum(reflect.TypeFor[T](), info)
+}
+
+// RegisterEnumMembers is like RegisterEnum but also records a name and description for
+// each value. Empty names or descriptions are omitted from the document.
+func RegisterEnumMembers[T any](members ...EnumMember[T]) {
+	info := EnumInfo{Values: make([]any, len(members))}
+	var names, descriptions []string
+	for i, m := range members {
+		info.Values[i] = m.Value
+		names = append(names, m.Name)
+		descriptions = append(descriptions, m.Description)
+	}
+	if !allEmpty(names) {
+		info.VarNames = names
+	}
+	if !allEmpty(descriptions) {
+		info.Descriptions = descriptions
+	}
+	registerEnum(reflect.TypeFor[T](), info)
+}
+
+// UnregisterEnum removes a registration made with RegisterEnum or RegisterEnumMembers.
+func UnregisterEnum[T any]() {
+	enumRegistry.mu.Lock()
+	defer enumRegistry.mu.Unlock()
+	delete(enumRegistry.enums, reflect.TypeFor[T]())
+}
+
+// LookupEnum returns the enum metadata for t, from a registration or from t implementing
+// Enum (with optional EnumVarNamer and EnumDescriber).
+func LookupEnum(t reflect.Type) (EnumInfo, bool) {
+	if t == nil || t.Kind() == reflect.Interface {
+		return EnumInfo{}, false
+	}
+	enumRegistry.mu.RLock()
+	info, ok := enumRegistry.enums[t]
+	enumRegistry.mu.RUnlock()
+	if ok {
+		return info, true
+	}
+
+	var zero any
+	switch {
+	case t.Implements(enumType):
+		zero = reflect.Zero(t).Interface()
+	case t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(enumType):
+		zero = reflect.New(t).Interface()
+	default:
+		return EnumInfo{}, false
+	}
+	info = EnumInfo{Values: zero.(Enum).EnumValues()}
+	if namer, ok := zero.(EnumVarNamer); ok {
+		info.VarNames = namer.EnumVarNames()
+	}
+	if describer, ok := zero.(EnumDescriber); ok {
+		info.Descriptions = describer.EnumDescriptions()
+	}
+	return info, true
+}
+
+var enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+func registerEnum(t reflect.Type, info EnumInfo) {
+	enumRegistry.mu.Lock()
+	defer enumRegistry.mu.Unlock()
+	enumRegistry.enums[t] = info
+}
+
+func allEmpty(values []string) bool {
+	for _, v := range values {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
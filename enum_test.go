@@ -0,0 +1,66 @@
+package apix_test
+
+import (
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+type processStatus string
+
+func (processStatus) EnumValues() []any {
+	return []any{processStatus("pending"), processStatus("done")}
+}
+
+func (processStatus) EnumVarNames() []string { return []string{"Pending", "Done"} }
+
+type priority int
+
+func (*priority) EnumValues() []any { return []any{1, 2, 3} }
+
+type foreignKind string
+
+func TestLookupEnumFromInterface(t *testing.T) {
+	info, ok := apix.LookupEnum(reflect.TypeOf(processStatus("")))
+	if !ok {
+		t.Fatal("expected processStatus to be an enum")
+	}
+	if !reflect.DeepEqual(info.Values, []any{processStatus("pending"), processStatus("done")}) || !reflect.DeepEqual(info.VarNames, []string{"Pending", "Done"}) {
+		t.Fatalf("unexpected enum info %+v", info)
+	}
+	if info.Descriptions != nil {
+		t.Fatalf("expected no descriptions, got %v", info.Descriptions)
+	}
+
+	if info, ok := apix.LookupEnum(reflect.TypeOf(priority(0))); !ok || len(info.Values) != 3 {
+		t.Fatalf("expected pointer-receiver enum to be detected, got %+v %v", info, ok)
+	}
+	if _, ok := apix.LookupEnum(reflect.TypeOf("")); ok {
+		t.Fatal("plain strings must not be enums")
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+	apix.RegisterEnum(foreignKind("a"), foreignKind("b"))
+	defer apix.UnregisterEnum[foreignKind]()
+
+	info, ok := apix.LookupEnum(reflect.TypeOf(foreignKind("")))
+	if !ok || !reflect.DeepEqual(info.Values, []any{foreignKind("a"), foreignKind("b")}) {
+		t.Fatalf("unexpected registration %+v %v", info, ok)
+	}
+
+	apix.RegisterEnumMembers(
+		apix.EnumMember[foreignKind]{Value: "a", Name: "KindA", Description: "first"},
+		apix.EnumMember[foreignKind]{Value: "b", Name: "KindB"},
+	)
+	info, _ = apix.LookupEnum(reflect.TypeOf(foreignKind("")))
+	if !reflect.DeepEqual(info.VarNames, []string{"KindA", "KindB"}) || !reflect.DeepEqual(info.Descriptions, []string{"first", ""}) {
+		t.Fatalf("unexpected member registration %+v", info)
+	}
+
+	apix.UnregisterEnum[foreignKind]()
+	if _, ok := apix.LookupEnum(reflect.TypeOf(foreignKind(""))); ok {
+		t.Fatal("expected registration to be removed")
+	}
+}
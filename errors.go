@@ -394,6 +394,12 @@ func ToProblemDetails(err error) *ProblemDetails {
 		return pd
 	}
 
+	// Validation errors carry per-field details in the "errors" extension member
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.ProblemDetails()
+	}
+
 	// Check if it's an HTTPError (handles wrapped errors)
 	var httpErr *HTTPError
 	if errors.As(err, &httpErr) {
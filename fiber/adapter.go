@@ -1,17 +1,24 @@
 package fiber
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 
 	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
 )
 
 // RequestDecoder decodes the HTTP request body into dst, enforcing validation rules.
@@ -28,21 +35,46 @@ type Options struct {
 	Decoder         RequestDecoder
 	ResponseEncoder ResponseEncoder
 	ErrorHandler    ErrorHandler
+	// Validator checks decoded requests after parameters are bound. Defaults to
+	// apix.DefaultValidator(), which enforces validate/binding struct tags. Validation also
+	// runs when a custom Decoder is set; use apix.NoValidation() to turn it off.
+	Validator apix.Validator
+	// MaxMultipartMemory is the number of bytes of multipart/form-data file content kept in
+	// memory; the rest is stored in temporary files, removed once the handler returns. Fiber
+	// still buffers the whole request body, bounded by fiber.Config.BodyLimit. Defaults to
+	// apix.DefaultMaxMultipartMemory.
+	MaxMultipartMemory int64
 	// UseProblemDetails enables RFC 9457 Problem Details encoding for errors.
 	// When enabled, errors implementing StatusCoder will be serialized as
 	// application/problem+json instead of plain text.
 	UseProblemDetails bool
+	// Registry receives route metadata for this adapter. Defaults to apix.DefaultRegistry().
+	// Use a dedicated registry per API surface to generate separate OpenAPI documents.
+	Registry *apix.Registry
+	// ResponseValidation checks every response against the generated document. Responses
+	// are buffered while it is set, so enable it in development and tests only. Streaming
+	// routes are not validated.
+	ResponseValidation *openapi.ResponseValidator
+	// Interceptors run around every handler of the adapter, before the interceptors of
+	// groups and routes.
+	Interceptors []apix.Interceptor
+	// AutoHead also answers HEAD requests for every GET route registered with Register
+	// or Get, using the GET handler's status and headers without the body, and documents
+	// the HEAD operations.
+	AutoHead bool
 }
 
 // FiberAdapter integrates apix route registration with fiber.App.
 type FiberAdapter struct {
-	app  *fiber.App
-	opts Options
+	app    *fiber.App
+	router fiber.Router
+	opts   Options
+	group  apix.Group
 }
 
 // New constructs a FiberAdapter with optional overrides.
 func New(app *fiber.App, opts ...Options) *FiberAdapter {
-	adapter := &FiberAdapter{app: app}
+	adapter := &FiberAdapter{app: app, router: app}
 	if len(opts) > 0 {
 		adapter.opts = opts[0]
 	}
@@ -52,49 +84,93 @@ func New(app *fiber.App, opts ...Options) *FiberAdapter {
 // App exposes the underlying fiber.App instance.
 func (a *FiberAdapter) App() *fiber.App { return a.app }
 
-// Register adds a handler for the provided method and path.
+// Group returns an adapter registering routes below prefix on a fiber.Router group. See
+// apix.Group for how opts and middleware apply.
+func (a *FiberAdapter) Group(prefix string, opts ...apix.RouteOption) *FiberAdapter {
+	return &FiberAdapter{app: a.app, router: a.router.Group(prefix), opts: a.opts, group: a.group.Group(prefix, opts...)}
+}
+
+// Use appends middleware to the adapter's app or group. Fiber runs middleware in
+// registration order, so add it before the routes it should wrap. See apix.Group for the
+// security it documents.
+func (a *FiberAdapter) Use(middleware ...fiber.Handler) {
+	args := make([]any, len(middleware))
+	for i, mw := range middleware {
+		args[i] = mw
+	}
+	a.group = a.group.With(apix.MiddlewareSecurity(args...))
+	a.router.Use(args...)
+}
+
+// UseSecured appends middleware marked with apix.SecuredBy, adding its security
+// requirement to the routes registered afterwards.
+func (a *FiberAdapter) UseSecured(middleware ...apix.SecuredMiddleware[fiber.Handler]) {
+	for _, mw := range middleware {
+		a.group = a.group.With(apix.MiddlewareSecurity(mw))
+		a.router.Use(mw.Middleware)
+	}
+}
+
+// Registry returns the route registry this adapter writes to.
+func (a *FiberAdapter) Registry() *apix.Registry {
+	if a.opts.Registry != nil {
+		return a.opts.Registry
+	}
+	return apix.DefaultRegistry()
+}
+
+// Register adds a handler for the provided method and path. Methods outside
+// fiber.DefaultMethods must be listed in the app's Config.RequestMethods.
 func Register[TReq any, TResp any](a *FiberAdapter, method apix.RouteMethod, path string, handler apix.HandlerFunc[TReq, TResp], opts ...apix.RouteOption) {
 	if a == nil || a.app == nil {
 		panic("apix/fiber: adapter not initialised")
 	}
 
-	ref := &apix.RouteRef{
-		Method:         method,
-		Path:           path,
-		Responses:      make(map[int]*apix.ResponseRef),
-		SuccessHeaders: make(map[int][]apix.HeaderRef),
-		HandlerType:    reflect.TypeOf(handler),
-	}
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
 
-	reqType := typeOf[TReq]()
-	if reqType != nil && !isNoBody(reqType) {
-		ref.RequestType = reqType
-		if ref.RequestContentType == "" {
-			ref.RequestContentType = "application/json"
-		}
+	respType := apix.ResponseBodyType(typeOf[TResp]())
+	if respType == nil {
+		respType = reflect.TypeOf(struct{}{})
 	}
+	apix.EnsureSuccessResponses(ref, respType)
 
-	for _, opt := range opts {
-		opt(ref)
+	if ref.OperationID == "" {
+		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	if ref.SuccessStatus == 0 {
-		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildFiberHandler(a, handler, ref))
+
+	if method == apix.MethodGet && a.opts.AutoHead {
+		head := apix.HeadRoute(ref)
+		a.Registry().Register(head)
+		a.handle(head, path, buildFiberHandler(a, handler, head))
 	}
+}
 
-	respType := typeOf[TResp]()
-	if respType == nil {
-		respType = reflect.TypeOf(struct{}{})
+// Stream adds a server-sent events handler for the provided method and path.
+func Stream[TReq any, TEvent any](a *FiberAdapter, method apix.RouteMethod, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	if a == nil || a.app == nil {
+		panic("apix/fiber: adapter not initialised")
 	}
-	apix.EnsureResponse(ref, ref.SuccessStatus, respType)
+
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
+	apix.EnsureEventStreamResponse(ref, typeOf[TEvent]())
 
 	if ref.OperationID == "" {
 		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	apix.RegisterRoute(ref)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildFiberStreamHandler(a, handler, ref))
+}
 
-	a.app.Add([]string{string(method)}, path, buildFiberHandler(a, handler, ref))
+// SSE registers a GET handler streaming server-sent events. TReq carries the route's
+// path/query/header parameters.
+func SSE[TReq any, TEvent any](a *FiberAdapter, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	Stream[TReq, TEvent](a, apix.MethodGet, path, handler, opts...)
 }
 
 // Get registers a GET handler.
@@ -122,39 +198,228 @@ func Delete[TResp any](a *FiberAdapter, path string, handler apix.HandlerFunc[ap
 	Register[apix.NoBody, TResp](a, apix.MethodDelete, path, handler, opts...)
 }
 
+// Head registers a HEAD handler. Its responses are documented without bodies.
+func Head[TResp any](a *FiberAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodHead, path, handler, opts...)
+}
+
+// OptionsRoute registers an OPTIONS handler. It is not called Options, the name of the
+// adapter configuration type.
+func OptionsRoute[TResp any](a *FiberAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodOptions, path, handler, opts...)
+}
+
+// Trace registers a TRACE handler.
+func Trace[TResp any](a *FiberAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodTrace, path, handler, opts...)
+}
+
+func (a *FiberAdapter) handle(ref *apix.RouteRef, path string, h fiber.Handler) {
+	handlers := append(routeMiddleware(ref), h)
+	a.router.Add([]string{string(ref.Method)}, path, handlers[0], handlers[1:]...)
+}
+
+// routeMiddleware returns the middleware attached to ref with apix.WithMiddleware.
+func routeMiddleware(ref *apix.RouteRef) []fiber.Handler {
+	out := make([]fiber.Handler, 0, len(ref.Middleware)+1)
+	for _, mw := range ref.Middleware {
+		fn, ok := apix.UnwrapMiddleware(mw).(fiber.Handler)
+		if !ok {
+			panic(fmt.Sprintf("apix/fiber: middleware %T of %s %s is not a fiber.Handler", mw, ref.Method, ref.Path))
+		}
+		out = append(out, fn)
+	}
+	return out
+}
+
+// rejectedKey marks, in the request locals, a response the adapter wrote for a request it
+// rejected before the handler ran, so response validation accepts an undocumented 400 or 422.
+type rejectedKey struct{}
+
 func buildFiberHandler[TReq any, TResp any](a *FiberAdapter, handler apix.HandlerFunc[TReq, TResp], ref *apix.RouteRef) fiber.Handler {
-	return func(c fiber.Ctx) error {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	serve := func(c fiber.Ctx) error {
 		ctx := c.Context()
+		defer func() { removeForm(multipartForm(c)) }()
 
-		var reqPtr *TReq
-		if ref.RequestType != nil {
-			reqVal := new(TReq)
-			if err := a.decode(ctx, c, reqVal); err != nil {
-				return a.handleError(ctx, c, err)
-			}
-			reqPtr = reqVal
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		if err != nil {
+			c.Locals(rejectedKey{}, true)
+			return a.handleError(ctx, c, err)
 		}
 
-		resp, err := handler(ctx, reqPtr)
+		resp, err := apix.InterceptHandler(ctx, call, interceptors, reqPtr, handler)
 		if err != nil {
 			return a.handleError(ctx, c, err)
 		}
 
-		if err := a.encode(ctx, c, ref.SuccessStatus, resp, ref); err != nil {
+		status, header, body, err := apix.ResolveResponse(resp, ref.SuccessStatus)
+		if err != nil {
+			return a.handleError(ctx, c, err)
+		}
+		for key, values := range header {
+			for _, v := range values {
+				c.Response().Header.Add(key, v)
+			}
+		}
+		if err := a.encode(ctx, c, status, body, ref); err != nil {
 			return a.handleError(ctx, c, err)
 		}
 		return nil
 	}
+
+	if a.opts.ResponseValidation == nil {
+		return serve
+	}
+
+	return func(c fiber.Ctx) error {
+		// Fiber buffers responses, so the written response can be inspected in place.
+		if err := serve(c); err != nil {
+			return err
+		}
+		header := http.Header{}
+		for key, values := range c.GetRespHeaders() {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+		validate := a.opts.ResponseValidation.ValidateResponse
+		if rejected, _ := c.Locals(rejectedKey{}).(bool); rejected {
+			validate = a.opts.ResponseValidation.ValidateRejection
+		}
+		if err := validate(c.Context(), a.Registry(), ref, c.Response().StatusCode(), header, c.Response().Body()); err != nil {
+			c.Response().Reset()
+			return a.handleError(c.Context(), c, err)
+		}
+		return nil
+	}
+}
+
+func buildFiberStreamHandler[TReq any, TEvent any](a *FiberAdapter, handler apix.StreamHandlerFunc[TReq, TEvent], ref *apix.RouteRef) fiber.Handler {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	return func(c fiber.Ctx) error {
+		ctx := c.Context()
+
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		form := multipartForm(c)
+		if err != nil {
+			removeForm(form)
+			return a.handleError(ctx, c, err)
+		}
+
+		header := http.Header{}
+		apix.SetEventStreamHeaders(header)
+		for key := range header {
+			c.Set(key, header.Get(key))
+		}
+		c.Status(ref.SuccessStatus)
+
+		// Fiber writes the body after the handler returns and may reuse c by then, so the
+		// stream writer works from a copy of the parameters and never touches c. The stream
+		// is already open when the handler runs and its errors are sent as error events. A
+		// failed flush means the client went away.
+		call.Params = snapshotParameters(c)
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			defer removeForm(form)
+			streamCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			events := apix.NewEventSender[TEvent](streamCtx, w, nil, func() error {
+				if err := w.Flush(); err != nil {
+					cancel()
+					return err
+				}
+				return nil
+			})
+			err := handler(streamCtx, reqPtr, events)
+			if err != nil {
+				err = apix.InterceptError(streamCtx, call, interceptors, err)
+			}
+			_ = events.Close(err)
+		})
+	}
+}
+
+// newRouteRef captures the route metadata shared by typed and streaming handlers. path is
+// relative to the adapter's group, whose options apply before opts.
+func newRouteRef[TReq any](group apix.Group, method apix.RouteMethod, path string, handler any, opts []apix.RouteOption) *apix.RouteRef {
+	ref := &apix.RouteRef{
+		Method:         method,
+		Path:           group.Path(path),
+		Responses:      make(map[int]*apix.ResponseRef),
+		SuccessHeaders: make(map[int][]apix.HeaderRef),
+		HandlerType:    reflect.TypeOf(handler),
+	}
+
+	reqType := typeOf[TReq]()
+	if reqType != nil && !isNoBody(reqType) {
+		ref.RequestType = reqType
+		if ref.RequestContentType == "" && apix.HasBodyFields(reqType) {
+			ref.RequestContentType = "application/json"
+		}
+	}
+
+	group.Apply(ref, opts)
+
+	if ref.SuccessStatus == 0 {
+		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	}
+	return ref
+}
+
+// decodeRequest decodes the body, binds parameters and validates the result, running the
+// interceptors' decode hooks around it. The request is nil for routes without a request type.
+func decodeRequest[TReq any](a *FiberAdapter, ctx context.Context, c fiber.Ctx, ref *apix.RouteRef, hasBody bool, call *apix.Call, interceptors []apix.Interceptor) (context.Context, *TReq, error) {
+	return apix.InterceptDecode(ctx, call, interceptors, func(ctx context.Context) (*TReq, error) {
+		if ref.RequestType == nil {
+			return nil, nil
+		}
+		reqVal := new(TReq)
+		if hasBody {
+			if err := a.decode(ctx, c, ref.RequestContentType, reqVal); err != nil {
+				return nil, err
+			}
+		}
+		if err := apix.BindParameters(reqVal, call.Params); err != nil {
+			return nil, err
+		}
+		if err := apix.Validate(a.opts.Validator, reqVal); err != nil {
+			return nil, err
+		}
+		return reqVal, nil
+	})
+}
+
+// interceptors returns the route's interceptor pipeline, led by the enforcement of its
+// security requirements.
+func (a *FiberAdapter) interceptors(ref *apix.RouteRef) []apix.Interceptor {
+	interceptors := apix.RouteInterceptors(a.opts.Interceptors, ref)
+	if security, ok := apix.SecurityInterceptor(a.Registry(), ref); ok {
+		interceptors = append([]apix.Interceptor{security}, interceptors...)
+	}
+	return interceptors
 }
 
-func (a *FiberAdapter) decode(ctx context.Context, c fiber.Ctx, dst any) error {
+// newCall describes the request to interceptors.
+func newCall(ref *apix.RouteRef, c fiber.Ctx) *apix.Call {
+	return &apix.Call{Route: ref, Params: parameterSource{c: c}, TLS: c.RequestCtx().TLSConnectionState()}
+}
+
+func (a *FiberAdapter) decode(ctx context.Context, c fiber.Ctx, contentType string, dst any) error {
 	if dec := a.opts.Decoder; dec != nil {
 		return dec(ctx, c, dst)
 	}
-	return defaultDecoder(ctx, c, dst)
+	return defaultDecoder(ctx, c, contentType, a.opts.MaxMultipartMemory, dst)
 }
 
 func (a *FiberAdapter) encode(ctx context.Context, c fiber.Ctx, status int, payload any, ref *apix.RouteRef) error {
+	// Files and raw bodies bypass the encoder: they are written as they are.
+	if content, ok := apix.AsBinary(payload); ok {
+		return sendBinary(c, status, content)
+	}
 	if enc := a.opts.ResponseEncoder; enc != nil {
 		return enc(ctx, c, status, payload, ref)
 	}
@@ -171,14 +436,18 @@ func (a *FiberAdapter) handleError(ctx context.Context, c fiber.Ctx, err error)
 	return defaultErrorHandler(ctx, c, err, a.opts.UseProblemDetails)
 }
 
-func defaultDecoder(ctx context.Context, c fiber.Ctx, dst any) error {
+// defaultDecoder binds form bodies for routes declaring a form content type and decodes
+// JSON otherwise.
+func defaultDecoder(ctx context.Context, c fiber.Ctx, contentType string, maxMemory int64, dst any) error {
+	if apix.IsFormContentType(contentType) {
+		return decodeForm(c, contentType, maxMemory, dst)
+	}
 	body := c.Body()
 	if len(body) == 0 {
 		return &httpError{status: http.StatusBadRequest, message: "request body required"}
 	}
 	decoder := json.NewDecoder(strings.NewReader(string(body)))
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(dst); err != nil {
+	if err := apix.DecodeJSON(decoder, dst, true); err != nil {
 		if errors.Is(err, io.EOF) {
 			return &httpError{status: http.StatusBadRequest, message: "request body required"}
 		}
@@ -190,6 +459,50 @@ func defaultDecoder(ctx context.Context, c fiber.Ctx, dst any) error {
 	return nil
 }
 
+// decodeForm binds a form body. Multipart file content beyond maxMemory bytes
+// (apix.DefaultMaxMultipartMemory when 0) is stored in temporary files, which the handlers
+// remove with removeForm once the request is served.
+func decodeForm(c fiber.Ctx, contentType string, maxMemory int64, dst any) error {
+	if maxMemory <= 0 {
+		maxMemory = apix.DefaultMaxMultipartMemory
+	}
+	body := c.Body()
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == apix.ContentTypeFormURLEncoded {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return &httpError{status: http.StatusBadRequest, message: "invalid form body: " + err.Error()}
+		}
+		return apix.BindForm(dst, values, nil)
+	}
+
+	boundary := string(c.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return &httpError{status: http.StatusBadRequest, message: "multipart boundary required"}
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(maxMemory)
+	if err != nil {
+		return &httpError{status: http.StatusBadRequest, message: "invalid form body: " + err.Error()}
+	}
+	c.Locals(multipartFormKey{}, form)
+	return apix.BindForm(dst, form.Value, form.File)
+}
+
+// multipartFormKey stores, in the request locals, the multipart form decodeForm read.
+type multipartFormKey struct{}
+
+// multipartForm returns the multipart form decodeForm read for c, or nil.
+func multipartForm(c fiber.Ctx) *multipart.Form {
+	form, _ := c.Locals(multipartFormKey{}).(*multipart.Form)
+	return form
+}
+
+// removeForm deletes the temporary files of form, as net/http does once a request is done.
+func removeForm(form *multipart.Form) {
+	if form != nil {
+		_ = form.RemoveAll()
+	}
+}
+
 func defaultEncoder(ctx context.Context, c fiber.Ctx, status int, payload any) error {
 	if status == http.StatusNoContent || payload == nil || isNoBody(reflect.TypeOf(payload)) {
 		return c.SendStatus(status)
@@ -197,7 +510,43 @@ func defaultEncoder(ctx context.Context, c fiber.Ctx, status int, payload any) e
 	return c.Status(status).JSON(payload)
 }
 
+// sendBinary streams a file or raw body. fasthttp closes readers implementing io.Closer
+// once the body is sent. Range requests are not supported.
+func sendBinary(c fiber.Ctx, status int, content apix.BinaryContent) error {
+	for key := range content.Header {
+		c.Set(key, content.Header.Get(key))
+	}
+	return c.Status(status).SendStream(content.Reader, int(content.Size))
+}
+
+// RequestValidator checks live requests against the API description, such as
+// *runtime.RequestValidator.
+type RequestValidator interface {
+	Validate(r *http.Request) error
+}
+
+// ValidateRequests returns middleware that rejects requests v reports as invalid with an
+// application/problem+json response.
+func ValidateRequests(v RequestValidator) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		r, err := adaptor.ConvertRequest(c, true)
+		if err == nil {
+			err = v.Validate(r)
+		}
+		if err != nil {
+			return defaultErrorHandler(c.Context(), c, err, true)
+		}
+		return c.Next()
+	}
+}
+
 func defaultErrorHandler(ctx context.Context, c fiber.Ctx, err error, useProblemDetails bool) error {
+	// Validation errors carry per-field details
+	var validationErr *apix.ValidationError
+	if errors.As(err, &validationErr) && !useProblemDetails {
+		return c.Status(validationErr.HTTPStatus()).JSON(validationErr.ErrorResponse())
+	}
+
 	// First check for StatusCoder interface (new pattern)
 	var statusCoder apix.StatusCoder
 	if errors.As(err, &statusCoder) {
@@ -257,3 +606,92 @@ func isNoBody(t reflect.Type) bool {
 	}
 	return t == noBodyType
 }
+
+// parameterSource adapts fiber.Ctx to apix.ParameterSource.
+type parameterSource struct {
+	c fiber.Ctx
+}
+
+func (s parameterSource) PathValue(name string) (string, bool) {
+	// Fiber reuses request buffers; copy so handlers may retain the value.
+	v := strings.Clone(s.c.Params(name))
+	return v, v != ""
+}
+
+func (s parameterSource) QueryValues(name string) []string {
+	return byteSlicesToStrings(s.c.Request().URI().QueryArgs().PeekMulti(name))
+}
+
+func (s parameterSource) HeaderValues(name string) []string {
+	return byteSlicesToStrings(s.c.Request().Header.PeekAll(name))
+}
+
+func (s parameterSource) CookieValue(name string) (string, bool) {
+	v := s.c.Request().Header.Cookie(name)
+	if v == nil {
+		return "", false
+	}
+	return string(v), true
+}
+
+// parameterSnapshot is a ParameterSource holding copies of a request's parameters, for use
+// after fiber may have reused the fiber.Ctx.
+type parameterSnapshot struct {
+	path    map[string]string
+	query   url.Values
+	header  http.Header
+	cookies map[string]string
+}
+
+func snapshotParameters(c fiber.Ctx) parameterSnapshot {
+	s := parameterSnapshot{
+		path:    make(map[string]string),
+		query:   url.Values{},
+		header:  http.Header{},
+		cookies: make(map[string]string),
+	}
+	for _, name := range c.Route().Params {
+		s.path[name] = strings.Clone(c.Params(name))
+	}
+	for key, value := range c.Request().URI().QueryArgs().All() {
+		s.query.Add(string(key), string(value))
+	}
+	for key, value := range c.Request().Header.All() {
+		s.header.Add(string(key), string(value))
+	}
+	for key, value := range c.Request().Header.Cookies() {
+		if _, ok := s.cookies[string(key)]; !ok {
+			s.cookies[string(key)] = string(value)
+		}
+	}
+	return s
+}
+
+func (s parameterSnapshot) PathValue(name string) (string, bool) {
+	v := s.path[name]
+	return v, v != ""
+}
+
+func (s parameterSnapshot) QueryValues(name string) []string {
+	return s.query[name]
+}
+
+func (s parameterSnapshot) HeaderValues(name string) []string {
+	return s.header.Values(name)
+}
+
+func (s parameterSnapshot) CookieValue(name string) (string, bool) {
+	v, ok := s.cookies[name]
+	return v, ok
+}
+
+func byteSlicesToStrings(values [][]byte) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
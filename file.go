@@ -0,0 +1,163 @@
+package apix
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ContentTypeOctetStream is the media type documented for binary responses without a
+// declared content type.
+const ContentTypeOctetStream = "application/octet-stream"
+
+// File is a response body streamed from Reader instead of being JSON-encoded. Return it
+// (or Response[File]) from a handler to serve downloads:
+//
+//	func exportUsers(ctx context.Context, _ *apix.NoBody) (apix.File, error) {
+//	    return apix.File{Name: "users.csv", ContentType: "text/csv", Reader: csvReader(ctx)}, nil
+//	}
+//
+// Adapters set Content-Type, Content-Disposition and Last-Modified from the fields. When
+// Reader is an io.ReadSeeker and the status is 200, net/http based adapters serve it with
+// http.ServeContent, which adds range and conditional request support. Readers that
+// implement io.Closer are closed once written.
+type File struct {
+	// Name is the filename sent in Content-Disposition. Empty omits the header.
+	Name string
+	// ContentType defaults to application/octet-stream.
+	ContentType string
+	Reader      io.Reader
+	// Size is the length of the content in bytes, or 0 when unknown.
+	Size    int64
+	ModTime time.Time
+	// Inline asks browsers to display the file instead of downloading it.
+	Inline bool
+}
+
+// RawBody is a response body written verbatim with the given content type, for payloads
+// that are already encoded or proxied from another service.
+type RawBody struct {
+	// ContentType defaults to application/octet-stream.
+	ContentType string
+	Reader      io.Reader
+}
+
+// Raw returns a RawBody writing data.
+func Raw(contentType string, data []byte) RawBody {
+	return RawBody{ContentType: contentType, Reader: bytes.NewReader(data)}
+}
+
+// WithResponseContentType sets the media type documented for the route's success
+// responses, such as "text/csv" or "application/pdf" for File downloads.
+func WithResponseContentType(contentType string) RouteOption {
+	return func(r *RouteRef) { r.SuccessContentType = contentType }
+}
+
+var (
+	fileType    = reflect.TypeOf(File{})
+	rawBodyType = reflect.TypeOf(RawBody{})
+)
+
+// IsBinaryType reports whether t, after dereferencing pointers, is File or RawBody.
+// Such bodies are documented as `type: string, format: binary`.
+func IsBinaryType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == fileType || t == rawBodyType
+}
+
+// BinaryContent is a File or RawBody prepared for writing.
+type BinaryContent struct {
+	// Header holds Content-Type plus, for files, Content-Disposition and Last-Modified.
+	Header http.Header
+	Reader io.Reader
+	// Size is the content length, or -1 when unknown.
+	Size    int64
+	Name    string
+	ModTime time.Time
+}
+
+// AsBinary prepares body for writing when it is a File or RawBody (or a pointer to one).
+func AsBinary(body any) (BinaryContent, bool) {
+	switch v := body.(type) {
+	case *File:
+		if v == nil {
+			return BinaryContent{}, false
+		}
+		return AsBinary(*v)
+	case *RawBody:
+		if v == nil {
+			return BinaryContent{}, false
+		}
+		return AsBinary(*v)
+	case File:
+		header := http.Header{}
+		header.Set("Content-Type", contentTypeOr(v.ContentType))
+		if v.Name != "" {
+			disposition := "attachment"
+			if v.Inline {
+				disposition = "inline"
+			}
+			header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": v.Name}))
+		}
+		if !v.ModTime.IsZero() {
+			header.Set("Last-Modified", v.ModTime.UTC().Format(http.TimeFormat))
+		}
+		size := v.Size
+		if size <= 0 {
+			size = -1
+		}
+		return BinaryContent{Header: header, Reader: readerOrEmpty(v.Reader), Size: size, Name: v.Name, ModTime: v.ModTime}, true
+	case RawBody:
+		header := http.Header{}
+		header.Set("Content-Type", contentTypeOr(v.ContentType))
+		return BinaryContent{Header: header, Reader: readerOrEmpty(v.Reader), Size: -1}, true
+	}
+	return BinaryContent{}, false
+}
+
+// Write sends the content with status. Seekable content answered with 200 goes through
+// http.ServeContent so Range, If-Range and If-Modified-Since requests are honoured.
+func (c BinaryContent) Write(w http.ResponseWriter, r *http.Request, status int) error {
+	defer c.Close()
+	for key, values := range c.Header {
+		w.Header()[key] = values
+	}
+	if seeker, ok := c.Reader.(io.ReadSeeker); ok && status == http.StatusOK && r != nil {
+		http.ServeContent(w, r, c.Name, c.ModTime, seeker)
+		return nil
+	}
+	if c.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(c.Size, 10))
+	}
+	w.WriteHeader(status)
+	_, err := io.Copy(w, c.Reader)
+	return err
+}
+
+// Close closes the reader when it implements io.Closer.
+func (c BinaryContent) Close() error {
+	if closer, ok := c.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func contentTypeOr(contentType string) string {
+	if contentType == "" {
+		return ContentTypeOctetStream
+	}
+	return contentType
+}
+
+func readerOrEmpty(r io.Reader) io.Reader {
+	if r == nil {
+		return bytes.NewReader(nil)
+	}
+	return r
+}
@@ -0,0 +1,80 @@
+package apix_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func TestAsBinaryDescribesFiles(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	content, ok := apix.AsBinary(&apix.File{Name: "report é.pdf", ContentType: "application/pdf", Reader: strings.NewReader("%PDF"), Size: 4, ModTime: modTime})
+	if !ok {
+		t.Fatalf("expected *File to be binary")
+	}
+	if got := content.Header.Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	if got := content.Header.Get("Content-Disposition"); got != "attachment; filename*=utf-8''report%20%C3%A9.pdf" {
+		t.Fatalf("unexpected disposition %q", got)
+	}
+	if got := content.Header.Get("Last-Modified"); got != "Fri, 02 Jan 2026 03:04:05 GMT" {
+		t.Fatalf("unexpected last-modified %q", got)
+	}
+
+	raw, ok := apix.AsBinary(apix.Raw("", []byte("blob")))
+	if !ok || raw.Header.Get("Content-Type") != apix.ContentTypeOctetStream || raw.Size != -1 {
+		t.Fatalf("unexpected raw body %+v", raw)
+	}
+	if _, ok := apix.AsBinary(sampleResp{}); ok {
+		t.Fatalf("expected JSON bodies not to be binary")
+	}
+}
+
+func TestBinaryContentServesRanges(t *testing.T) {
+	content, _ := apix.AsBinary(apix.File{Name: "data.txt", ContentType: "text/plain", Reader: strings.NewReader("0123456789")})
+
+	req := httptest.NewRequest(http.MethodGet, "/data.txt", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := httptest.NewRecorder()
+	if err := content.Write(rec, req, http.StatusOK); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Fatalf("expected partial content, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Disposition") != `attachment; filename=data.txt` {
+		t.Fatalf("unexpected disposition %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	// Readers that cannot seek are copied as they are, with the requested status.
+	content, _ = apix.AsBinary(apix.File{Reader: io.MultiReader(strings.NewReader("abc")), Size: 3})
+	rec = httptest.NewRecorder()
+	if err := content.Write(rec, req, http.StatusAccepted); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "abc" || rec.Header().Get("Content-Length") != "3" {
+		t.Fatalf("unexpected stream copy %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestEnsureSuccessResponsesDocumentsBinaryBodies(t *testing.T) {
+	ref := &apix.RouteRef{Method: apix.MethodGet, SuccessStatus: http.StatusOK}
+	apix.EnsureSuccessResponses(ref, reflect.TypeOf(apix.File{}))
+	if ct := ref.Responses[http.StatusOK].ContentType; ct != apix.ContentTypeOctetStream {
+		t.Fatalf("expected octet-stream default, got %q", ct)
+	}
+
+	ref = &apix.RouteRef{Method: apix.MethodGet, SuccessStatus: http.StatusOK}
+	apix.WithResponseContentType("text/csv")(ref)
+	apix.EnsureSuccessResponses(ref, reflect.TypeOf(apix.File{}))
+	if ct := ref.Responses[http.StatusOK].ContentType; ct != "text/csv" {
+		t.Fatalf("expected declared content type, got %q", ct)
+	}
+}
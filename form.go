@@ -0,0 +1,299 @@
+package apix
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Form media types accepted by DecodeForm.
+const (
+	ContentTypeMultipartForm  = "multipart/form-data"
+	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
+)
+
+// DefaultMaxMultipartMemory is the number of bytes of multipart file content kept in
+// memory when an adapter does not configure a limit. Larger files are stored in temporary
+// files, as with http.Request.ParseMultipartForm.
+const DefaultMaxMultipartMemory = 32 << 20
+
+// FileUpload is a file received in a multipart/form-data request. Declare request fields
+// as FileUpload, *FileUpload or []FileUpload to receive the files of a form field:
+//
+//	type UploadAvatarRequest struct {
+//	    UserID string     `path:"id"`
+//	    Avatar FileUpload `form:"avatar" format:"binary" validate:"required"`
+//	    Note   string     `form:"note"`
+//	}
+//
+// Fields of type []byte tagged `format:"binary"` receive the content of the first file
+// instead.
+type FileUpload struct {
+	Filename string
+	Size     int64
+	Header   textproto.MIMEHeader
+
+	open func() (multipart.File, error)
+}
+
+// NewFileUpload wraps a parsed multipart file header.
+func NewFileUpload(fh *multipart.FileHeader) FileUpload {
+	return FileUpload{Filename: fh.Filename, Size: fh.Size, Header: fh.Header, open: fh.Open}
+}
+
+// Open returns the file content. Callers must close it.
+func (f FileUpload) Open() (multipart.File, error) {
+	if f.open == nil {
+		return nil, errors.New("apix: file upload has no content")
+	}
+	return f.open()
+}
+
+// ContentType returns the media type the client sent for the file.
+func (f FileUpload) ContentType() string {
+	return f.Header.Get("Content-Type")
+}
+
+// FormField describes a request struct field bound from a form body.
+type FormField struct {
+	Name  string
+	Index []int
+	Type  reflect.Type
+	// File is set for FileUpload fields and `format:"binary"` byte slices.
+	File bool
+}
+
+var (
+	formFieldCache sync.Map // map[reflect.Type][]FormField
+	fileUploadType = reflect.TypeOf(FileUpload{})
+)
+
+// IsFormContentType reports whether contentType is multipart/form-data or
+// application/x-www-form-urlencoded.
+func IsFormContentType(contentType string) bool {
+	switch mediaType(contentType) {
+	case ContentTypeMultipartForm, ContentTypeFormURLEncoded:
+		return true
+	}
+	return false
+}
+
+// IsFileUploadType reports whether t is FileUpload or a pointer or slice of it.
+func IsFileUploadType(t reflect.Type) bool {
+	t = derefType(t)
+	if t != nil && t.Kind() == reflect.Slice {
+		t = derefType(t.Elem())
+	}
+	return t == fileUploadType
+}
+
+// FormFieldName returns the form field name of field: its form tag, else its JSON name,
+// else the Go field name. skip is set for fields tagged form:"-".
+func FormFieldName(field reflect.StructField) (name string, skip bool) {
+	if tag, ok := field.Tag.Lookup("form"); ok {
+		name = strings.Split(tag, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	if tag := field.Tag.Get("json"); tag != "" {
+		name = strings.Split(tag, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return field.Name, false
+}
+
+// FormFields returns the fields of t bound from a form body, in declaration order.
+// Parameter-tagged fields are excluded; fields of embedded structs are promoted.
+func FormFields(t reflect.Type) []FormField {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := formFieldCache.Load(t); ok {
+		return cached.([]FormField)
+	}
+	fields := collectFormFields(t, nil)
+	formFieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFormFields(t reflect.Type, index []int) []FormField {
+	var fields []FormField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		if _, _, ok := ParameterTag(field); ok {
+			continue
+		}
+		if _, tagged := field.Tag.Lookup("form"); field.Anonymous && !tagged {
+			if ft := derefType(field.Type); ft.Kind() == reflect.Struct && ft != fileUploadType {
+				fields = append(fields, collectFormFields(ft, fieldIndex)...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name, skip := FormFieldName(field)
+		if skip {
+			continue
+		}
+		fields = append(fields, FormField{
+			Name:  name,
+			Index: fieldIndex,
+			Type:  field.Type,
+			File:  IsFileUploadType(field.Type) || (isByteSlice(field.Type) && isBinaryFormat(field)),
+		})
+	}
+	return fields
+}
+
+// DecodeForm parses the form body of r, sent as contentType, and binds it into dst.
+// Multipart file content beyond maxMemory bytes (DefaultMaxMultipartMemory when 0) is
+// stored in temporary files, which net/http removes after the request.
+func DecodeForm(r *http.Request, contentType string, maxMemory int64, dst any) error {
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMultipartMemory
+	}
+	if mediaType(contentType) == ContentTypeMultipartForm {
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return formError(err)
+		}
+		return BindForm(dst, r.MultipartForm.Value, r.MultipartForm.File)
+	}
+	if err := r.ParseForm(); err != nil {
+		return formError(err)
+	}
+	return BindForm(dst, r.PostForm, nil)
+}
+
+// BindForm copies form values and files into the form fields of dst, which must be a
+// pointer to a struct. Conversion failures are reported as 400 Bad Request errors.
+func BindForm(dst any, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+	root := rv.Elem()
+	for _, ff := range FormFields(rv.Type()) {
+		if ff.File {
+			if len(files[ff.Name]) == 0 {
+				continue
+			}
+			if err := setFormFile(fieldByIndexAlloc(root, ff.Index), files[ff.Name]); err != nil {
+				return formFieldError(ff.Name, err.Error())
+			}
+			continue
+		}
+		if len(values[ff.Name]) == 0 {
+			continue
+		}
+		if err := setFormValue(fieldByIndexAlloc(root, ff.Index), values[ff.Name]); err != nil {
+			return formFieldError(ff.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// setFormValue binds repeated form values to slices and the first value to scalars.
+func setFormValue(v reflect.Value, values []string) error {
+	t := v.Type()
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(t, len(values), len(values))
+		for i, value := range values {
+			if err := setParameterValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setParameterValue(v, values[:1])
+}
+
+func setFormFile(v reflect.Value, headers []*multipart.FileHeader) error {
+	switch {
+	case v.Type() == fileUploadType:
+		v.Set(reflect.ValueOf(NewFileUpload(headers[0])))
+	case v.Kind() == reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setFormFile(elem.Elem(), headers); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case isByteSlice(v.Type()):
+		data, err := readFileHeader(headers[0])
+		if err != nil {
+			return err
+		}
+		v.SetBytes(data)
+	case v.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(headers), len(headers))
+		for i, fh := range headers {
+			if err := setFormFile(slice.Index(i), []*multipart.FileHeader{fh}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("has unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func readFileHeader(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func formError(err error) error {
+	if errors.Is(err, multipart.ErrMessageTooLarge) {
+		return &HTTPError{Status: http.StatusRequestEntityTooLarge, Message: "form body too large", Code: "INVALID_FORM", Err: err}
+	}
+	return &HTTPError{Status: http.StatusBadRequest, Message: "invalid form body: " + err.Error(), Code: "INVALID_FORM", Err: err}
+}
+
+func formFieldError(name, reason string) error {
+	return &HTTPError{
+		Status:  http.StatusBadRequest,
+		Message: fmt.Sprintf("form field %q %s", name, reason),
+		Code:    "INVALID_FORM",
+	}
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mt
+}
+
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func isBinaryFormat(field reflect.StructField) bool {
+	format := field.Tag.Get("format")
+	return format == "binary" || format == "file"
+}
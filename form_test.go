@@ -0,0 +1,104 @@
+package apix_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+type uploadForm struct {
+	DocID       string            `path:"id"`
+	Title       string            `form:"title"`
+	Tags        []string          `form:"tag"`
+	Count       int               `json:"count"`
+	Attachment  apix.FileUpload   `form:"attachment" format:"binary"`
+	Extras      []apix.FileUpload `form:"extra"`
+	Raw         []byte            `form:"raw" format:"binary"`
+	Ignored     string            `form:"-"`
+	Description string
+}
+
+func TestFormFieldsUseFormThenJSONNames(t *testing.T) {
+	var names []string
+	var files []string
+	for _, ff := range apix.FormFields(reflect.TypeOf(uploadForm{})) {
+		names = append(names, ff.Name)
+		if ff.File {
+			files = append(files, ff.Name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "title,tag,count,attachment,extra,raw,Description" {
+		t.Fatalf("unexpected form fields %s", got)
+	}
+	if got := strings.Join(files, ","); got != "attachment,extra,raw" {
+		t.Fatalf("unexpected file fields %s", got)
+	}
+}
+
+func TestDecodeFormBindsMultipartValuesAndFiles(t *testing.T) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("title", "Quarterly report")
+	_ = mw.WriteField("tag", "finance")
+	_ = mw.WriteField("tag", "q3")
+	_ = mw.WriteField("count", "3")
+	for name, content := range map[string]string{"attachment": "%PDF-1.7", "raw": "raw bytes"} {
+		part, _ := mw.CreateFormFile(name, name+".bin")
+		_, _ = part.Write([]byte(content))
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		part, _ := mw.CreateFormFile("extra", name)
+		_, _ = part.Write([]byte(name))
+	}
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/docs", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var dst uploadForm
+	if err := apix.DecodeForm(req, apix.ContentTypeMultipartForm, 0, &dst); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if dst.Title != "Quarterly report" || strings.Join(dst.Tags, ",") != "finance,q3" || dst.Count != 3 {
+		t.Fatalf("unexpected values %+v", dst)
+	}
+	if dst.Attachment.Filename != "attachment.bin" || dst.Attachment.Size != 8 || dst.Attachment.ContentType() != "application/octet-stream" {
+		t.Fatalf("unexpected attachment %+v", dst.Attachment)
+	}
+	f, err := dst.Attachment.Open()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	content, _ := io.ReadAll(f)
+	_ = f.Close()
+	if string(content) != "%PDF-1.7" {
+		t.Fatalf("unexpected attachment content %q", content)
+	}
+	if len(dst.Extras) != 2 || dst.Extras[1].Filename != "b.txt" || string(dst.Raw) != "raw bytes" {
+		t.Fatalf("unexpected files %+v %q", dst.Extras, dst.Raw)
+	}
+}
+
+func TestDecodeFormReportsInvalidValues(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/docs", strings.NewReader(url.Values{"count": {"three"}}.Encode()))
+	req.Header.Set("Content-Type", apix.ContentTypeFormURLEncoded)
+
+	var dst uploadForm
+	err := apix.DecodeForm(req, apix.ContentTypeFormURLEncoded, 0, &dst)
+	var httpErr *apix.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusBadRequest || !strings.Contains(httpErr.Message, `"count"`) {
+		t.Fatalf("expected 400 naming the field, got %v", err)
+	}
+	if _, err := (apix.FileUpload{}).Open(); err == nil {
+		t.Fatalf("expected empty upload to fail to open")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"reflect"
 
 	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/internal/capture"
+	"github.com/Infra-Forge/infra-apix/openapi"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,21 +29,44 @@ type Options struct {
 	Decoder         RequestDecoder
 	ResponseEncoder ResponseEncoder
 	ErrorHandler    ErrorHandler
+	// Validator checks decoded requests after parameters are bound. Defaults to
+	// apix.DefaultValidator(), which enforces validate/binding struct tags. Validation also
+	// runs when a custom Decoder is set; use apix.NoValidation() to turn it off.
+	Validator apix.Validator
+	// MaxMultipartMemory is the number of bytes of multipart/form-data file content kept in
+	// memory; the rest is stored in temporary files. Defaults to apix.DefaultMaxMultipartMemory.
+	MaxMultipartMemory int64
 	// UseProblemDetails enables RFC 9457 Problem Details encoding for errors.
 	// When enabled, errors implementing StatusCoder will be serialized as
 	// application/problem+json instead of plain text.
 	UseProblemDetails bool
+	// Registry receives route metadata for this adapter. Defaults to apix.DefaultRegistry().
+	// Use a dedicated registry per API surface to generate separate OpenAPI documents.
+	Registry *apix.Registry
+	// ResponseValidation checks every response against the generated document. Responses
+	// are buffered while it is set, so enable it in development and tests only. Streaming
+	// routes are not validated.
+	ResponseValidation *openapi.ResponseValidator
+	// Interceptors run around every handler of the adapter, before the interceptors of
+	// groups and routes.
+	Interceptors []apix.Interceptor
+	// AutoHead also answers HEAD requests for every GET route registered with Register
+	// or Get, using the GET handler's status and headers without the body, and documents
+	// the HEAD operations.
+	AutoHead bool
 }
 
 // GinAdapter integrates apix route registration with gin.Engine.
 type GinAdapter struct {
-	e    *gin.Engine
-	opts Options
+	e      *gin.Engine
+	routes gin.IRouter
+	opts   Options
+	group  apix.Group
 }
 
 // New constructs a GinAdapter with optional overrides.
 func New(e *gin.Engine, opts ...Options) *GinAdapter {
-	adapter := &GinAdapter{e: e}
+	adapter := &GinAdapter{e: e, routes: e}
 	if len(opts) > 0 {
 		adapter.opts = opts[0]
 	}
@@ -51,49 +76,91 @@ func New(e *gin.Engine, opts ...Options) *GinAdapter {
 // Engine exposes the underlying gin.Engine instance.
 func (a *GinAdapter) Engine() *gin.Engine { return a.e }
 
+// Group returns an adapter registering routes below prefix on a gin.RouterGroup. See
+// apix.Group for how opts and middleware apply.
+func (a *GinAdapter) Group(prefix string, opts ...apix.RouteOption) *GinAdapter {
+	return &GinAdapter{e: a.e, routes: a.routes.Group(prefix), opts: a.opts, group: a.group.Group(prefix, opts...)}
+}
+
+// Use appends middleware to the adapter's engine or router group. See apix.Group for the
+// security it documents.
+func (a *GinAdapter) Use(middleware ...gin.HandlerFunc) {
+	detect := make([]any, len(middleware))
+	for i, mw := range middleware {
+		detect[i] = mw
+	}
+	a.group = a.group.With(apix.MiddlewareSecurity(detect...))
+	a.routes.Use(middleware...)
+}
+
+// UseSecured appends middleware marked with apix.SecuredBy, adding its security
+// requirement to the routes registered afterwards.
+func (a *GinAdapter) UseSecured(middleware ...apix.SecuredMiddleware[gin.HandlerFunc]) {
+	for _, mw := range middleware {
+		a.group = a.group.With(apix.MiddlewareSecurity(mw))
+		a.routes.Use(mw.Middleware)
+	}
+}
+
+// Registry returns the route registry this adapter writes to.
+func (a *GinAdapter) Registry() *apix.Registry {
+	if a.opts.Registry != nil {
+		return a.opts.Registry
+	}
+	return apix.DefaultRegistry()
+}
+
 // Register adds a handler for the provided method and path.
 func Register[TReq any, TResp any](a *GinAdapter, method apix.RouteMethod, path string, handler apix.HandlerFunc[TReq, TResp], opts ...apix.RouteOption) {
 	if a == nil || a.e == nil {
 		panic("apix/gin: adapter not initialised")
 	}
 
-	ref := &apix.RouteRef{
-		Method:         method,
-		Path:           path,
-		Responses:      make(map[int]*apix.ResponseRef),
-		SuccessHeaders: make(map[int][]apix.HeaderRef),
-		HandlerType:    reflect.TypeOf(handler),
-	}
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
 
-	reqType := typeOf[TReq]()
-	if reqType != nil && !isNoBody(reqType) {
-		ref.RequestType = reqType
-		if ref.RequestContentType == "" {
-			ref.RequestContentType = "application/json"
-		}
+	respType := apix.ResponseBodyType(typeOf[TResp]())
+	if respType == nil {
+		respType = reflect.TypeOf(struct{}{})
 	}
+	apix.EnsureSuccessResponses(ref, respType)
 
-	for _, opt := range opts {
-		opt(ref)
+	if ref.OperationID == "" {
+		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	if ref.SuccessStatus == 0 {
-		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildGinHandler(a, handler, ref))
+
+	if method == apix.MethodGet && a.opts.AutoHead {
+		head := apix.HeadRoute(ref)
+		a.Registry().Register(head)
+		a.handle(head, path, buildGinHandler(a, handler, head))
 	}
+}
 
-	respType := typeOf[TResp]()
-	if respType == nil {
-		respType = reflect.TypeOf(struct{}{})
+// Stream adds a server-sent events handler for the provided method and path.
+func Stream[TReq any, TEvent any](a *GinAdapter, method apix.RouteMethod, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	if a == nil || a.e == nil {
+		panic("apix/gin: adapter not initialised")
 	}
-	apix.EnsureResponse(ref, ref.SuccessStatus, respType)
+
+	ref := newRouteRef[TReq](a.group, method, path, handler, opts)
+	apix.EnsureEventStreamResponse(ref, typeOf[TEvent]())
 
 	if ref.OperationID == "" {
 		ref.OperationID = apix.DefaultOperationID(ref.Method, ref.Path)
 	}
 
-	apix.RegisterRoute(ref)
+	a.Registry().Register(ref)
+
+	a.handle(ref, path, buildGinStreamHandler(a, handler, ref))
+}
 
-	a.e.Handle(string(method), path, buildGinHandler(a, handler, ref))
+// SSE registers a GET handler streaming server-sent events. TReq carries the route's
+// path/query/header parameters.
+func SSE[TReq any, TEvent any](a *GinAdapter, path string, handler apix.StreamHandlerFunc[TReq, TEvent], opts ...apix.RouteOption) {
+	Stream[TReq, TEvent](a, apix.MethodGet, path, handler, opts...)
 }
 
 // Get registers a GET handler.
@@ -121,40 +188,206 @@ func Delete[TResp any](a *GinAdapter, path string, handler apix.HandlerFunc[apix
 	Register[apix.NoBody, TResp](a, apix.MethodDelete, path, handler, opts...)
 }
 
+// Head registers a HEAD handler. Its responses are documented without bodies.
+func Head[TResp any](a *GinAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodHead, path, handler, opts...)
+}
+
+// OptionsRoute registers an OPTIONS handler. It is not called Options, the name of the
+// adapter configuration type.
+func OptionsRoute[TResp any](a *GinAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodOptions, path, handler, opts...)
+}
+
+// Trace registers a TRACE handler.
+func Trace[TResp any](a *GinAdapter, path string, handler apix.HandlerFunc[apix.NoBody, TResp], opts ...apix.RouteOption) {
+	Register[apix.NoBody, TResp](a, apix.MethodTrace, path, handler, opts...)
+}
+
+func (a *GinAdapter) handle(ref *apix.RouteRef, path string, h gin.HandlerFunc) {
+	a.routes.Handle(string(ref.Method), path, append(routeMiddleware(ref), h)...)
+}
+
+// routeMiddleware returns the middleware attached to ref with apix.WithMiddleware.
+func routeMiddleware(ref *apix.RouteRef) []gin.HandlerFunc {
+	out := make([]gin.HandlerFunc, 0, len(ref.Middleware)+1)
+	for _, mw := range ref.Middleware {
+		switch fn := apix.UnwrapMiddleware(mw).(type) {
+		case gin.HandlerFunc:
+			out = append(out, fn)
+		case func(*gin.Context):
+			out = append(out, fn)
+		default:
+			panic(fmt.Sprintf("apix/gin: middleware %T of %s %s is not a gin.HandlerFunc", mw, ref.Method, ref.Path))
+		}
+	}
+	return out
+}
+
 func buildGinHandler[TReq any, TResp any](a *GinAdapter, handler apix.HandlerFunc[TReq, TResp], ref *apix.RouteRef) gin.HandlerFunc {
-	return func(c *gin.Context) {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	serve := func(c *gin.Context) {
 		ctx := c.Request.Context()
 
-		var reqPtr *TReq
-		if ref.RequestType != nil {
-			reqVal := new(TReq)
-			if err := a.decode(ctx, c, reqVal); err != nil {
-				a.handleError(ctx, c, err)
-				return
-			}
-			reqPtr = reqVal
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		if err != nil {
+			capture.Reject(c.Writer)
+			a.handleError(ctx, c, err)
+			return
 		}
 
-		resp, err := handler(ctx, reqPtr)
+		resp, err := apix.InterceptHandler(ctx, call, interceptors, reqPtr, handler)
 		if err != nil {
 			a.handleError(ctx, c, err)
 			return
 		}
 
-		if err := a.encode(ctx, c, ref.SuccessStatus, resp, ref); err != nil {
+		status, header, body, err := apix.ResolveResponse(resp, ref.SuccessStatus)
+		if err != nil {
 			a.handleError(ctx, c, err)
+			return
 		}
+		addHeaders(c.Writer.Header(), header)
+		if err := a.encode(ctx, c, status, body, ref); err != nil {
+			a.handleError(ctx, c, err)
+		}
+	}
+
+	if a.opts.ResponseValidation == nil {
+		return serve
+	}
+
+	return func(c *gin.Context) {
+		w := c.Writer
+		rec := &captureWriter{ResponseWriter: w, rec: capture.NewRecorder()}
+		c.Writer = rec
+		serve(c)
+		c.Writer = w
+
+		validate := a.opts.ResponseValidation.ValidateResponse
+		if rec.rec.Rejected() {
+			validate = a.opts.ResponseValidation.ValidateRejection
+		}
+		if err := validate(c.Request.Context(), a.Registry(), ref, rec.rec.Status(), rec.rec.Header(), rec.rec.Body()); err != nil {
+			a.handleError(c.Request.Context(), c, err)
+			return
+		}
+		rec.rec.FlushTo(w)
 	}
 }
 
-func (a *GinAdapter) decode(ctx context.Context, c *gin.Context, dst any) error {
+func buildGinStreamHandler[TReq any, TEvent any](a *GinAdapter, handler apix.StreamHandlerFunc[TReq, TEvent], ref *apix.RouteRef) gin.HandlerFunc {
+	hasBody := apix.HasBodyFields(ref.RequestType)
+	interceptors := a.interceptors(ref)
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		call := newCall(ref, c)
+		ctx, reqPtr, err := decodeRequest[TReq](a, ctx, c, ref, hasBody, call, interceptors)
+		if err != nil {
+			a.handleError(ctx, c, err)
+			return
+		}
+
+		w := c.Writer
+		events := apix.NewEventSender[TEvent](ctx, w, func() {
+			apix.SetEventStreamHeaders(w.Header())
+			w.WriteHeader(ref.SuccessStatus)
+			w.WriteHeaderNow()
+		}, http.NewResponseController(w).Flush)
+		err = handler(ctx, reqPtr, events)
+		if err != nil {
+			err = apix.InterceptError(ctx, call, interceptors, err)
+		}
+		if err != nil && !events.Opened() {
+			a.handleError(ctx, c, err)
+			return
+		}
+		_ = events.Close(err)
+	}
+}
+
+// newRouteRef captures the route metadata shared by typed and streaming handlers. path is
+// relative to the adapter's group, whose options apply before opts.
+func newRouteRef[TReq any](group apix.Group, method apix.RouteMethod, path string, handler any, opts []apix.RouteOption) *apix.RouteRef {
+	ref := &apix.RouteRef{
+		Method:         method,
+		Path:           group.Path(path),
+		Responses:      make(map[int]*apix.ResponseRef),
+		SuccessHeaders: make(map[int][]apix.HeaderRef),
+		HandlerType:    reflect.TypeOf(handler),
+	}
+
+	reqType := typeOf[TReq]()
+	if reqType != nil && !isNoBody(reqType) {
+		ref.RequestType = reqType
+		if ref.RequestContentType == "" && apix.HasBodyFields(reqType) {
+			ref.RequestContentType = "application/json"
+		}
+	}
+
+	group.Apply(ref, opts)
+
+	if ref.SuccessStatus == 0 {
+		ref.SuccessStatus = apix.DefaultSuccessStatus(method)
+	}
+	return ref
+}
+
+// decodeRequest decodes the body, binds parameters and validates the result, running the
+// interceptors' decode hooks around it. The request is nil for routes without a request type.
+func decodeRequest[TReq any](a *GinAdapter, ctx context.Context, c *gin.Context, ref *apix.RouteRef, hasBody bool, call *apix.Call, interceptors []apix.Interceptor) (context.Context, *TReq, error) {
+	return apix.InterceptDecode(ctx, call, interceptors, func(ctx context.Context) (*TReq, error) {
+		if ref.RequestType == nil {
+			return nil, nil
+		}
+		reqVal := new(TReq)
+		if hasBody {
+			if err := a.decode(ctx, c, ref.RequestContentType, reqVal); err != nil {
+				return nil, err
+			}
+		}
+		if err := apix.BindParameters(reqVal, call.Params); err != nil {
+			return nil, err
+		}
+		if err := apix.Validate(a.opts.Validator, reqVal); err != nil {
+			return nil, err
+		}
+		return reqVal, nil
+	})
+}
+
+// interceptors returns the route's interceptor pipeline, led by the enforcement of its
+// security requirements.
+func (a *GinAdapter) interceptors(ref *apix.RouteRef) []apix.Interceptor {
+	interceptors := apix.RouteInterceptors(a.opts.Interceptors, ref)
+	if security, ok := apix.SecurityInterceptor(a.Registry(), ref); ok {
+		interceptors = append([]apix.Interceptor{security}, interceptors...)
+	}
+	return interceptors
+}
+
+// newCall describes the request to interceptors.
+func newCall(ref *apix.RouteRef, c *gin.Context) *apix.Call {
+	return &apix.Call{Route: ref, Params: apix.NewRequestSource(c.Request, c.Param), TLS: c.Request.TLS}
+}
+
+func (a *GinAdapter) decode(ctx context.Context, c *gin.Context, contentType string, dst any) error {
 	if dec := a.opts.Decoder; dec != nil {
 		return dec(ctx, c, dst)
 	}
-	return defaultDecoder(ctx, c, dst)
+	return defaultDecoder(ctx, c, contentType, a.opts.MaxMultipartMemory, dst)
 }
 
 func (a *GinAdapter) encode(ctx context.Context, c *gin.Context, status int, payload any, ref *apix.RouteRef) error {
+	// Files and raw bodies bypass the encoder: they are written as they are.
+	if content, ok := apix.AsBinary(payload); ok {
+		return content.Write(c.Writer, c.Request, status)
+	}
 	if enc := a.opts.ResponseEncoder; enc != nil {
 		return enc(ctx, c, status, payload, ref)
 	}
@@ -172,13 +405,17 @@ func (a *GinAdapter) handleError(ctx context.Context, c *gin.Context, err error)
 	defaultErrorHandler(ctx, c, err, a.opts.UseProblemDetails)
 }
 
-func defaultDecoder(ctx context.Context, c *gin.Context, dst any) error {
+// defaultDecoder binds form bodies for routes declaring a form content type and decodes
+// JSON otherwise.
+func defaultDecoder(ctx context.Context, c *gin.Context, contentType string, maxMemory int64, dst any) error {
+	if apix.IsFormContentType(contentType) {
+		return apix.DecodeForm(c.Request, contentType, maxMemory, dst)
+	}
 	if c.Request.Body == nil {
 		return &httpError{status: http.StatusBadRequest, message: "request body required"}
 	}
 	decoder := json.NewDecoder(c.Request.Body)
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(dst); err != nil {
+	if err := apix.DecodeJSON(decoder, dst, true); err != nil {
 		if errors.Is(err, io.EOF) {
 			return &httpError{status: http.StatusBadRequest, message: "request body required"}
 		}
@@ -187,8 +424,6 @@ func defaultDecoder(ctx context.Context, c *gin.Context, dst any) error {
 	if decoder.More() {
 		return &httpError{status: http.StatusBadRequest, message: "unexpected additional JSON content"}
 	}
-	// Gin has built-in validation via ShouldBind, but we use manual JSON decoding for consistency
-	// Users can provide custom decoder if they want Gin's validation
 	return nil
 }
 
@@ -201,7 +436,33 @@ func defaultEncoder(ctx context.Context, c *gin.Context, status int, payload any
 	return nil
 }
 
+// RequestValidator checks live requests against the API description, such as
+// *runtime.RequestValidator.
+type RequestValidator interface {
+	Validate(r *http.Request) error
+}
+
+// ValidateRequests returns middleware that rejects requests v reports as invalid with an
+// application/problem+json response.
+func ValidateRequests(v RequestValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := v.Validate(c.Request); err != nil {
+			defaultErrorHandler(c.Request.Context(), c, err, true)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func defaultErrorHandler(ctx context.Context, c *gin.Context, err error, useProblemDetails bool) {
+	// Validation errors carry per-field details
+	var validationErr *apix.ValidationError
+	if errors.As(err, &validationErr) && !useProblemDetails {
+		c.JSON(validationErr.HTTPStatus(), validationErr.ErrorResponse())
+		return
+	}
+
 	// First check for StatusCoder interface (new pattern)
 	var statusCoder apix.StatusCoder
 	if errors.As(err, &statusCoder) {
@@ -247,6 +508,15 @@ func typeOf[T any]() reflect.Type {
 	return t
 }
 
+// addHeaders copies headers set by an apix.Response onto the outgoing response.
+func addHeaders(dst, src http.Header) {
+	for key, values := range src {
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
+
 var noBodyType = reflect.TypeOf(apix.NoBody{})
 
 func isNoBody(t reflect.Type) bool {
@@ -258,3 +528,25 @@ func isNoBody(t reflect.Type) bool {
 	}
 	return t == noBodyType
 }
+
+// captureWriter buffers a gin response while response validation runs.
+type captureWriter struct {
+	gin.ResponseWriter
+	rec *capture.Recorder
+}
+
+func (w *captureWriter) Header() http.Header               { return w.rec.Header() }
+func (w *captureWriter) WriteHeader(status int)            { w.rec.WriteHeader(status) }
+func (w *captureWriter) WriteHeaderNow()                   {}
+func (w *captureWriter) Write(p []byte) (int, error)       { return w.rec.Write(p) }
+func (w *captureWriter) WriteString(s string) (int, error) { return w.rec.WriteString(s) }
+func (w *captureWriter) Status() int                       { return w.rec.Status() }
+func (w *captureWriter) Written() bool                     { return w.rec.Written() }
+func (w *captureWriter) Reject()                           { w.rec.Reject() }
+
+func (w *captureWriter) Size() int {
+	if !w.rec.Written() {
+		return -1
+	}
+	return len(w.rec.Body())
+}
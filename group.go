@@ -0,0 +1,102 @@
+package apix
+
+import "strings"
+
+// Group is the path prefix and default route options shared by the routes registered
+// through an adapter group. The zero value is the root group: no prefix and no options.
+//
+// Adapters create groups with their Group method:
+//
+//	docs := adapter.Group("/documents", apix.WithTags("Documents"), apix.WithSecurity("BearerAuth"), apix.WithStandardErrors())
+//	chiadapter.Get(docs, "/{id}", getDocument)      // GET /documents/{id}
+//	chiadapter.Post(docs, "/", createDocument)      // POST /documents/
+//
+// A group's options apply to each of its routes before the route's own options, and groups
+// nest. Middleware added with an adapter's Use runs for the routes of that adapter or group
+// only; the security requirements that SecuredBy or registered SecurityDetectors report for
+// it are added to the routes registered afterwards.
+type Group struct {
+	prefix string
+	levels [][]RouteOption
+}
+
+// NewGroup returns a group of routes below prefix.
+func NewGroup(prefix string, opts ...RouteOption) Group {
+	return Group{}.Group(prefix, opts...)
+}
+
+// Group returns a group nested in g. Prefixes are joined and opts apply after the options
+// of g.
+func (g Group) Group(prefix string, opts ...RouteOption) Group {
+	levels := make([][]RouteOption, len(g.levels), len(g.levels)+1)
+	copy(levels, g.levels)
+	return Group{prefix: joinPath(g.prefix, prefix), levels: append(levels, opts)}
+}
+
+// With returns a copy of g whose own options are followed by opts, for options that become
+// known after the group was created, such as the security of middleware added with Use.
+func (g Group) With(opts ...RouteOption) Group {
+	if len(g.levels) == 0 {
+		return Group{prefix: g.prefix, levels: [][]RouteOption{opts}}
+	}
+	levels := make([][]RouteOption, len(g.levels))
+	copy(levels, g.levels)
+	last := levels[len(levels)-1]
+	levels[len(levels)-1] = append(last[:len(last):len(last)], opts...)
+	return Group{prefix: g.prefix, levels: levels}
+}
+
+// Prefix returns the full path prefix of the group.
+func (g Group) Prefix() string { return g.prefix }
+
+// Path returns path below the group's prefix.
+func (g Group) Path(path string) string { return joinPath(g.prefix, path) }
+
+// Apply applies the group's options, outermost group first, followed by the route's own
+// opts, so route options override scalar settings such as the summary. Tags accumulate
+// without duplicates. Security requirements declared at one level replace those inherited
+// from enclosing groups, as operation security replaces global security in OpenAPI;
+// requirements of middleware accumulate, as every level's middleware runs.
+func (g Group) Apply(ref *RouteRef, opts []RouteOption) {
+	for _, level := range g.levels {
+		applyLevel(ref, level)
+	}
+	applyLevel(ref, opts)
+	ref.Tags = uniqueStrings(ref.Tags)
+}
+
+func applyLevel(ref *RouteRef, opts []RouteOption) {
+	inherited := ref.Security
+	ref.Security = nil
+	for _, opt := range opts {
+		opt(ref)
+	}
+	if len(ref.Security) == 0 {
+		ref.Security = inherited
+	}
+}
+
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func uniqueStrings(values []string) []string {
+	if len(values) < 2 {
+		return values
+	}
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
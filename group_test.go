@@ -0,0 +1,40 @@
+package apix_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func TestGroupJoinsPrefixesAndMergesOptions(t *testing.T) {
+	api := apix.NewGroup("/api/", apix.WithTags("API"), apix.WithSecurity("BearerAuth"), apix.WithStandardErrors())
+	docs := api.Group("/documents", apix.WithTags("Documents"), apix.WithSummary("Documents"))
+
+	if docs.Prefix() != "/api/documents" || docs.Path("/{id}") != "/api/documents/{id}" || docs.Path("") != "/api/documents" {
+		t.Fatalf("unexpected group paths %q %q", docs.Prefix(), docs.Path("/{id}"))
+	}
+
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: docs.Path("/{id}"), Responses: map[int]*apix.ResponseRef{}}
+	docs.Apply(ref, []apix.RouteOption{apix.WithTags("Documents"), apix.WithSummary("Get document")})
+
+	if !reflect.DeepEqual(ref.Tags, []string{"API", "Documents"}) {
+		t.Fatalf("expected de-duplicated tags, got %v", ref.Tags)
+	}
+	if ref.Summary != "Get document" {
+		t.Fatalf("expected route summary to win, got %q", ref.Summary)
+	}
+	if len(ref.Security) != 1 || ref.Security[0].Name != "BearerAuth" {
+		t.Fatalf("expected inherited security, got %v", ref.Security)
+	}
+	if ref.Responses[http.StatusBadRequest] == nil || ref.Responses[http.StatusInternalServerError] == nil {
+		t.Fatalf("expected group standard errors, got %v", ref.Responses)
+	}
+
+	admin := &apix.RouteRef{Method: apix.MethodDelete, Path: docs.Path("/{id}"), Responses: map[int]*apix.ResponseRef{}}
+	docs.Apply(admin, []apix.RouteOption{apix.WithSecurity("BearerAuth", "documents:delete")})
+	if len(admin.Security) != 1 || !reflect.DeepEqual(admin.Security[0].Scopes, []string{"documents:delete"}) {
+		t.Fatalf("expected route security to replace the group's, got %v", admin.Security)
+	}
+}
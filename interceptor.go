@@ -0,0 +1,129 @@
+package apix
+
+import (
+	"context"
+	"crypto/tls"
+)
+
+// Interceptor hooks into the typed request pipeline shared by every adapter, for concerns
+// that need the decoded request or the handler's response: audit logging, authorization on
+// body fields, response redaction. Every hook is optional.
+//
+// Interceptors are attached globally through the adapter's Options.Interceptors, to a group
+// or a route with WithInterceptors. They run in that order, outermost first; AfterHandler
+// and OnError hooks run in reverse order.
+type Interceptor struct {
+	// BeforeDecode runs before the request is decoded. The returned context is passed on to
+	// decoding, the remaining hooks and the handler. An error aborts the request.
+	BeforeDecode func(ctx context.Context, call *Call) (context.Context, error)
+	// AfterDecode runs once call.Request holds the decoded and validated request. An error
+	// aborts the request before the handler runs.
+	AfterDecode func(ctx context.Context, call *Call) (context.Context, error)
+	// AfterHandler runs after the handler succeeded. It may replace call.Response, which is
+	// then sent like a handler result, or fail the request with an error. It does not run
+	// for streaming routes, whose events are sent while the handler runs.
+	AfterHandler func(ctx context.Context, call *Call) error
+	// OnError runs when decoding, an interceptor or the handler fails. It returns the error
+	// to report, which may be a replacement such as an HTTPError; nil keeps err.
+	OnError func(ctx context.Context, call *Call, err error) error
+}
+
+// Call describes the request an interceptor runs for.
+type Call struct {
+	Route *RouteRef
+	// Params exposes the raw path, query, header and cookie values of the request.
+	Params ParameterSource
+	// TLS is the connection state of requests served over TLS, nil otherwise.
+	TLS *tls.ConnectionState
+	// Request is the decoded *TReq once decoding succeeded; nil for routes without a
+	// request type.
+	Request any
+	// Response is the handler's TResp, set before AfterHandler hooks run.
+	Response any
+}
+
+// WithInterceptors attaches interceptors to the route, after those of enclosing groups.
+func WithInterceptors(interceptors ...Interceptor) RouteOption {
+	return func(r *RouteRef) {
+		r.Interceptors = append(r.Interceptors, interceptors...)
+	}
+}
+
+// RouteInterceptors returns global followed by the interceptors attached to ref.
+func RouteInterceptors(global []Interceptor, ref *RouteRef) []Interceptor {
+	out := make([]Interceptor, 0, len(global)+len(ref.Interceptors))
+	out = append(out, global...)
+	return append(out, ref.Interceptors...)
+}
+
+// InterceptDecode runs the BeforeDecode hooks, decode and the AfterDecode hooks. Errors are
+// passed through the OnError hooks before being returned.
+func InterceptDecode[TReq any](ctx context.Context, call *Call, interceptors []Interceptor, decode func(ctx context.Context) (*TReq, error)) (context.Context, *TReq, error) {
+	var err error
+	for _, in := range interceptors {
+		if in.BeforeDecode == nil {
+			continue
+		}
+		if ctx, err = callBefore(ctx, call, in.BeforeDecode); err != nil {
+			return ctx, nil, InterceptError(ctx, call, interceptors, err)
+		}
+	}
+
+	req, err := decode(ctx)
+	if err != nil {
+		return ctx, nil, InterceptError(ctx, call, interceptors, err)
+	}
+	if req != nil {
+		call.Request = req
+	}
+
+	for _, in := range interceptors {
+		if in.AfterDecode == nil {
+			continue
+		}
+		if ctx, err = callBefore(ctx, call, in.AfterDecode); err != nil {
+			return ctx, nil, InterceptError(ctx, call, interceptors, err)
+		}
+	}
+	return ctx, req, nil
+}
+
+// InterceptHandler calls handler and runs the AfterHandler hooks on its response. It returns
+// the response to send; errors are passed through the OnError hooks. Streaming handlers are
+// called directly instead, with errors passed to InterceptError, so AfterHandler hooks
+// never see their events.
+func InterceptHandler[TReq any, TResp any](ctx context.Context, call *Call, interceptors []Interceptor, req *TReq, handler HandlerFunc[TReq, TResp]) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, InterceptError(ctx, call, interceptors, err)
+	}
+	call.Response = resp
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].AfterHandler; hook != nil {
+			if err := hook(ctx, call); err != nil {
+				return nil, InterceptError(ctx, call, interceptors, err)
+			}
+		}
+	}
+	return call.Response, nil
+}
+
+// InterceptError passes err through the OnError hooks, innermost first.
+func InterceptError(ctx context.Context, call *Call, interceptors []Interceptor, err error) error {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].OnError; hook != nil {
+			if replaced := hook(ctx, call, err); replaced != nil {
+				err = replaced
+			}
+		}
+	}
+	return err
+}
+
+func callBefore(ctx context.Context, call *Call, hook func(context.Context, *Call) (context.Context, error)) (context.Context, error) {
+	next, err := hook(ctx, call)
+	if next == nil {
+		next = ctx
+	}
+	return next, err
+}
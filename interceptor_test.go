@@ -0,0 +1,72 @@
+package apix_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+type interceptorKey struct{}
+
+func TestInterceptorsWrapDecodeAndHandler(t *testing.T) {
+	ref := &apix.RouteRef{Method: apix.MethodPost, Path: "/samples"}
+	apix.WithInterceptors(apix.Interceptor{
+		BeforeDecode: func(ctx context.Context, call *apix.Call) (context.Context, error) {
+			return context.WithValue(ctx, interceptorKey{}, "audit-1"), nil
+		},
+		AfterHandler: func(ctx context.Context, call *apix.Call) error {
+			call.Response = sampleResp{ID: "redacted"}
+			return nil
+		},
+	})(ref)
+	interceptors := apix.RouteInterceptors(nil, ref)
+	call := &apix.Call{Route: ref}
+
+	ctx, req, err := apix.InterceptDecode(context.Background(), call, interceptors, func(ctx context.Context) (*sampleReq, error) {
+		return &sampleReq{Name: "ada"}, nil
+	})
+	if err != nil || call.Request != req || ctx.Value(interceptorKey{}) != "audit-1" {
+		t.Fatalf("unexpected decode result %v %v", call.Request, err)
+	}
+
+	resp, err := apix.InterceptHandler(ctx, call, interceptors, req, func(ctx context.Context, req *sampleReq) (sampleResp, error) {
+		if ctx.Value(interceptorKey{}) != "audit-1" {
+			t.Fatalf("expected handler to receive the interceptor context")
+		}
+		return sampleResp{ID: req.Name}, nil
+	})
+	if err != nil || resp != (sampleResp{ID: "redacted"}) {
+		t.Fatalf("expected replaced response, got %v %v", resp, err)
+	}
+}
+
+func TestInterceptorErrorsPassThroughOnError(t *testing.T) {
+	var order []string
+	onError := func(name string, replace error) apix.Interceptor {
+		return apix.Interceptor{OnError: func(ctx context.Context, call *apix.Call, err error) error {
+			order = append(order, name)
+			return replace
+		}}
+	}
+	denied := &apix.HTTPError{Status: http.StatusForbidden, Message: "denied"}
+	interceptors := []apix.Interceptor{
+		onError("outer", nil),
+		onError("inner", denied),
+		{AfterDecode: func(ctx context.Context, call *apix.Call) (context.Context, error) {
+			return ctx, errors.New("not allowed")
+		}},
+	}
+
+	_, _, err := apix.InterceptDecode(context.Background(), &apix.Call{}, interceptors, func(ctx context.Context) (*sampleReq, error) {
+		return &sampleReq{}, nil
+	})
+	if !errors.Is(err, denied) {
+		t.Fatalf("expected replaced error, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "inner" || order[1] != "outer" {
+		t.Fatalf("expected OnError innermost first, got %v", order)
+	}
+}
@@ -0,0 +1,82 @@
+package capture
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Recorder buffers a response so it can be inspected before it reaches the client.
+// It is used by the adapters when response validation is enabled.
+type Recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	rejected    bool
+	body        bytes.Buffer
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{header: make(http.Header), status: http.StatusOK}
+}
+
+// Header implements http.ResponseWriter.
+func (r *Recorder) Header() http.Header { return r.header }
+
+// WriteHeader implements http.ResponseWriter. Only the first call takes effect.
+func (r *Recorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+// Write implements http.ResponseWriter.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(p)
+}
+
+// WriteString buffers s.
+func (r *Recorder) WriteString(s string) (int, error) {
+	r.wroteHeader = true
+	return r.body.WriteString(s)
+}
+
+// Written reports whether a status or body has been written.
+func (r *Recorder) Written() bool { return r.wroteHeader }
+
+// Reject marks the response as answering a request the adapter rejected before the handler
+// ran, such as one whose body could not be decoded.
+func (r *Recorder) Reject() { r.rejected = true }
+
+// Rejected reports whether Reject was called.
+func (r *Recorder) Rejected() bool { return r.rejected }
+
+// Reject calls Reject on w when w records responses, and does nothing otherwise.
+func Reject(w any) {
+	if rec, ok := w.(interface{ Reject() }); ok {
+		rec.Reject()
+	}
+}
+
+// Status returns the recorded status code, http.StatusOK when none was written.
+func (r *Recorder) Status() int { return r.status }
+
+// Body returns the buffered body.
+func (r *Recorder) Body() []byte { return r.body.Bytes() }
+
+// FlushTo copies the recorded headers, status and body to w.
+func (r *Recorder) FlushTo(w http.ResponseWriter) error {
+	dst := w.Header()
+	for key, values := range r.header {
+		dst[key] = values
+	}
+	w.WriteHeader(r.status)
+	if r.body.Len() == 0 {
+		return nil
+	}
+	_, err := w.Write(r.body.Bytes())
+	return err
+}
@@ -0,0 +1,46 @@
+package capture_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Infra-Forge/infra-apix/internal/capture"
+)
+
+func TestRecorderBuffersUntilFlush(t *testing.T) {
+	rec := capture.NewRecorder()
+	if rec.Written() || rec.Status() != http.StatusOK {
+		t.Fatalf("expected fresh recorder, got status %d written %v", rec.Status(), rec.Written())
+	}
+
+	rec.Header().Set("X-Trace", "abc")
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteHeader(http.StatusTeapot)
+	rec.Write([]byte(`{"id":`))
+	rec.WriteString(`"1"}`)
+
+	if rec.Status() != http.StatusCreated || string(rec.Body()) != `{"id":"1"}` {
+		t.Fatalf("unexpected recording %d %q", rec.Status(), rec.Body())
+	}
+
+	out := httptest.NewRecorder()
+	if err := rec.FlushTo(out); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if out.Code != http.StatusCreated || out.Header().Get("X-Trace") != "abc" || out.Body.String() != `{"id":"1"}` {
+		t.Fatalf("unexpected flushed response %d %v %q", out.Code, out.Header(), out.Body.String())
+	}
+}
+
+func TestRejectMarksRecorders(t *testing.T) {
+	rec := capture.NewRecorder()
+	if rec.Rejected() {
+		t.Fatalf("expected fresh recorder not to be rejected")
+	}
+	capture.Reject(rec)
+	capture.Reject(httptest.NewRecorder())
+	if !rec.Rejected() {
+		t.Fatalf("expected recorder to be marked rejected")
+	}
+}
@@ -37,6 +37,18 @@ func HandleError(w http.ResponseWriter, r *http.Request, err error, useProblemDe
 			return
 		}
 
+		// Validation errors carry per-field details
+		var validationErr *apix.ValidationError
+		if errors.As(err, &validationErr) {
+			data, marshalErr := json.Marshal(validationErr.ErrorResponse())
+			if marshalErr == nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				w.Write(data)
+				return
+			}
+		}
+
 		http.Error(w, err.Error(), status)
 		return
 	}
@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats maps string rules onto OpenAPI formats.
+var Formats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"uri":              "uri",
+	"http_url":         "uri",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"uuid_rfc4122":     "uuid",
+	"hostname":         "hostname",
+	"hostname_rfc1123": "hostname",
+	"ipv4":             "ipv4",
+	"ipv6":             "ipv6",
+	"datetime":         "date-time",
+}
+
+// Patterns maps string rules onto equivalent regular expressions.
+var Patterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":      `^[0-9]+$`,
+	"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+	"e164":        `^\+[1-9]?[0-9]{7,14}$`,
+}
+
+var patternMessages = map[string]string{
+	"alpha":       "must contain only letters",
+	"alphanum":    "must contain only letters and digits",
+	"numeric":     "must be a numeric value",
+	"number":      "must contain only digits",
+	"hexadecimal": "must be a hexadecimal value",
+	"e164":        "must be an E.164 phone number",
+}
+
+var (
+	compiledPatterns = func() map[string]*regexp.Regexp {
+		out := make(map[string]*regexp.Regexp, len(Patterns))
+		for name, p := range Patterns {
+			out[name] = regexp.MustCompile(p)
+		}
+		return out
+	}()
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]{0,61}[a-zA-Z0-9]))*\.?$`)
+)
+
+// Check evaluates rule against v, which must not be a pointer. Rules that do not apply to the
+// value's kind, as well as unknown rules, pass. "required", "omitempty" and "dive" are
+// structural and left to the caller.
+func Check(rule Rule, v reflect.Value) (message string, ok bool) {
+	switch rule.Name {
+	case "min", "gte":
+		return checkSize(rule.Param, v, func(a, b float64) bool { return a >= b }, "at least", "greater than or equal to")
+	case "max", "lte":
+		return checkSize(rule.Param, v, func(a, b float64) bool { return a <= b }, "at most", "less than or equal to")
+	case "gt":
+		return checkSize(rule.Param, v, func(a, b float64) bool { return a > b }, "more than", "greater than")
+	case "lt":
+		return checkSize(rule.Param, v, func(a, b float64) bool { return a < b }, "fewer than", "less than")
+	case "len":
+		return checkSize(rule.Param, v, func(a, b float64) bool { return a == b }, "exactly", "equal to")
+	case "oneof":
+		values := OneOfValues(rule.Param)
+		s, isScalar := scalarString(v)
+		if !isScalar {
+			return "", true
+		}
+		for _, allowed := range values {
+			if s == allowed {
+				return "", true
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", strings.Join(values, ", ")), false
+	case "unique":
+		return checkUnique(v)
+	}
+
+	if v.Kind() != reflect.String {
+		return "", true
+	}
+	s := v.String()
+
+	switch rule.Name {
+	case "startswith":
+		if !strings.HasPrefix(s, rule.Param) {
+			return fmt.Sprintf("must start with %q", rule.Param), false
+		}
+	case "endswith":
+		if !strings.HasSuffix(s, rule.Param) {
+			return fmt.Sprintf("must end with %q", rule.Param), false
+		}
+	case "contains":
+		if !strings.Contains(s, rule.Param) {
+			return fmt.Sprintf("must contain %q", rule.Param), false
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address", false
+		}
+	case "url", "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return "must be a valid URL", false
+		}
+	case "http_url":
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be a valid HTTP URL", false
+		}
+	case "uuid", "uuid_rfc4122":
+		if !uuidPattern.MatchString(s) {
+			return "must be a valid UUID", false
+		}
+	case "uuid3", "uuid4", "uuid5":
+		if !uuidPattern.MatchString(s) || s[14] != rule.Name[4] {
+			return fmt.Sprintf("must be a valid version %c UUID", rule.Name[4]), false
+		}
+	case "hostname", "hostname_rfc1123":
+		if !hostnamePattern.MatchString(s) {
+			return "must be a valid hostname", false
+		}
+	case "ipv4":
+		if addr, err := netip.ParseAddr(s); err != nil || !addr.Is4() {
+			return "must be a valid IPv4 address", false
+		}
+	case "ipv6":
+		if addr, err := netip.ParseAddr(s); err != nil || !addr.Is6() {
+			return "must be a valid IPv6 address", false
+		}
+	case "ip":
+		if _, err := netip.ParseAddr(s); err != nil {
+			return "must be a valid IP address", false
+		}
+	case "datetime":
+		layout := rule.Param
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		if _, err := time.Parse(layout, s); err != nil {
+			return fmt.Sprintf("must be a date-time in layout %q", layout), false
+		}
+	default:
+		if re, ok := compiledPatterns[rule.Name]; ok && !re.MatchString(s) {
+			return patternMessages[rule.Name], false
+		}
+	}
+	return "", true
+}
+
+// checkSize compares string length, collection size or numeric value against param.
+func checkSize(param string, v reflect.Value, cmp func(a, b float64) bool, sizeWord, numberWord string) (string, bool) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return "", true
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if cmp(float64(utf8.RuneCountInString(v.String())), limit) {
+			return "", true
+		}
+		return fmt.Sprintf("must be %s %s characters long", sizeWord, param), false
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if cmp(float64(v.Len()), limit) {
+			return "", true
+		}
+		return fmt.Sprintf("must contain %s %s items", sizeWord, param), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if cmp(float64(v.Int()), limit) {
+			return "", true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if cmp(float64(v.Uint()), limit) {
+			return "", true
+		}
+	case reflect.Float32, reflect.Float64:
+		if cmp(v.Float(), limit) {
+			return "", true
+		}
+	default:
+		return "", true
+	}
+	return fmt.Sprintf("must be %s %s", numberWord, param), false
+}
+
+func checkUnique(v reflect.Value) (string, bool) {
+	var values func(i int) reflect.Value
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values = v.Index
+	case reflect.Map:
+		keys := v.MapKeys()
+		values = func(i int) reflect.Value { return v.MapIndex(keys[i]) }
+	default:
+		return "", true
+	}
+	seen := make(map[any]struct{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := values(i)
+		if !item.CanInterface() {
+			return "", true
+		}
+		key := item.Interface()
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return "", true
+		}
+		if _, dup := seen[key]; dup {
+			return "must contain unique items", false
+		}
+		seen[key] = struct{}{}
+	}
+	return "", true
+}
+
+func scalarString(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
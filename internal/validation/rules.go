@@ -0,0 +1,116 @@
+// Package validation parses go-playground style `validate` and `binding` struct tags so the
+// schema builder and the runtime validator agree on the same rules.
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Rule is a single tag directive such as "min=1" or "email".
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Rules holds the directives of one field. Rules after a "dive" apply to slice, array or map
+// elements and are stored in Items.
+type Rules struct {
+	Field []Rule
+	Items *Rules
+}
+
+// TagKeys lists the struct tags rules are read from, in order.
+var TagKeys = []string{"validate", "binding"}
+
+// FieldRules merges the rules declared in the field's validate and binding tags.
+func FieldRules(field reflect.StructField) Rules {
+	var merged Rules
+	for _, key := range TagKeys {
+		if tag := field.Tag.Get(key); tag != "" {
+			merged = merge(merged, Parse(tag))
+		}
+	}
+	return merged
+}
+
+// Parse splits a tag value into rules. Alternatives ("a|b") cannot be expressed as a single
+// constraint and are skipped.
+func Parse(tag string) Rules {
+	var out Rules
+	current := &out
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.Contains(part, "|") {
+			continue
+		}
+		if part == "dive" {
+			current.Items = &Rules{}
+			current = current.Items
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		current.Field = append(current.Field, Rule{Name: name, Param: param})
+	}
+	return out
+}
+
+// Has reports whether a rule with the given name is present.
+func (r Rules) Has(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// Get returns the first rule with the given name.
+func (r Rules) Get(name string) (Rule, bool) {
+	for _, rule := range r.Field {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// OneOfValues splits a oneof parameter into its values. Single quotes group values containing spaces.
+func OneOfValues(param string) []string {
+	var (
+		values  []string
+		current strings.Builder
+		quoted  bool
+		pending bool
+	)
+	for _, r := range param {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			pending = true
+		case r == ' ' && !quoted:
+			if pending {
+				values = append(values, current.String())
+				current.Reset()
+				pending = false
+			}
+		default:
+			current.WriteRune(r)
+			pending = true
+		}
+	}
+	if pending {
+		values = append(values, current.String())
+	}
+	return values
+}
+
+func merge(a, b Rules) Rules {
+	out := Rules{Field: append(append([]Rule{}, a.Field...), b.Field...)}
+	switch {
+	case a.Items != nil && b.Items != nil:
+		items := merge(*a.Items, *b.Items)
+		out.Items = &items
+	case a.Items != nil:
+		out.Items = a.Items
+	case b.Items != nil:
+		out.Items = b.Items
+	}
+	return out
+}
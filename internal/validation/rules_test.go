@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSplitsDiveRules(t *testing.T) {
+	rules := Parse("required,min=1,max=5,dive,oneof=a b,dive,len=2")
+	if !rules.Has("required") || len(rules.Field) != 3 {
+		t.Fatalf("unexpected field rules: %+v", rules.Field)
+	}
+	if rule, ok := rules.Get("max"); !ok || rule.Param != "5" {
+		t.Fatalf("expected max=5, got %+v", rule)
+	}
+	if rules.Items == nil || !rules.Items.Has("oneof") {
+		t.Fatalf("expected item rules, got %+v", rules.Items)
+	}
+	if rules.Items.Items == nil || !rules.Items.Items.Has("len") {
+		t.Fatalf("expected nested item rules, got %+v", rules.Items.Items)
+	}
+}
+
+func TestParseSkipsAlternatives(t *testing.T) {
+	rules := Parse("omitempty,email|url, min=3 ,")
+	if rules.Has("email") || rules.Has("email|url") || !rules.Has("min") {
+		t.Fatalf("unexpected rules: %+v", rules.Field)
+	}
+}
+
+func TestFieldRulesMergesValidateAndBinding(t *testing.T) {
+	field, _ := reflect.TypeOf(struct {
+		Tags []string `validate:"min=1,dive,max=3" binding:"max=4,dive,alpha"`
+	}{}).FieldByName("Tags")
+
+	rules := FieldRules(field)
+	if !rules.Has("min") || !rules.Has("max") {
+		t.Fatalf("expected merged field rules, got %+v", rules.Field)
+	}
+	if rules.Items == nil || !rules.Items.Has("max") || !rules.Items.Has("alpha") {
+		t.Fatalf("expected merged item rules, got %+v", rules.Items)
+	}
+}
+
+func TestOneOfValues(t *testing.T) {
+	got := OneOfValues("red  'dark blue' green ''")
+	want := []string{"red", "dark blue", "green", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
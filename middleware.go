@@ -0,0 +1,126 @@
+package apix
+
+import "sync"
+
+// SecuredMiddleware is framework middleware marked with the security requirement it
+// enforces, so routes it wraps are documented as secured. Create one with SecuredBy.
+type SecuredMiddleware[M any] struct {
+	Middleware   M
+	Requirements []SecurityRequirement
+}
+
+// SecuredBy marks mw as enforcing the named security scheme with the given scopes. Pass the
+// result to an adapter's UseSecured, or to WithMiddleware on a group or route:
+//
+//	jwt := apix.SecuredBy(jwtauth.Authenticator(tokenAuth), "BearerAuth")
+//	adapter.UseSecured(jwt)
+//	admin := adapter.Group("/admin", apix.WithMiddleware(apix.SecuredBy(requireAdmin, "BearerAuth", "admin")))
+//
+// The requirement documents what mw enforces; enforcement stays with mw unless a scheme
+// with a Verifier is registered under the same name.
+func SecuredBy[M any](mw M, name string, scopes ...string) SecuredMiddleware[M] {
+	return SecuredMiddleware[M]{
+		Middleware:   mw,
+		Requirements: []SecurityRequirement{{Name: name, Scopes: scopes}},
+	}
+}
+
+// SecurityRequirements implements SecurityRequirer.
+func (m SecuredMiddleware[M]) SecurityRequirements() []SecurityRequirement {
+	return m.Requirements
+}
+
+// UnwrapMiddleware returns the marked middleware.
+func (m SecuredMiddleware[M]) UnwrapMiddleware() any {
+	return m.Middleware
+}
+
+// SecurityRequirer is implemented by middleware values that report the security
+// requirements they enforce, such as SecuredMiddleware.
+type SecurityRequirer interface {
+	SecurityRequirements() []SecurityRequirement
+}
+
+// SecurityDetector recognises third-party middleware that enforces security, such as a JWT
+// middleware from another module, so it needs no SecuredBy marking. DetectSecurity returns
+// the requirements mw enforces, or nil for middleware the detector does not know.
+type SecurityDetector interface {
+	DetectSecurity(mw any) []SecurityRequirement
+}
+
+// SecurityDetectorFunc adapts a function to SecurityDetector.
+type SecurityDetectorFunc func(mw any) []SecurityRequirement
+
+// DetectSecurity calls f.
+func (f SecurityDetectorFunc) DetectSecurity(mw any) []SecurityRequirement {
+	return f(mw)
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []SecurityDetector
+)
+
+// RegisterSecurityDetector adds d to the detectors consulted for middleware added with an
+// adapter's Use or with WithMiddleware. Register detectors before adding middleware.
+func RegisterSecurityDetector(d SecurityDetector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors = append(detectors, d)
+}
+
+// ResetSecurityDetectors removes all registered detectors (primarily for tests).
+func ResetSecurityDetectors() {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors = nil
+}
+
+// DetectSecurity returns the security requirements mw enforces: those reported by a
+// SecurityRequirer, otherwise those found by the registered detectors.
+func DetectSecurity(mw any) []SecurityRequirement {
+	if requirer, ok := mw.(SecurityRequirer); ok {
+		return requirer.SecurityRequirements()
+	}
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	var out []SecurityRequirement
+	for _, d := range detectors {
+		out = append(out, d.DetectSecurity(mw)...)
+	}
+	return out
+}
+
+// UnwrapMiddleware returns the framework middleware inside a SecuredMiddleware, or mw itself.
+func UnwrapMiddleware(mw any) any {
+	if secured, ok := mw.(interface{ UnwrapMiddleware() any }); ok {
+		return secured.UnwrapMiddleware()
+	}
+	return mw
+}
+
+// MiddlewareSecurity returns a RouteOption adding the security requirements detected on
+// middleware to RouteRef.MiddlewareSecurity. Adapters apply it for middleware added with
+// Use. Unlike WithSecurity, the requirements are kept when a route or inner group declares
+// its own security, since the middleware still runs.
+func MiddlewareSecurity(middleware ...any) RouteOption {
+	var requirements []SecurityRequirement
+	for _, mw := range middleware {
+		requirements = append(requirements, DetectSecurity(mw)...)
+	}
+	return func(r *RouteRef) {
+		r.MiddlewareSecurity = append(r.MiddlewareSecurity, requirements...)
+	}
+}
+
+// WithMiddleware wraps the route's handler in framework middleware, outermost first, and
+// documents the security requirements detected on it. Each value must be the adapter's
+// middleware type, optionally marked with SecuredBy. Used on a group, it wraps every route
+// of the group.
+func WithMiddleware(middleware ...any) RouteOption {
+	security := MiddlewareSecurity(middleware...)
+	return func(r *RouteRef) {
+		r.Middleware = append(r.Middleware, middleware...)
+		security(r)
+	}
+}
@@ -0,0 +1,83 @@
+package apix_test
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func requireTenant(next http.Handler) http.Handler { return next }
+
+func requestID(next http.Handler) http.Handler { return next }
+
+func TestWithMiddlewareDocumentsSecuredMiddleware(t *testing.T) {
+	secured := apix.SecuredBy(requestID, "BearerAuth", "items:write")
+	ref := &apix.RouteRef{Method: apix.MethodPost, Path: "/items"}
+	apix.WithMiddleware(secured, requestID)(ref)
+
+	if len(ref.Middleware) != 2 {
+		t.Fatalf("expected middleware to be attached, got %v", ref.Middleware)
+	}
+	if _, ok := apix.UnwrapMiddleware(ref.Middleware[0]).(func(http.Handler) http.Handler); !ok {
+		t.Fatalf("expected unwrapped middleware, got %T", apix.UnwrapMiddleware(ref.Middleware[0]))
+	}
+	want := []apix.SecurityRequirement{{Name: "BearerAuth", Scopes: []string{"items:write"}}}
+	if !reflect.DeepEqual(ref.MiddlewareSecurity, want) || len(ref.Security) != 0 {
+		t.Fatalf("expected detected middleware security, got %v %v", ref.MiddlewareSecurity, ref.Security)
+	}
+}
+
+func TestSecurityDetectorsRecogniseThirdPartyMiddleware(t *testing.T) {
+	t.Cleanup(apix.ResetSecurityDetectors)
+	apix.RegisterSecurityDetector(apix.SecurityDetectorFunc(func(mw any) []apix.SecurityRequirement {
+		fn := reflect.ValueOf(mw)
+		if fn.Kind() != reflect.Func || !strings.HasSuffix(runtime.FuncForPC(fn.Pointer()).Name(), ".requireTenant") {
+			return nil
+		}
+		return []apix.SecurityRequirement{{Name: "TenantKey"}}
+	}))
+
+	if got := apix.DetectSecurity(requestID); len(got) != 0 {
+		t.Fatalf("expected unknown middleware to be ignored, got %v", got)
+	}
+	if got := apix.DetectSecurity(requireTenant); len(got) != 1 || got[0].Name != "TenantKey" {
+		t.Fatalf("expected detected requirement, got %v", got)
+	}
+}
+
+func TestGroupWithAddsOptionsAtItsOwnLevel(t *testing.T) {
+	root := apix.Group{}.With(apix.MiddlewareSecurity(apix.SecuredBy(requestID, "SessionCookie")))
+	admin := root.Group("/admin", apix.WithTags("Admin"))
+	secured := admin.With(apix.MiddlewareSecurity(apix.SecuredBy(requestID, "BearerAuth", "admin")))
+
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: admin.Path("/stats")}
+	admin.Apply(ref, nil)
+	if len(ref.MiddlewareSecurity) != 1 || ref.MiddlewareSecurity[0].Name != "SessionCookie" {
+		t.Fatalf("expected original group to be unchanged, got %v", ref.MiddlewareSecurity)
+	}
+
+	ref = &apix.RouteRef{Method: apix.MethodGet, Path: secured.Path("/stats")}
+	secured.Apply(ref, nil)
+	want := []apix.SecurityRequirement{{Name: "SessionCookie"}, {Name: "BearerAuth", Scopes: []string{"admin"}}}
+	if !reflect.DeepEqual(ref.MiddlewareSecurity, want) || !reflect.DeepEqual(ref.Tags, []string{"Admin"}) {
+		t.Fatalf("expected middleware security of every level, got %v %v", ref.MiddlewareSecurity, ref.Tags)
+	}
+}
+
+func TestDeclaredSecurityKeepsMiddlewareRequirements(t *testing.T) {
+	group := apix.Group{}.With(apix.MiddlewareSecurity(apix.SecuredBy(requestID, "BearerAuth")))
+	admin := group.Group("/admin", apix.WithSecurity("ApiKey"))
+
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: admin.Path("/stats")}
+	admin.Apply(ref, []apix.RouteOption{apix.WithSecurity("OAuth", "admin")})
+	if len(ref.Security) != 1 || ref.Security[0].Name != "OAuth" {
+		t.Fatalf("expected route security to replace the group's, got %v", ref.Security)
+	}
+	if len(ref.MiddlewareSecurity) != 1 || ref.MiddlewareSecurity[0].Name != "BearerAuth" {
+		t.Fatalf("expected middleware requirement to be kept, got %v", ref.MiddlewareSecurity)
+	}
+}
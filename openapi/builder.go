@@ -1,28 +1,57 @@
 package openapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	apix "github.com/Infra-Forge/infra-apix"
 	"github.com/Infra-Forge/infra-apix/internal/logging"
+	"github.com/Infra-Forge/infra-apix/internal/validation"
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-// Builder converts registered routes into an OpenAPI 3.1 document.
+// Builder converts registered routes into an OpenAPI 3.1 (or 3.0) document. Routes using
+// methods without a path item field, such as PROPFIND, are documented under the
+// AdditionalOperationsExtension of their path item.
 type Builder struct {
+	// OpenAPIVersion selects the target document version: OpenAPIVersion31 (default) or
+	// OpenAPIVersion30. It sets the document's openapi field, which EncodeDocument uses to
+	// render schemas as JSON Schema 2020-12 or as OpenAPI 3.0 schema objects.
+	OpenAPIVersion string
+
 	Info            openapi3.Info
 	Servers         openapi3.Servers
 	SecuritySchemes openapi3.SecuritySchemes
 	GlobalSecurity  openapi3.SecurityRequirements
 	Tags            openapi3.Tags
 
-	doc         *openapi3.T
-	schemaCache map[reflect.Type]*openapi3.SchemaRef
+	// ComponentNamer overrides how named types are keyed in components/schemas.
+	// Default: DefaultComponentName.
+	ComponentNamer ComponentNamer
+
+	// FlattenEmbedded documents the promoted fields of embedded structs as properties of
+	// the embedding struct, as encoding/json serialises them. Embedded fields tagged
+	// `openapi:"allOf"` stay allOf entries. Default: every embedded struct is an allOf entry.
+	FlattenEmbedded bool
+
+	// StrictComponentNames fails the build when two types map to the same component
+	// name instead of qualifying them with more of their package path.
+	StrictComponentNames bool
+
+	doc             *openapi3.T
+	schemaCache     map[reflect.Type]*openapi3.SchemaRef
+	typeSchemas     typeSchemaSet
+	componentNames  map[reflect.Type]string
+	componentOwners map[string]reflect.Type
+	componentOrder  []reflect.Type
+	nameCollision   bool
 }
 
 func NewBuilder() *Builder {
@@ -34,35 +63,54 @@ func NewBuilder() *Builder {
 	}
 }
 
-// Build transforms the route registry snapshot into an OpenAPI document.
+// Build transforms the route registry snapshot into an OpenAPI document. Security schemes
+// attached to routes with apix.SecurityScheme.Require are added to components, unless
+// SecuritySchemes defines a scheme of the same name.
 func (b *Builder) Build(routes []*apix.RouteRef) (*openapi3.T, error) {
-	paths := openapi3.NewPaths()
-	components := &openapi3.Components{
-		Schemas:         map[string]*openapi3.SchemaRef{},
-		SecuritySchemes: b.SecuritySchemes,
-	}
-	doc := &openapi3.T{
-		OpenAPI:    "3.1.0",
-		Info:       &b.Info,
-		Servers:    b.Servers,
-		Components: components,
-		Paths:      paths,
-		Tags:       b.Tags,
+	return b.build(routes, nil)
+}
+
+// BuildRegistry builds a document from the routes currently held by registry, documenting
+// the security schemes registered with it as well.
+// A nil registry builds from apix.DefaultRegistry().
+func (b *Builder) BuildRegistry(registry *apix.Registry) (*openapi3.T, error) {
+	if registry == nil {
+		registry = apix.DefaultRegistry()
 	}
+	return b.build(registry.Snapshot(), registry.SecuritySchemes())
+}
 
-	if len(b.GlobalSecurity) > 0 {
-		doc.Security = b.GlobalSecurity
+func (b *Builder) build(routes []*apix.RouteRef, schemes []*apix.SecurityScheme) (*openapi3.T, error) {
+	version := b.OpenAPIVersion
+	if version == "" {
+		version = OpenAPIVersion31
+	}
+	if err := checkOpenAPIVersion(version); err != nil {
+		return nil, err
+	}
+	securitySchemes, err := b.securitySchemes(version, routes, schemes)
+	if err != nil {
+		return nil, err
 	}
 
-	b.doc = doc
-	b.schemaCache = make(map[reflect.Type]*openapi3.SchemaRef)
 	defer func() {
 		b.doc = nil
 		b.schemaCache = nil
+		b.componentNames = nil
+		b.componentOwners = nil
+		b.componentOrder = nil
+		b.nameCollision = false
 	}()
 
-	for _, route := range routes {
-		if err := b.addRoute(doc, route); err != nil {
+	doc, err := b.buildDocument(version, securitySchemes, routes)
+	if err != nil {
+		return nil, err
+	}
+	if b.nameCollision {
+		// Which of the colliding types kept the short name depended on the order routes
+		// were traversed; rebuild with names planned from the complete set of types.
+		b.planComponentNames()
+		if doc, err = b.buildDocument(version, securitySchemes, routes); err != nil {
 			return nil, err
 		}
 	}
@@ -82,6 +130,72 @@ func (b *Builder) Build(routes []*apix.RouteRef) (*openapi3.T, error) {
 	return doc, nil
 }
 
+// buildDocument documents routes in a new document, keeping the component names already
+// assigned to types.
+func (b *Builder) buildDocument(version string, securitySchemes openapi3.SecuritySchemes, routes []*apix.RouteRef) (*openapi3.T, error) {
+	paths := openapi3.NewPaths()
+	components := &openapi3.Components{
+		Schemas:         map[string]*openapi3.SchemaRef{},
+		SecuritySchemes: securitySchemes,
+	}
+	doc := &openapi3.T{
+		OpenAPI:    version,
+		Info:       &b.Info,
+		Servers:    b.Servers,
+		Components: components,
+		Paths:      paths,
+		Tags:       b.Tags,
+	}
+
+	if len(b.GlobalSecurity) > 0 {
+		doc.Security = b.GlobalSecurity
+	}
+
+	b.doc = doc
+	b.schemaCache = make(map[reflect.Type]*openapi3.SchemaRef)
+	for _, route := range routes {
+		if err := b.addRoute(doc, route); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// securitySchemes merges the registered schemes, the schemes attached to routes and the
+// Builder's own SecuritySchemes, which take precedence. Schemes sharing a name must share
+// their definition, otherwise the document could describe another scheme than the one the
+// adapters verify.
+func (b *Builder) securitySchemes(version string, routes []*apix.RouteRef, schemes []*apix.SecurityScheme) (openapi3.SecuritySchemes, error) {
+	for _, route := range routes {
+		for _, sec := range slices.Concat(route.Security, route.MiddlewareSecurity) {
+			if sec.Scheme != nil {
+				schemes = append(schemes, sec.Scheme)
+			}
+		}
+	}
+	if len(schemes) == 0 {
+		return b.SecuritySchemes, nil
+	}
+
+	out := make(openapi3.SecuritySchemes, len(schemes)+len(b.SecuritySchemes))
+	for _, scheme := range schemes {
+		if existing, ok := out[scheme.Name]; ok {
+			if !reflect.DeepEqual(existing.Value, scheme.Definition) {
+				return nil, fmt.Errorf("security scheme %q: conflicting definitions for the same name", scheme.Name)
+			}
+			continue
+		}
+		if scheme.Definition.Type == apix.SecurityTypeMutualTLS && !isOpenAPI31(version) {
+			return nil, fmt.Errorf("security scheme %q: mutualTLS requires OpenAPI 3.1", scheme.Name)
+		}
+		out[scheme.Name] = &openapi3.SecuritySchemeRef{Value: scheme.Definition}
+	}
+	for name, ref := range b.SecuritySchemes {
+		out[name] = ref
+	}
+	return out, nil
+}
+
 func (b *Builder) addRoute(doc *openapi3.T, ref *apix.RouteRef) error {
 	// Normalize path to OpenAPI format (convert :param and *param to {param})
 	normalizedPath := normalizePath(ref.Path)
@@ -99,45 +213,15 @@ func (b *Builder) addRoute(doc *openapi3.T, ref *apix.RouteRef) error {
 	op.Deprecated = ref.Deprecated
 	op.Tags = ref.Tags
 
-	if len(ref.Parameters) > 0 {
-		params := append([]apix.Parameter(nil), ref.Parameters...)
-		sort.SliceStable(params, func(i, j int) bool {
-			if params[i].In == params[j].In {
-				return params[i].Name < params[j].Name
-			}
-			return params[i].In < params[j].In
-		})
-		op.Parameters = make(openapi3.Parameters, 0, len(params))
-		for _, p := range params {
-			paramSchema := &openapi3.Schema{}
-			schemaType(paramSchema, p.SchemaType)
-			if paramSchema.Type == nil || len(*paramSchema.Type) == 0 {
-				schemaType(paramSchema, "string")
-			}
-			param := &openapi3.Parameter{
-				Name:        p.Name,
-				In:          p.In,
-				Description: p.Description,
-				Required:    p.Required,
-				Schema:      &openapi3.SchemaRef{Value: paramSchema},
-			}
-			if p.SchemaType == "" {
-				schemaType(param.Schema.Value, "string")
-			}
-			if p.Example != nil {
-				param.Example = p.Example
-			}
-			op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
-		}
+	params, err := b.buildParameters(ref)
+	if err != nil {
+		return err
+	}
+	if len(params) > 0 {
+		op.Parameters = params
 	}
 
-	if len(ref.Security) > 0 {
-		security := openapi3.NewSecurityRequirements()
-		for _, sec := range ref.Security {
-			req := openapi3.SecurityRequirement{}
-			req[sec.Name] = sec.Scopes
-			security.With(req)
-		}
+	if security := operationSecurity(ref); security != nil {
 		op.Security = security
 	}
 
@@ -151,11 +235,19 @@ func (b *Builder) addRoute(doc *openapi3.T, ref *apix.RouteRef) error {
 	for code := range ref.Responses {
 		statusCodes = append(statusCodes, code)
 	}
+	for code := range ref.SuccessHeaders {
+		if _, ok := ref.Responses[code]; !ok {
+			statusCodes = append(statusCodes, code)
+		}
+	}
 	sort.Ints(statusCodes)
 
 	for _, status := range statusCodes {
 		respRef := ref.Responses[status]
-		oaResp, err := b.buildResponse(status, respRef)
+		if respRef == nil {
+			respRef = &apix.ResponseRef{}
+		}
+		oaResp, err := b.buildResponse(status, respRef, ref.SuccessHeaders[status])
 		if err != nil {
 			return err
 		}
@@ -164,31 +256,97 @@ func (b *Builder) addRoute(doc *openapi3.T, ref *apix.RouteRef) error {
 
 	addDXDefaults(ref, op)
 
-	switch strings.ToUpper(string(ref.Method)) {
-	case http.MethodGet:
-		pathItem.Get = op
-	case http.MethodPost:
-		pathItem.Post = op
-	case http.MethodPut:
-		pathItem.Put = op
-	case http.MethodPatch:
-		pathItem.Patch = op
-	case http.MethodDelete:
-		pathItem.Delete = op
-	default:
-		return fmt.Errorf("unsupported method %s", ref.Method)
+	method := strings.ToUpper(string(ref.Method))
+	if method == http.MethodHead {
+		// HEAD responses carry the headers of the matching GET but never a body.
+		for _, resp := range op.Responses.Map() {
+			if resp.Value != nil {
+				resp.Value.Content = nil
+			}
+		}
 	}
+	setOperation(pathItem, method, op)
 
 	// Note: pathItem is already set at normalizedPath on line 80, no need to set again
 	return nil
 }
 
-func (b *Builder) buildRequestBody(ref *apix.RouteRef) (*openapi3.RequestBodyRef, error) {
-	schemaRef, err := b.schemaFromTypes(ref.ExplicitRequestModel, ref.RequestType)
-	if err != nil {
-		return nil, err
+// buildParameters merges parameters derived from path/query/header/cookie tags on the
+// request type with those declared through apix.WithParameter. Declared parameters win.
+func (b *Builder) buildParameters(ref *apix.RouteRef) (openapi3.Parameters, error) {
+	type paramKey struct{ in, name string }
+	byKey := map[paramKey]*openapi3.Parameter{}
+
+	reqType := ref.RequestType
+	for reqType != nil && reqType.Kind() == reflect.Pointer {
+		reqType = reqType.Elem()
 	}
-	if schemaRef == nil {
+	for _, field := range apix.ParameterFields(ref.RequestType) {
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		paramSchema, err := b.schemaRefFromType(fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", field.Name, err)
+		}
+		applyValidationRules(paramSchema, fieldType, validation.FieldRules(reqType.FieldByIndex(field.Index)))
+		param := &openapi3.Parameter{
+			Name:        field.Name,
+			In:          field.In,
+			Description: field.Description,
+			Required:    field.Required,
+			Schema:      paramSchema,
+		}
+		if field.Example != "" {
+			param.Example = parseExampleValue(field.Example, fieldType)
+		}
+		byKey[paramKey{field.In, field.Name}] = param
+	}
+
+	for _, p := range ref.Parameters {
+		paramSchema := &openapi3.Schema{}
+		schemaType(paramSchema, p.SchemaType)
+		if paramSchema.Type == nil || len(*paramSchema.Type) == 0 {
+			schemaType(paramSchema, "string")
+		}
+		param := &openapi3.Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      &openapi3.SchemaRef{Value: paramSchema},
+		}
+		if p.Example != nil {
+			param.Example = p.Example
+		}
+		byKey[paramKey{p.In, p.Name}] = param
+	}
+
+	if len(byKey) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]paramKey, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].in == keys[j].in {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].in < keys[j].in
+	})
+
+	params := make(openapi3.Parameters, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, &openapi3.ParameterRef{Value: byKey[k]})
+	}
+	return params, nil
+}
+
+func (b *Builder) buildRequestBody(ref *apix.RouteRef) (*openapi3.RequestBodyRef, error) {
+	if ref.ExplicitRequestModel == nil && !apix.HasBodyFields(ref.RequestType) {
 		return nil, nil
 	}
 
@@ -197,6 +355,20 @@ func (b *Builder) buildRequestBody(ref *apix.RouteRef) (*openapi3.RequestBodyRef
 		contentType = "application/json"
 	}
 
+	var schemaRef *openapi3.SchemaRef
+	var err error
+	if ref.ExplicitRequestModel == nil && apix.IsFormContentType(contentType) && len(apix.FormFields(ref.RequestType)) > 0 {
+		schemaRef, err = b.buildFormSchema(ref.RequestType)
+	} else {
+		schemaRef, err = b.schemaFromTypes(ref.ExplicitRequestModel, ref.RequestType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if schemaRef == nil {
+		return nil, nil
+	}
+
 	content := openapi3.Content{}
 	media := &openapi3.MediaType{Schema: schemaRef}
 	if ref.RequestExample != nil {
@@ -217,7 +389,92 @@ func (b *Builder) buildRequestBody(ref *apix.RouteRef) (*openapi3.RequestBodyRef
 	}, nil
 }
 
-func (b *Builder) buildResponse(status int, ref *apix.ResponseRef) (*openapi3.Response, error) {
+// AdditionalOperationsExtension is the path item extension holding operations for methods
+// OpenAPI 3.0 and 3.1 path items have no field for, keyed by method as in OpenAPI 3.2's
+// additionalOperations.
+const AdditionalOperationsExtension = "x-additional-operations"
+
+// setOperation stores op under method in pathItem.
+func setOperation(pathItem *openapi3.PathItem, method string, op *openapi3.Operation) {
+	if isPathItemMethod(method) {
+		pathItem.SetOperation(method, op)
+		return
+	}
+	if pathItem.Extensions == nil {
+		pathItem.Extensions = make(map[string]any)
+	}
+	additional, _ := pathItem.Extensions[AdditionalOperationsExtension].(map[string]*openapi3.Operation)
+	if additional == nil {
+		additional = make(map[string]*openapi3.Operation)
+		pathItem.Extensions[AdditionalOperationsExtension] = additional
+	}
+	additional[method] = op
+}
+
+// Operation returns the operation documented for method in pathItem, including
+// operations stored under AdditionalOperationsExtension.
+func Operation(pathItem *openapi3.PathItem, method string) *openapi3.Operation {
+	if pathItem == nil {
+		return nil
+	}
+	method = strings.ToUpper(method)
+	if isPathItemMethod(method) {
+		return pathItem.GetOperation(method)
+	}
+	return additionalOperations(pathItem)[method]
+}
+
+// additionalOperations returns the operations under AdditionalOperationsExtension. Decoded
+// documents hold the extension as plain JSON, which is converted to operations.
+func additionalOperations(pathItem *openapi3.PathItem) map[string]*openapi3.Operation {
+	raw, ok := pathItem.Extensions[AdditionalOperationsExtension]
+	if !ok {
+		return nil
+	}
+	if ops, ok := raw.(map[string]*openapi3.Operation); ok {
+		return ops
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var ops map[string]*openapi3.Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil
+	}
+	return ops
+}
+
+func isPathItemMethod(method string) bool {
+	switch method {
+	case http.MethodConnect, http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPatch, http.MethodPost, http.MethodPut, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// buildFormSchema documents a form body inline, naming properties as apix.BindForm reads
+// them: form tag first, then the JSON name.
+func (b *Builder) buildFormSchema(t reflect.Type) (*openapi3.SchemaRef, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := openapi3.NewObjectSchema()
+	schema.Properties = make(map[string]*openapi3.SchemaRef)
+	schema.Required = []string{}
+	for _, ff := range apix.FormFields(t) {
+		field := t.FieldByIndex(ff.Index)
+		if err := b.addProperty(schema, field, ff.Name, isFieldRequired(field)); err != nil {
+			return nil, err
+		}
+	}
+	return schemaRef(schema), nil
+}
+
+// buildResponse documents one response. Route-level success headers are added after the
+// response's own headers and replace any with the same name.
+func (b *Builder) buildResponse(status int, ref *apix.ResponseRef, successHeaders []apix.HeaderRef) (*openapi3.Response, error) {
 	schemaRef, err := b.schemaFromTypes(ref.ExplicitModelType, ref.ModelType)
 	if err != nil {
 		return nil, err
@@ -247,20 +504,10 @@ func (b *Builder) buildResponse(status int, ref *apix.ResponseRef) (*openapi3.Re
 	}
 
 	for _, hdr := range ref.Headers {
-		headerSchema := &openapi3.Schema{}
-		schemaType(headerSchema, hdr.SchemaType)
-		if headerSchema.Type == nil || len(*headerSchema.Type) == 0 {
-			schemaType(headerSchema, "string")
-		}
-		resp.Headers[hdr.Name] = &openapi3.HeaderRef{
-			Value: &openapi3.Header{
-				Parameter: openapi3.Parameter{
-					Description: hdr.Description,
-					Required:    hdr.Required,
-					Schema:      &openapi3.SchemaRef{Value: headerSchema},
-				},
-			},
-		}
+		resp.Headers[hdr.Name] = headerRef(hdr)
+	}
+	for _, hdr := range successHeaders {
+		resp.Headers[hdr.Name] = headerRef(hdr)
 	}
 
 	return resp, nil
@@ -299,7 +546,10 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 	if cached, ok := b.schemaCache[t]; ok {
 		// If this type has a component name, return a reference-only SchemaRef
 		// to avoid circular references in the OpenAPI document structure
-		name := componentName(t)
+		name, err := b.componentName(t)
+		if err != nil {
+			return nil, err
+		}
 		if name != "" {
 			return &openapi3.SchemaRef{
 				Ref: "#/components/schemas/" + name,
@@ -309,6 +559,19 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 		return cached, nil
 	}
 
+	if schema, ok := b.exactTypeSchema(t); ok {
+		return schemaRef(schema), nil
+	}
+	if info, ok := apix.LookupEnum(t); ok {
+		return b.buildEnumSchema(t, info)
+	}
+	if schema, ok := builtinTypeSchema(t); ok {
+		return schemaRef(schema), nil
+	}
+	if schema, ok := b.implementedTypeSchema(t); ok {
+		return schemaRef(schema), nil
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
 		return schemaRef(openapi3.NewBoolSchema()), nil
@@ -345,11 +608,6 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 		}
 		fallthrough
 	case reflect.Array:
-		if isUUID(t) {
-			s := openapi3.NewStringSchema()
-			s.Format = "uuid"
-			return schemaRef(s), nil
-		}
 		itemRef, err := b.schemaRefFromType(t.Elem())
 		if err != nil {
 			return nil, err
@@ -358,7 +616,7 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 		s.Items = itemRef
 		return schemaRef(s), nil
 	case reflect.Map:
-		if t.Key().Kind() != reflect.String {
+		if t.Key().Kind() != reflect.String && !implements(t.Key(), textMarshalerType) {
 			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
 		}
 		valueRef, err := b.schemaRefFromType(t.Elem())
@@ -369,26 +627,12 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 		s.AdditionalProperties = openapi3.AdditionalProperties{Schema: valueRef}
 		return schemaRef(s), nil
 	case reflect.Interface:
+		if info, ok := apix.LookupUnion(t); ok {
+			return b.buildUnionSchema(t, info)
+		}
 		s := openapi3.NewObjectSchema()
 		return schemaRef(s), nil
 	case reflect.Struct:
-		if t.AssignableTo(timeType) {
-			s := openapi3.NewStringSchema()
-			s.Format = "date-time"
-			return schemaRef(s), nil
-		}
-		if isUUID(t) {
-			s := openapi3.NewStringSchema()
-			s.Format = "uuid"
-			return schemaRef(s), nil
-		}
-		if isDecimal(t) {
-			s := openapi3.NewStringSchema()
-			s.Format = "decimal"
-			s.Description = "Decimal number represented as string for precision"
-			s.Example = "123.45"
-			return schemaRef(s), nil
-		}
 		return b.buildStructSchema(t)
 	default:
 		return nil, fmt.Errorf("unsupported type %s", t)
@@ -396,7 +640,10 @@ func (b *Builder) schemaRefNonNull(t reflect.Type) (*openapi3.SchemaRef, error)
 }
 
 func (b *Builder) buildStructSchema(t reflect.Type) (*openapi3.SchemaRef, error) {
-	name := componentName(t)
+	name, err := b.componentName(t)
+	if err != nil {
+		return nil, err
+	}
 
 	if name != "" {
 		if ref, ok := b.schemaCache[t]; ok {
@@ -449,6 +696,9 @@ func (b *Builder) buildStructSchema(t reflect.Type) (*openapi3.SchemaRef, error)
 }
 
 func (b *Builder) populateStructSchema(schema *openapi3.Schema, t reflect.Type) error {
+	if b.FlattenEmbedded {
+		return b.populateFlattened(schema, t)
+	}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
@@ -456,11 +706,20 @@ func (b *Builder) populateStructSchema(schema *openapi3.Schema, t reflect.Type)
 			continue
 		}
 
+		// Path/query/header/cookie fields are documented as operation parameters.
+		if _, _, ok := apix.ParameterTag(field); ok {
+			continue
+		}
+
 		if field.Anonymous {
 			ref, err := b.schemaRefFromType(field.Type)
 			if err != nil {
 				return err
 			}
+			// Embedded components are referenced, not inlined into the composition.
+			if component := b.componentRef(ref); component != nil {
+				ref = component
+			}
 			schema.AllOf = append(schema.AllOf, ref)
 			continue
 		}
@@ -469,43 +728,105 @@ func (b *Builder) populateStructSchema(schema *openapi3.Schema, t reflect.Type)
 		if skip {
 			continue
 		}
-		if schema.Properties == nil {
-			schema.Properties = make(map[string]*openapi3.SchemaRef)
+		if err := b.addProperty(schema, field, jsonName, isFieldRequired(field)); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Check if field is marked as a file upload
-		isFile := field.Tag.Get("format") == "binary" || field.Tag.Get("format") == "file"
+// addProperty documents field as the property jsonName of schema.
+func (b *Builder) addProperty(schema *openapi3.Schema, field reflect.StructField, jsonName string, required bool) error {
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*openapi3.SchemaRef)
+	}
 
-		var childRef *openapi3.SchemaRef
-		var err error
+	// Check if field is marked as a file upload
+	isFile := field.Tag.Get("format") == "binary" || field.Tag.Get("format") == "file"
 
-		if isFile {
-			// For file uploads, use string schema with binary format
-			fileSchema := openapi3.NewStringSchema()
-			fileSchema.Format = "binary"
-			childRef = &openapi3.SchemaRef{Value: fileSchema}
-		} else {
-			childRef, err = b.schemaRefFromType(field.Type)
-			if err != nil {
-				return err
-			}
+	var childRef *openapi3.SchemaRef
+	var err error
+
+	if isFile {
+		// For file uploads, use string schema with binary format
+		fileSchema := openapi3.NewStringSchema()
+		fileSchema.Format = "binary"
+		childRef = &openapi3.SchemaRef{Value: fileSchema}
+	} else {
+		childRef, err = b.schemaRefFromType(field.Type)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Field metadata must not leak into shared components; it goes on an allOf
+	// wrapper, which EncodeDocument renders as $ref siblings in 3.1 documents.
+	if hasPropertyAnnotations(field) {
+		if ref := b.componentRef(childRef); ref != nil {
+			childRef = &openapi3.SchemaRef{Value: &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}}}
 		}
+	}
 
-		schema.Properties[jsonName] = childRef
+	schema.Properties[jsonName] = childRef
 
-		// Apply field-level metadata from struct tags
-		childSchema := ensureSchema(childRef)
+	// Apply field-level metadata from struct tags
+	childSchema := ensureSchema(childRef)
 
-		if fieldDescription := field.Tag.Get("description"); fieldDescription != "" {
-			childSchema.Description = fieldDescription
-		}
+	if fieldDescription := field.Tag.Get("description"); fieldDescription != "" {
+		childSchema.Description = fieldDescription
+	}
+
+	if fieldExample := field.Tag.Get("example"); fieldExample != "" && !isFile {
+		childSchema.Example = parseExampleValue(fieldExample, field.Type)
+	}
+
+	if fieldDefault := field.Tag.Get("default"); fieldDefault != "" && !isFile {
+		childSchema.Default = parseExampleValue(fieldDefault, field.Type)
+	}
+
+	childSchema.ReadOnly = childSchema.ReadOnly || boolTag(field, "readonly")
+	childSchema.WriteOnly = childSchema.WriteOnly || boolTag(field, "writeonly")
+	childSchema.Deprecated = childSchema.Deprecated || boolTag(field, "deprecated")
+
+	if !isFile {
+		applyValidationRules(childRef, field.Type, validation.FieldRules(field))
+	}
+
+	if required {
+		schema.Required = append(schema.Required, jsonName)
+	}
+	return nil
+}
+
+// propertyTags are the struct tags documented on a field's property schema.
+var propertyTags = []string{"description", "example", "default", "readonly", "writeonly", "deprecated"}
 
-		if fieldExample := field.Tag.Get("example"); fieldExample != "" && !isFile {
-			childSchema.Example = parseExampleValue(fieldExample, field.Type)
+func hasPropertyAnnotations(field reflect.StructField) bool {
+	for _, tag := range propertyTags {
+		if field.Tag.Get(tag) != "" {
+			return true
 		}
+	}
+	return false
+}
 
-		if isFieldRequired(field) {
-			schema.Required = append(schema.Required, jsonName)
+// boolTag reports whether the tag is set to a true value such as `readonly:"true"`.
+func boolTag(field reflect.StructField, name string) bool {
+	v, err := strconv.ParseBool(field.Tag.Get(name))
+	return err == nil && v
+}
+
+// componentRef returns a reference-only SchemaRef when ref is, or points to, a component.
+func (b *Builder) componentRef(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	if ref.Ref != "" {
+		return ref
+	}
+	for name, component := range b.doc.Components.Schemas {
+		if component == ref {
+			return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name}
 		}
 	}
 	return nil
@@ -644,12 +965,43 @@ func addDXDefaults(ref *apix.RouteRef, op *openapi3.Operation) {
 		}
 	}
 
-	if len(ref.Security) > 0 {
+	if len(ref.Security) > 0 || len(ref.MiddlewareSecurity) > 0 {
 		ensureResponse(op, http.StatusUnauthorized, "Unauthorized")
 		ensureResponse(op, http.StatusForbidden, "Forbidden")
 	}
 }
 
+// operationSecurity returns the alternatives of ref.Security, each combined with every
+// requirement enforced by middleware, which applies whichever alternative is used. It
+// returns nil for routes without security.
+func operationSecurity(ref *apix.RouteRef) *openapi3.SecurityRequirements {
+	if len(ref.Security) == 0 && len(ref.MiddlewareSecurity) == 0 {
+		return nil
+	}
+	alternatives := ref.Security
+	if len(alternatives) == 0 {
+		alternatives = []apix.SecurityRequirement{{}}
+	}
+	security := openapi3.NewSecurityRequirements()
+	for _, alt := range alternatives {
+		req := openapi3.SecurityRequirement{}
+		for _, sec := range append([]apix.SecurityRequirement{alt}, ref.MiddlewareSecurity...) {
+			if sec.Name == "" {
+				continue
+			}
+			scopes := req[sec.Name]
+			for _, scope := range sec.Scopes {
+				if !slices.Contains(scopes, scope) {
+					scopes = append(scopes, scope)
+				}
+			}
+			req[sec.Name] = scopes
+		}
+		security.With(req)
+	}
+	return security
+}
+
 func ensureResponse(op *openapi3.Operation, status int, description string) {
 	if existing := op.Responses.Status(status); existing != nil {
 		return
@@ -695,6 +1047,10 @@ func wrapNullable(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
 }
 
 func ensureSchema(ref *openapi3.SchemaRef) *openapi3.Schema {
+	// Component references are shared; field metadata must not leak into them.
+	if ref != nil && ref.Ref != "" {
+		return &openapi3.Schema{}
+	}
 	if ref == nil {
 		ref = &openapi3.SchemaRef{Value: openapi3.NewObjectSchema()}
 	}
@@ -704,19 +1060,6 @@ func ensureSchema(ref *openapi3.SchemaRef) *openapi3.Schema {
 	return ref.Value
 }
 
-func componentName(t reflect.Type) string {
-	if t.Name() == "" {
-		return ""
-	}
-	pkg := t.PkgPath()
-	if pkg == "" {
-		return sanitizeComponentName(t.Name())
-	}
-	parts := strings.Split(pkg, "/")
-	pkgPart := parts[len(parts)-1]
-	return sanitizeComponentName(pkgPart + "_" + t.Name())
-}
-
 func sanitizeComponentName(name string) string {
 	replacer := strings.NewReplacer(
 		"-", "_",
@@ -735,7 +1078,35 @@ func sanitizeComponentName(name string) string {
 	return replacer.Replace(name)
 }
 
-var timeType = reflect.TypeOf(time.Time{})
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileUploadType = reflect.TypeOf(apix.FileUpload{})
+)
+
+// builtinTypeSchema documents well-known types that serialise as formatted strings.
+func builtinTypeSchema(t reflect.Type) (*openapi3.Schema, bool) {
+	switch {
+	case t.Kind() == reflect.Struct && t.AssignableTo(timeType):
+		s := openapi3.NewStringSchema()
+		s.Format = "date-time"
+		return s, true
+	case isUUID(t):
+		s := openapi3.NewStringSchema()
+		s.Format = "uuid"
+		return s, true
+	case apix.IsBinaryType(t), t == fileUploadType:
+		s := openapi3.NewStringSchema()
+		s.Format = "binary"
+		return s, true
+	case isDecimal(t):
+		s := openapi3.NewStringSchema()
+		s.Format = "decimal"
+		s.Description = "Decimal number represented as string for precision"
+		s.Example = "123.45"
+		return s, true
+	}
+	return stdlibTypeSchema(t)
+}
 
 func isUUID(t reflect.Type) bool {
 	return t.PkgPath() == "github.com/google/uuid" && t.Name() == "UUID"
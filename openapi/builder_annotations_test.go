@@ -0,0 +1,108 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type profileSettings struct {
+	Theme string `json:"theme"`
+}
+
+type profile struct {
+	ID        string          `json:"id" readonly:"true"`
+	CreatedAt time.Time       `json:"created_at" readonly:"true"`
+	Password  string          `json:"password,omitempty" writeonly:"true"`
+	Nickname  string          `json:"nickname,omitempty" deprecated:"true" description:"Use display_name"`
+	PageSize  *int            `json:"page_size,omitempty" default:"20"`
+	Ratio     float64         `json:"ratio,omitempty" default:"0.5"`
+	Public    bool            `json:"public,omitempty" default:"true"`
+	Locale    string          `json:"locale,omitempty" default:"en-US"`
+	State     accountState    `json:"state,omitempty" default:"active"`
+	Settings  profileSettings `json:"settings" readonly:"true"`
+	Backup    profileSettings `json:"backup"`
+	Draft     bool            `json:"draft,omitempty" readonly:"false"`
+}
+
+func profileSchema(t *testing.T) (*openapi3.T, *openapi3.Schema) {
+	t.Helper()
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:      apix.MethodPut,
+		Path:        "/profile",
+		RequestType: reflect.TypeOf(profile{}),
+		Responses:   map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(profile{})}},
+	})
+	doc, err := openapi.NewBuilder().BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	component := doc.Components.Schemas["openapi_test_profile"]
+	if component == nil || component.Value == nil {
+		t.Fatalf("profile component missing")
+	}
+	return doc, component.Value
+}
+
+func TestPropertyAccessTags(t *testing.T) {
+	_, s := profileSchema(t)
+
+	if !s.Properties["id"].Value.ReadOnly || !s.Properties["created_at"].Value.ReadOnly {
+		t.Fatalf("expected server-assigned fields to be readOnly")
+	}
+	if !s.Properties["password"].Value.WriteOnly {
+		t.Fatalf("expected password to be writeOnly")
+	}
+	nickname := s.Properties["nickname"].Value
+	if !nickname.Deprecated || nickname.Description != "Use display_name" {
+		t.Fatalf("expected deprecated nickname, got %+v", nickname)
+	}
+	if s.Properties["draft"].Value.ReadOnly {
+		t.Fatalf("readonly:\"false\" must not mark the field")
+	}
+}
+
+func TestPropertyDefaultsAreTyped(t *testing.T) {
+	_, s := profileSchema(t)
+
+	cases := map[string]any{
+		"page_size": int64(20),
+		"ratio":     0.5,
+		"public":    true,
+		"locale":    "en-US",
+		"state":     "active",
+	}
+	for name, want := range cases {
+		prop := s.Properties[name].Value
+		if prop.Default != want {
+			t.Fatalf("%s: expected default %#v, got %#v", name, want, prop.Default)
+		}
+	}
+	if state := s.Properties["state"].Value; len(state.AllOf) != 1 || state.AllOf[0].Ref == "" {
+		t.Fatalf("expected enum default on a $ref wrapper, got %+v", state)
+	}
+}
+
+func TestPropertyTagsDoNotLeakIntoComponents(t *testing.T) {
+	doc, s := profileSchema(t)
+
+	settings := s.Properties["settings"].Value
+	if !settings.ReadOnly || len(settings.AllOf) != 1 || settings.AllOf[0].Ref != "#/components/schemas/openapi_test_profileSettings" {
+		t.Fatalf("expected readOnly wrapper around the settings component, got %+v", settings)
+	}
+	if component := doc.Components.Schemas["openapi_test_profileSettings"].Value; component.ReadOnly {
+		t.Fatalf("readOnly leaked into the shared component")
+	}
+	if backup := s.Properties["backup"]; backup.Ref == "" || backup.Value != nil && backup.Value.ReadOnly {
+		t.Fatalf("untagged field must reference the component unchanged, got %+v", backup)
+	}
+	if state := doc.Components.Schemas["openapi_test_accountState"].Value; state.Default != nil {
+		t.Fatalf("default leaked into the enum component")
+	}
+}
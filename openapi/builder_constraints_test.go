@@ -0,0 +1,113 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/google/uuid"
+)
+
+type constrainedAddress struct {
+	City string `json:"city" validate:"required,min=2"`
+}
+
+type constrainedRequest struct {
+	Page      int                  `query:"page" validate:"gte=1,lte=100"`
+	Name      string               `json:"name" validate:"required,min=1,max=255"`
+	Code      string               `json:"code" binding:"len=3,alpha"`
+	Email     string               `json:"email" validate:"email"`
+	Website   *string              `json:"website,omitempty" validate:"omitempty,url"`
+	Kind      string               `json:"kind" validate:"oneof=income expense 'money transfer'"`
+	Priority  int                  `json:"priority" validate:"oneof=1 2 3"`
+	Ratio     float64              `json:"ratio" validate:"gt=0,lt=1"`
+	Tags      []string             `json:"tags" validate:"min=1,max=5,unique,dive,min=2,max=20"`
+	Labels    map[string]string    `json:"labels" validate:"max=10,dive,max=64"`
+	Owner     uuid.UUID            `json:"owner" validate:"required"`
+	Prefix    string               `json:"prefix" validate:"startswith=sk_"`
+	Addresses []constrainedAddress `json:"addresses" validate:"dive,min=3"`
+	Address   constrainedAddress   `json:"address" validate:"required,min=5"`
+}
+
+func TestBuilderMapsValidationRulesToSchema(t *testing.T) {
+	ref := &apix.RouteRef{
+		Method:             apix.MethodPost,
+		Path:               "/constrained",
+		OperationID:        "constrained",
+		RequestType:        reflect.TypeOf(constrainedRequest{}),
+		RequestContentType: "application/json",
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusCreated: {ModelType: reflect.TypeOf(constrainedAddress{})},
+		},
+	}
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	page := doc.Paths.Value("/constrained").Post.Parameters.GetByInAndName("query", "page")
+	if page == nil || *page.Schema.Value.Min != 1 || *page.Schema.Value.Max != 100 {
+		t.Fatalf("expected page bounds, got %+v", page)
+	}
+
+	body := doc.Components.Schemas["openapi_test_constrainedRequest"].Value
+	props := body.Properties
+
+	name := props["name"].Value
+	if name.MinLength != 1 || name.MaxLength == nil || *name.MaxLength != 255 {
+		t.Fatalf("unexpected name lengths: min=%d max=%v", name.MinLength, name.MaxLength)
+	}
+
+	code := props["code"].Value
+	if code.MinLength != 3 || *code.MaxLength != 3 || code.Pattern != `^[a-zA-Z]+$` {
+		t.Fatalf("unexpected code constraints: %+v", code)
+	}
+
+	if props["email"].Value.Format != "email" {
+		t.Fatalf("expected email format")
+	}
+	if website := props["website"].Value; website.Format != "uri" || !website.Nullable {
+		t.Fatalf("expected nullable uri format, got %+v", website)
+	}
+
+	kind := props["kind"].Value
+	if !reflect.DeepEqual(kind.Enum, []any{"income", "expense", "money transfer"}) {
+		t.Fatalf("unexpected kind enum: %#v", kind.Enum)
+	}
+	if priority := props["priority"].Value; !reflect.DeepEqual(priority.Enum, []any{int64(1), int64(2), int64(3)}) {
+		t.Fatalf("expected typed enum values, got %#v", priority.Enum)
+	}
+
+	ratio := props["ratio"].Value
+	if *ratio.Min != 0 || !ratio.ExclusiveMin || *ratio.Max != 1 || !ratio.ExclusiveMax {
+		t.Fatalf("expected exclusive bounds, got %+v", ratio)
+	}
+
+	tags := props["tags"].Value
+	if tags.MinItems != 1 || *tags.MaxItems != 5 || !tags.UniqueItems {
+		t.Fatalf("unexpected tags constraints: %+v", tags)
+	}
+	if item := tags.Items.Value; item.MinLength != 2 || *item.MaxLength != 20 {
+		t.Fatalf("expected dive rules on items, got %+v", item)
+	}
+
+	labels := props["labels"].Value
+	if *labels.MaxProps != 10 || *labels.AdditionalProperties.Schema.Value.MaxLength != 64 {
+		t.Fatalf("unexpected labels constraints: %+v", labels)
+	}
+
+	if props["owner"].Value.MinLength != 0 {
+		t.Fatalf("uuid values must not receive string constraints")
+	}
+	if props["prefix"].Value.Pattern != `^sk_` {
+		t.Fatalf("unexpected prefix pattern %q", props["prefix"].Value.Pattern)
+	}
+
+	address := doc.Components.Schemas["openapi_test_constrainedAddress"].Value
+	if address.MinProps != 0 || address.Properties["city"].Value.MinLength != 2 {
+		t.Fatalf("field rules must not leak into shared component schemas: %+v", address)
+	}
+}
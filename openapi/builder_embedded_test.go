@@ -0,0 +1,131 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type auditFields struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+type ownership struct {
+	OwnerID string `json:"owner_id"`
+	Kind    string
+}
+
+type Labels struct {
+	Name    string            `json:"name"`
+	Caption string            `json:"Title,omitempty"`
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+type Tagged struct {
+	Title string
+	Kind  string
+}
+
+type Identified struct {
+	ID string `json:"id"`
+}
+
+type document struct {
+	Identified `openapi:"allOf"`
+	auditFields
+	*ownership
+	Labels
+	Tagged
+	Version string  `json:"version"`
+	Summary string  `json:"summary"`
+	Parent  *Labels `json:"parent,omitempty"`
+	Meta    Labels  `json:"meta"`
+}
+
+func documentSchema(t *testing.T, flatten bool) (*openapi3.T, *openapi3.Schema) {
+	t.Helper()
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:    apix.MethodGet,
+		Path:      "/documents/:id",
+		Responses: map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(document{})}},
+	})
+	b := openapi.NewBuilder()
+	b.FlattenEmbedded = flatten
+	doc, err := b.BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	component := doc.Components.Schemas["openapi_test_document"]
+	if component == nil || component.Value == nil {
+		t.Fatalf("document component missing")
+	}
+	return doc, component.Value
+}
+
+func TestFlattenEmbeddedPromotesFields(t *testing.T) {
+	_, s := documentSchema(t, true)
+
+	for _, name := range []string{"created_at", "updated_at", "owner_id", "extra", "summary", "parent", "meta"} {
+		if s.Properties[name] == nil {
+			t.Fatalf("expected promoted property %s, got %v", name, slices.Sorted(mapsKeys(s.Properties)))
+		}
+	}
+	if version := s.Properties["version"].Value; !version.Type.Is(openapi3.TypeString) {
+		t.Fatalf("expected the outer version field to shadow the embedded one, got %+v", version)
+	}
+	// Labels.Caption (tagged "Title") wins over the untagged Tagged.Title at the same depth.
+	if title := s.Properties["Title"]; title == nil || slices.Contains(s.Required, "Title") {
+		t.Fatalf("expected the tagged Title field to win, got %v", slices.Sorted(mapsKeys(s.Properties)))
+	}
+	// Two untagged Kind fields at the same depth cancel each other out.
+	if s.Properties["Kind"] != nil {
+		t.Fatalf("expected ambiguous Kind fields to be dropped")
+	}
+	if len(s.AllOf) != 1 || s.AllOf[0].Ref != "#/components/schemas/openapi_test_Identified" {
+		t.Fatalf("expected the allOf-tagged embed to stay composed, got %+v", s.AllOf)
+	}
+	if s.Properties["id"] != nil {
+		t.Fatalf("allOf embed must not be flattened")
+	}
+}
+
+func TestFlattenEmbeddedRequiredFields(t *testing.T) {
+	_, s := documentSchema(t, true)
+
+	want := []string{"created_at", "updated_at", "name", "version", "summary", "meta"}
+	if !slices.Equal(s.Required, want) {
+		t.Fatalf("expected required %v in field order, got %v", want, s.Required)
+	}
+	if slices.Contains(s.Required, "owner_id") {
+		t.Fatalf("fields promoted through a pointer must be optional")
+	}
+}
+
+func TestEmbeddedStructsDefaultToAllOf(t *testing.T) {
+	_, s := documentSchema(t, false)
+	if len(s.AllOf) < 2 {
+		t.Fatalf("expected embedded structs as allOf entries by default, got %d", len(s.AllOf))
+	}
+	if s.Properties["created_at"] != nil {
+		t.Fatalf("fields must not be flattened unless FlattenEmbedded is set")
+	}
+}
+
+func mapsKeys[V any](m map[string]V) func(func(string) bool) {
+	return func(yield func(string) bool) {
+		for k := range m {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+type transactionType string
+
+const (
+	transactionIncome  transactionType = "income"
+	transactionExpense transactionType = "expense"
+)
+
+func (transactionType) EnumValues() []any {
+	return []any{transactionIncome, transactionExpense}
+}
+
+func (transactionType) EnumVarNames() []string { return []string{"Income", "Expense"} }
+
+func (transactionType) EnumDescriptions() []string {
+	return []string{"Money coming in", "Money going out"}
+}
+
+type severityLevel int
+
+type transaction struct {
+	Type     transactionType   `json:"type"`
+	Previous *transactionType  `json:"previous,omitempty"`
+	History  []transactionType `json:"history"`
+	Severity severityLevel     `json:"severity"`
+}
+
+type transactionFilter struct {
+	Type transactionType `query:"type"`
+}
+
+func TestBuilderDocumentsEnumsAsComponents(t *testing.T) {
+	apix.RegisterEnumMembers(
+		apix.EnumMember[severityLevel]{Value: 1, Name: "Low"},
+		apix.EnumMember[severityLevel]{Value: 2, Name: "High"},
+	)
+	defer apix.UnregisterEnum[severityLevel]()
+
+	ref := &apix.RouteRef{
+		Method:      apix.MethodGet,
+		Path:        "/transactions",
+		OperationID: "listTransactions",
+		RequestType: reflect.TypeOf(transactionFilter{}),
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {ModelType: reflect.TypeOf(transaction{})},
+		},
+	}
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	component := doc.Components.Schemas["openapi_test_transactionType"]
+	if component == nil {
+		t.Fatalf("expected transactionType component, got %v", doc.Components.Schemas)
+	}
+	if !component.Value.Type.Is("string") || !reflect.DeepEqual(component.Value.Enum, []any{"income", "expense"}) {
+		t.Fatalf("unexpected enum schema %+v", component.Value)
+	}
+	if !reflect.DeepEqual(component.Value.Extensions["x-enum-varnames"], []string{"Income", "Expense"}) ||
+		!reflect.DeepEqual(component.Value.Extensions["x-enum-descriptions"], []string{"Money coming in", "Money going out"}) {
+		t.Fatalf("unexpected enum extensions %v", component.Value.Extensions)
+	}
+
+	severity := doc.Components.Schemas["openapi_test_severityLevel"]
+	if severity == nil || !severity.Value.Type.Is("integer") || !reflect.DeepEqual(severity.Value.Enum, []any{float64(1), float64(2)}) {
+		t.Fatalf("unexpected registered enum %+v", severity)
+	}
+	if _, ok := severity.Value.Extensions["x-enum-descriptions"]; ok {
+		t.Fatal("expected empty descriptions to be omitted")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	spec := string(data)
+	for _, want := range []string{
+		`"type":{"$ref":"#/components/schemas/openapi_test_transactionType"}`,
+		`"items":{"$ref":"#/components/schemas/openapi_test_transactionType"}`,
+		`"schema":{"$ref":"#/components/schemas/openapi_test_transactionType"}`,
+		`"previous":{"allOf":[{"$ref":"#/components/schemas/openapi_test_transactionType"}],"nullable":true}`,
+	} {
+		if !strings.Contains(spec, want) {
+			t.Fatalf("expected %s in spec:\n%s", want, spec)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package openapi_test
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 
@@ -37,3 +38,20 @@ func TestBuilderSchemaInferenceCornerCases(t *testing.T) {
 		t.Fatalf("expected component schemas generated")
 	}
 }
+
+func TestBuilderBuildRegistry(t *testing.T) {
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:    apix.MethodGet,
+		Path:      "/internal/ping",
+		Responses: map[int]*apix.ResponseRef{http.StatusOK: {}},
+	})
+
+	doc, err := openapi.NewBuilder().BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if doc.Paths.Value("/internal/ping") == nil {
+		t.Fatalf("expected registry route in document")
+	}
+}
@@ -0,0 +1,107 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+type capabilityResponse struct {
+	Methods []string `json:"methods"`
+}
+
+func TestBuilderMapsAllHTTPMethods(t *testing.T) {
+	reg := apix.NewRegistry()
+	get := &apix.RouteRef{
+		Method:         apix.MethodGet,
+		Path:           "/files/{id}",
+		OperationID:    "getFile",
+		Responses:      map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(capabilityResponse{})}},
+		SuccessHeaders: map[int][]apix.HeaderRef{http.StatusOK: {{Name: "ETag", SchemaType: "string"}}},
+	}
+	reg.Register(get)
+	reg.Register(apix.HeadRoute(get))
+	for _, method := range []apix.RouteMethod{apix.MethodOptions, apix.MethodTrace, "PROPFIND"} {
+		reg.Register(&apix.RouteRef{
+			Method:      method,
+			Path:        "/files/{id}",
+			OperationID: apix.DefaultOperationID(method, "/files/{id}"),
+			Responses:   map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(capabilityResponse{})}},
+		})
+	}
+
+	doc, err := openapi.NewBuilder().BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	item := doc.Paths.Value("/files/{id}")
+	if item.Get == nil || item.Head == nil || item.Options == nil || item.Trace == nil {
+		t.Fatalf("expected GET, HEAD, OPTIONS and TRACE operations, got %v", item.Operations())
+	}
+
+	head := item.Head.Responses.Status(http.StatusOK).Value
+	if head.Content != nil || head.Headers["ETag"] == nil {
+		t.Fatalf("expected HEAD response with headers and no content, got %+v", head)
+	}
+	if item.Head.OperationID != "getFile_head" {
+		t.Fatalf("unexpected HEAD operation ID %s", item.Head.OperationID)
+	}
+	if item.Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json") == nil {
+		t.Fatalf("expected GET response to keep its content")
+	}
+
+	propfind := openapi.Operation(item, "propfind")
+	if propfind == nil || propfind.OperationID != "propfind_files_id" {
+		t.Fatalf("expected PROPFIND under %s, got %v", openapi.AdditionalOperationsExtension, item.Extensions)
+	}
+
+	data, _, err := openapi.EncodeDocument(doc, "json")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if !strings.Contains(string(data), `"PROPFIND": {`) {
+		t.Fatalf("expected encoded PROPFIND operation, got %s", data)
+	}
+	decoded, err := openapi.DecodeDocument(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if op := openapi.Operation(decoded.Paths.Value("/files/{id}"), http.MethodHead); op == nil {
+		t.Fatalf("expected decoded HEAD operation")
+	}
+	if op := openapi.Operation(decoded.Paths.Value("/files/{id}"), "PROPFIND"); op == nil || op.OperationID != "propfind_files_id" {
+		t.Fatalf("expected decoded PROPFIND operation, got %+v", op)
+	}
+
+	delete(item.Extensions, openapi.AdditionalOperationsExtension)
+	report := openapi.Diff(decoded, doc)
+	if !report.HasBreaking() || report.Changes[0].Operation != "PROPFIND /files/{id}" {
+		t.Fatalf("expected removed PROPFIND operation to be breaking, got %+v", report.Changes)
+	}
+}
+
+func TestAdditionalOperationsUseOpenAPI31Schemas(t *testing.T) {
+	reg := apix.NewRegistry()
+	reg.Register(&apix.RouteRef{
+		Method:      "PROPFIND",
+		Path:        "/files/{id}",
+		OperationID: "propfind_files_id",
+		Responses:   map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf([]*string{})}},
+	})
+	doc, err := openapi.NewBuilder().BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	data, _, err := openapi.EncodeDocument(doc, "json")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if strings.Contains(string(data), `"nullable"`) || !strings.Contains(string(data), `"null"`) {
+		t.Fatalf("expected PROPFIND schemas in OpenAPI 3.1 form, got %s", data)
+	}
+}
@@ -147,3 +147,50 @@ func TestFormURLEncodedContentType(t *testing.T) {
 		t.Fatal("Schema not found for form-urlencoded")
 	}
 }
+
+type AvatarUploadRequest struct {
+	UserID  string            `path:"id"`
+	Avatar  apix.FileUpload   `form:"avatar" validate:"required"`
+	Extras  []apix.FileUpload `form:"extra"`
+	Caption string            `form:"caption" json:"ignored"`
+}
+
+func TestMultipartFormSchemaUsesFormNamesAndFileUploads(t *testing.T) {
+	apix.ResetRegistry()
+
+	ref := &apix.RouteRef{
+		Method:      apix.MethodPost,
+		Path:        "/users/{id}/avatar",
+		RequestType: reflect.TypeOf(AvatarUploadRequest{}),
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusNoContent: {},
+		},
+	}
+	apix.WithMultipartFormData()(ref)
+	apix.RegisterRoute(ref)
+
+	doc, err := openapi.NewBuilder().Build(apix.Snapshot())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	media := doc.Paths.Value("/users/{id}/avatar").Post.RequestBody.Value.Content["multipart/form-data"]
+	if media == nil || media.Schema == nil || media.Schema.Ref != "" {
+		t.Fatalf("expected inline multipart schema, got %+v", media)
+	}
+	schema := media.Schema.Value
+	if len(schema.Properties) != 3 || schema.Properties["caption"] == nil || schema.Properties["id"] != nil {
+		t.Fatalf("expected form-named properties, got %v", schema.Properties)
+	}
+	avatar := schema.Properties["avatar"].Value
+	if !avatar.Type.Is("string") || avatar.Format != "binary" {
+		t.Fatalf("expected avatar to be a binary string, got %+v", avatar)
+	}
+	extras := schema.Properties["extra"].Value
+	if !extras.Type.Is("array") || extras.Items.Value.Format != "binary" {
+		t.Fatalf("expected extra to be an array of binary strings, got %+v", extras)
+	}
+	if len(schema.Required) == 0 || schema.Required[0] != "avatar" {
+		t.Fatalf("expected avatar to be required, got %v", schema.Required)
+	}
+}
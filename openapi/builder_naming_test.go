@@ -0,0 +1,159 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	crm "github.com/Infra-Forge/infra-apix/openapi/testdata/crm/models"
+	hr "github.com/Infra-Forge/infra-apix/openapi/testdata/hr/models"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+type pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+func namingRegistry(models ...any) *apix.Registry {
+	registry := apix.NewRegistry()
+	for i, model := range models {
+		registry.Register(&apix.RouteRef{
+			Method:    apix.MethodGet,
+			Path:      "/models/" + string(rune('a'+i)),
+			Responses: map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(model)}},
+		})
+	}
+	return registry
+}
+
+func schemaNames(doc *openapi3.T) []string {
+	var names []string
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestComponentNamesDisambiguatePackages(t *testing.T) {
+	doc, err := openapi.NewBuilder().BuildRegistry(namingRegistry(crm.User{}, hr.User{}))
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	first := doc.Components.Schemas["models_User"]
+	second := doc.Components.Schemas["hr_models_User"]
+	if first == nil || second == nil {
+		t.Fatalf("expected models_User and hr_models_User, got %v", schemaNames(doc))
+	}
+	if first.Value.Properties["email"] == nil || second.Value.Properties["employee_id"] == nil {
+		t.Fatalf("components were mixed up")
+	}
+}
+
+func TestComponentNamesDoNotDependOnRouteOrder(t *testing.T) {
+	cases := map[string]struct {
+		models  []any
+		crmPath string
+	}{
+		"crm first": {[]any{crm.User{}, hr.User{}}, "/models/a"},
+		"hr first":  {[]any{hr.User{}, crm.User{}}, "/models/b"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			doc, err := openapi.NewBuilder().BuildRegistry(namingRegistry(tc.models...))
+			if err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+			short := doc.Components.Schemas["models_User"]
+			if short == nil || doc.Components.Schemas["hr_models_User"] == nil || short.Value.Properties["email"] == nil {
+				t.Fatalf("expected crm User to keep the short name, got %v", schemaNames(doc))
+			}
+			if got := doc.Paths.Value(tc.crmPath).Get.Responses.Status(http.StatusOK).Value.Content["application/json"].Schema; got.Value != short.Value {
+				t.Fatalf("expected %s to document models_User", tc.crmPath)
+			}
+		})
+	}
+}
+
+func TestStrictComponentNamesRejectCollisions(t *testing.T) {
+	b := openapi.NewBuilder()
+	b.StrictComponentNames = true
+	_, err := b.BuildRegistry(namingRegistry(crm.User{}, hr.User{}))
+	if err == nil || !strings.Contains(err.Error(), "testdata/crm/models.User") || !strings.Contains(err.Error(), "testdata/hr/models.User") {
+		t.Fatalf("expected collision error naming both types, got %v", err)
+	}
+}
+
+func TestComponentNamesNumberLocalTypes(t *testing.T) {
+	first := func() any {
+		type receipt struct {
+			A string `json:"a"`
+		}
+		return receipt{}
+	}()
+	second := func() any {
+		type receipt struct {
+			B string `json:"b"`
+		}
+		return receipt{}
+	}()
+	doc, err := openapi.NewBuilder().BuildRegistry(namingRegistry(first, second))
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if doc.Components.Schemas["openapi_test_receipt"] == nil || doc.Components.Schemas["openapi_test_receipt_2"] == nil {
+		t.Fatalf("expected numbered component, got %v", schemaNames(doc))
+	}
+}
+
+func TestGenericComponentNames(t *testing.T) {
+	doc, err := openapi.NewBuilder().BuildRegistry(namingRegistry(
+		page[crm.User]{},
+		page[*hr.User]{},
+		pair[string, []crm.User]{},
+		page[map[string]page[int]]{},
+	))
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	for _, name := range []string{
+		"openapi_test_pageOfUser",
+		"openapi_test_pageOfUser_2",
+		"openapi_test_pairOfStringAndUserList",
+		"openapi_test_pageOfStringToPageOfIntMap",
+		"openapi_test_pageOfInt",
+	} {
+		if doc.Components.Schemas[name] == nil {
+			t.Fatalf("missing %s in %v", name, schemaNames(doc))
+		}
+	}
+}
+
+func TestComponentNamerHook(t *testing.T) {
+	b := openapi.NewBuilder()
+	b.ComponentNamer = func(t reflect.Type) string {
+		if t.PkgPath() == reflect.TypeOf(crm.User{}).PkgPath() {
+			return "CrmUser"
+		}
+		return ""
+	}
+	doc, err := b.BuildRegistry(namingRegistry(crm.User{}, hr.User{}, page[crm.User]{}))
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if doc.Components.Schemas["CrmUser"] == nil || doc.Components.Schemas["models_User"] == nil {
+		t.Fatalf("expected hook name and default fallback, got %v", schemaNames(doc))
+	}
+	items := doc.Components.Schemas["openapi_test_pageOfUser"].Value.Properties["items"].Value.Items
+	if items.Ref != "#/components/schemas/CrmUser" {
+		t.Fatalf("expected references to use the hook name, got %q", items.Ref)
+	}
+}
@@ -0,0 +1,103 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+type listWidgetsRequest struct {
+	OrgID  string   `path:"org_id" description:"Organisation identifier"`
+	Limit  *int     `query:"limit" example:"20"`
+	Status []string `query:"status"`
+	Tenant string   `header:"X-Tenant" validate:"required"`
+}
+
+type updateWidgetRequest struct {
+	ID   string `path:"id"`
+	Name string `json:"name"`
+}
+
+func TestBuilderDerivesParametersFromRequestTags(t *testing.T) {
+	ref := &apix.RouteRef{
+		Method:      apix.MethodGet,
+		Path:        "/orgs/{org_id}/widgets",
+		OperationID: "listWidgets",
+		RequestType: reflect.TypeOf(listWidgetsRequest{}),
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {ModelType: reflect.TypeOf([]string{})},
+		},
+	}
+	apix.WithParameter(apix.Parameter{Name: "X-Tenant", In: "header", Description: "Tenant override", Required: true})(ref)
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	op := doc.Paths.Value("/orgs/{org_id}/widgets").Get
+	if op.RequestBody != nil {
+		t.Fatalf("parameter-only request must not produce a request body")
+	}
+	if len(op.Parameters) != 4 {
+		t.Fatalf("expected 4 parameters, got %d", len(op.Parameters))
+	}
+
+	tenant := op.Parameters.GetByInAndName("header", "X-Tenant")
+	if tenant == nil || tenant.Description != "Tenant override" {
+		t.Fatalf("expected explicit parameter to override derived one")
+	}
+
+	orgID := op.Parameters.GetByInAndName("path", "org_id")
+	if orgID == nil || !orgID.Required || orgID.Description != "Organisation identifier" {
+		t.Fatalf("unexpected path parameter: %+v", orgID)
+	}
+
+	limit := op.Parameters.GetByInAndName("query", "limit")
+	if limit == nil || limit.Required || !limit.Schema.Value.Type.Is("integer") {
+		t.Fatalf("unexpected limit parameter: %+v", limit)
+	}
+	if limit.Example != int64(20) {
+		t.Fatalf("expected typed example, got %#v", limit.Example)
+	}
+
+	status := op.Parameters.GetByInAndName("query", "status")
+	if status == nil || !status.Schema.Value.Type.Is("array") {
+		t.Fatalf("expected array schema for slice parameter")
+	}
+}
+
+func TestBuilderExcludesParameterFieldsFromBody(t *testing.T) {
+	ref := &apix.RouteRef{
+		Method:      apix.MethodPut,
+		Path:        "/widgets/{id}",
+		OperationID: "updateWidget",
+		RequestType: reflect.TypeOf(updateWidgetRequest{}),
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {ModelType: reflect.TypeOf(updateWidgetRequest{})},
+		},
+	}
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	op := doc.Paths.Value("/widgets/{id}").Put
+	if op.RequestBody == nil {
+		t.Fatalf("expected request body")
+	}
+	schema := doc.Components.Schemas["openapi_test_updateWidgetRequest"].Value
+	if _, ok := schema.Properties["ID"]; ok {
+		t.Fatalf("path field must not appear in body schema")
+	}
+	if _, ok := schema.Properties["name"]; !ok {
+		t.Fatalf("expected body field in schema")
+	}
+	if op.Parameters.GetByInAndName("path", "id") == nil {
+		t.Fatalf("expected path parameter")
+	}
+}
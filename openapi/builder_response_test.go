@@ -0,0 +1,76 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+func TestBuilderDocumentsSuccessHeaders(t *testing.T) {
+	ref := &apix.RouteRef{
+		Method:        apix.MethodPut,
+		Path:          "/documents/{id}",
+		SuccessStatus: http.StatusOK,
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {
+				ModelType: reflect.TypeOf(responseModel{}),
+				Headers:   []apix.HeaderRef{{Name: "ETag", Description: "Response-level"}},
+			},
+		},
+	}
+	apix.WithSuccessHeaders(http.StatusOK,
+		apix.HeaderRef{Name: "ETag", Description: "Version of the stored document", Required: true},
+		apix.HeaderRef{Name: "X-Revision", SchemaType: "integer"},
+	)(ref)
+	apix.WithSuccessHeaders(http.StatusCreated, apix.HeaderRef{Name: "Location", Required: true})(ref)
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	op := doc.Paths.Value("/documents/{id}").Put
+
+	ok := op.Responses.Status(http.StatusOK).Value
+	etag := ok.Headers["ETag"]
+	if etag == nil || etag.Value.Description != "Version of the stored document" || !etag.Value.Required {
+		t.Fatalf("expected route success header to win, got %+v", etag)
+	}
+	revision := ok.Headers["X-Revision"]
+	if revision == nil || !revision.Value.Schema.Value.Type.Is("integer") {
+		t.Fatalf("expected X-Revision integer header, got %+v", revision)
+	}
+
+	created := op.Responses.Status(http.StatusCreated)
+	if created == nil || created.Value.Headers["Location"] == nil {
+		t.Fatalf("expected headers for an undeclared status to add the response")
+	}
+	if len(created.Value.Content) != 0 {
+		t.Fatalf("expected no body on header-only response")
+	}
+}
+
+func TestBuilderDocumentsBinaryResponses(t *testing.T) {
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/exports/users", SuccessStatus: http.StatusOK}
+	apix.WithResponseContentType("text/csv")(ref)
+	apix.EnsureSuccessResponses(ref, apix.ResponseBodyType(reflect.TypeOf(apix.Response[apix.File]{})))
+
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	resp := doc.Paths.Value("/exports/users").Get.Responses.Status(http.StatusOK).Value
+	media := resp.Content.Get("text/csv")
+	if media == nil || len(resp.Content) != 1 {
+		t.Fatalf("expected only text/csv content, got %v", resp.Content)
+	}
+	schema := media.Schema.Value
+	if !schema.Type.Is("string") || schema.Format != "binary" {
+		t.Fatalf("expected string/binary schema, got %+v", schema)
+	}
+	if _, ok := doc.Components.Schemas["File"]; ok {
+		t.Fatalf("File must not become a component")
+	}
+}
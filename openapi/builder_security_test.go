@@ -0,0 +1,156 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func securedRoute(path string, opts ...apix.RouteOption) *apix.RouteRef {
+	ref := &apix.RouteRef{
+		Method:      apix.MethodGet,
+		Path:        path,
+		OperationID: apix.DefaultOperationID(apix.MethodGet, path),
+		Responses:   map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(capabilityResponse{})}},
+	}
+	for _, opt := range opts {
+		opt(ref)
+	}
+	return ref
+}
+
+func TestBuilderDocumentsSecuritySchemes(t *testing.T) {
+	oauth := apix.OAuth2("OAuth", apix.OAuthFlows{
+		AuthorizationCode: &apix.OAuthFlow{
+			AuthorizationURL: "https://auth.example.com/authorize",
+			TokenURL:         "https://auth.example.com/token",
+			Scopes:           map[string]string{"items:read": "Read items"},
+		},
+	}, nil)
+	reg := apix.NewRegistry()
+	reg.RegisterSecurityScheme(apix.APIKeyHeader("ApiKey", "X-API-Key", nil).Describe("Partner key"))
+	reg.RegisterSecurityScheme(apix.OpenIDConnect("OIDC", "https://auth.example.com/.well-known/openid-configuration", nil))
+	reg.Register(securedRoute("/items", oauth.Require("items:read")))
+	reg.Register(securedRoute("/me", apix.HTTPBearer("BearerAuth", "JWT", nil).Require()))
+
+	doc, err := openapi.NewBuilder().BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	schemes := doc.Components.SecuritySchemes
+	if len(schemes) != 4 {
+		t.Fatalf("expected 4 security schemes, got %v", schemes)
+	}
+	for name, scheme := range schemes {
+		if err := scheme.Value.Validate(t.Context()); err != nil {
+			t.Fatalf("validate %s: %v", name, err)
+		}
+	}
+	if s := schemes["BearerAuth"].Value; s.Type != "http" || s.Scheme != "bearer" || s.BearerFormat != "JWT" {
+		t.Fatalf("unexpected bearer scheme %+v", s)
+	}
+	if s := schemes["ApiKey"].Value; s.Type != "apiKey" || s.In != "header" || s.Name != "X-API-Key" || s.Description != "Partner key" {
+		t.Fatalf("unexpected API key scheme %+v", s)
+	}
+	if s := schemes["OIDC"].Value; s.Type != "openIdConnect" || !strings.HasSuffix(s.OpenIdConnectUrl, "openid-configuration") {
+		t.Fatalf("unexpected OpenID Connect scheme %+v", s)
+	}
+	flow := schemes["OAuth"].Value.Flows.AuthorizationCode
+	if flow == nil || flow.TokenURL != "https://auth.example.com/token" || flow.Scopes["items:read"] != "Read items" {
+		t.Fatalf("unexpected OAuth2 flow %+v", flow)
+	}
+
+	op := doc.Paths.Value("/items").Get
+	if op.Security == nil || len(*op.Security) != 1 || (*op.Security)[0]["OAuth"][0] != "items:read" {
+		t.Fatalf("unexpected operation security %v", op.Security)
+	}
+}
+
+func TestBuilderSecuritySchemesOverrideAttachedSchemes(t *testing.T) {
+	reg := apix.NewRegistry()
+	reg.Register(securedRoute("/me", apix.HTTPBearer("BearerAuth", "JWT", nil).Require()))
+
+	b := openapi.NewBuilder()
+	custom := openapi3.NewJWTSecurityScheme().WithDescription("Custom")
+	b.SecuritySchemes = openapi3.SecuritySchemes{"BearerAuth": {Value: custom}}
+	doc, err := b.BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if doc.Components.SecuritySchemes["BearerAuth"].Value != custom {
+		t.Fatalf("expected builder scheme to win")
+	}
+	if len(b.SecuritySchemes) != 1 {
+		t.Fatalf("expected builder schemes to stay untouched, got %v", b.SecuritySchemes)
+	}
+}
+
+func TestBuilderRejectsConflictingSecuritySchemes(t *testing.T) {
+	reg := apix.NewRegistry()
+	reg.RegisterSecurityScheme(apix.HTTPBearer("Auth", "JWT", nil))
+	reg.Register(securedRoute("/me", apix.HTTPBearer("Auth", "JWT", nil).Require()))
+	if _, err := openapi.NewBuilder().BuildRegistry(reg); err != nil {
+		t.Fatalf("expected identical definitions to share a name, got %v", err)
+	}
+
+	reg.Register(securedRoute("/keys", apix.APIKeyHeader("Auth", "X-API-Key", nil).Require()))
+	if _, err := openapi.NewBuilder().BuildRegistry(reg); err == nil || !strings.Contains(err.Error(), `"Auth"`) {
+		t.Fatalf("expected conflicting definitions to fail the build, got %v", err)
+	}
+}
+
+func TestBuilderMutualTLSRequiresOpenAPI31(t *testing.T) {
+	reg := apix.NewRegistry()
+	reg.Register(securedRoute("/internal", apix.MutualTLS("ClientCert", nil).Require()))
+
+	doc, err := openapi.NewBuilder().BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if s := doc.Components.SecuritySchemes["ClientCert"].Value; s.Type != "mutualTLS" {
+		t.Fatalf("unexpected mutual TLS scheme %+v", s)
+	}
+
+	b := openapi.NewBuilder()
+	b.OpenAPIVersion = openapi.OpenAPIVersion30
+	if _, err := b.BuildRegistry(reg); err == nil || !strings.Contains(err.Error(), "mutualTLS") {
+		t.Fatalf("expected OpenAPI 3.0 build to fail, got %v", err)
+	}
+}
+
+func TestBuilderCombinesMiddlewareSecurityWithEachAlternative(t *testing.T) {
+	session := apix.MiddlewareSecurity(apix.SecuredBy(func() {}, "SessionCookie"))
+	jwt := apix.MiddlewareSecurity(apix.SecuredBy(func() {}, "BearerAuth", "admin"))
+	reg := apix.NewRegistry()
+	reg.Register(securedRoute("/stacked", session, jwt))
+	reg.Register(securedRoute("/declared", session, apix.WithSecurity("ApiKey"), apix.WithSecurity("BearerAuth", "read")))
+
+	doc, err := openapi.NewBuilder().BuildRegistry(reg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	stacked := *doc.Paths.Value("/stacked").Get.Security
+	want := openapi3.SecurityRequirements{{"SessionCookie": nil, "BearerAuth": {"admin"}}}
+	if !reflect.DeepEqual(stacked, want) {
+		t.Fatalf("expected one requirement with both schemes, got %v", stacked)
+	}
+
+	declared := *doc.Paths.Value("/declared").Get.Security
+	want = openapi3.SecurityRequirements{
+		{"ApiKey": nil, "SessionCookie": nil},
+		{"BearerAuth": {"read"}, "SessionCookie": nil},
+	}
+	if !reflect.DeepEqual(declared, want) {
+		t.Fatalf("expected middleware security in every alternative, got %v", declared)
+	}
+	if doc.Paths.Value("/stacked").Get.Responses.Status(http.StatusUnauthorized) == nil {
+		t.Fatalf("expected 401 response on middleware-secured route")
+	}
+}
@@ -0,0 +1,137 @@
+package openapi_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type connection struct {
+	Timeout   time.Duration         `json:"timeout" validate:"min=1"`
+	Retries   []time.Duration       `json:"retries"`
+	Metadata  json.RawMessage       `json:"metadata"`
+	Extra     *json.RawMessage      `json:"extra,omitempty"`
+	Address   net.IP                `json:"address"`
+	Peers     []net.IP              `json:"peers"`
+	Gateway   netip.Addr            `json:"gateway"`
+	Endpoint  url.URL               `json:"endpoint"`
+	Callback  *url.URL              `json:"callback,omitempty"`
+	Label     sql.NullString        `json:"label"`
+	Port      sql.NullInt32         `json:"port"`
+	Weight    sql.NullFloat64       `json:"weight"`
+	Enabled   sql.NullBool          `json:"enabled"`
+	Closed    sql.NullTime          `json:"closed"`
+	Owner     sql.Null[string]      `json:"owner"`
+	Fallbacks []sql.NullString      `json:"fallbacks"`
+	Limits    map[string]*net.IP    `json:"limits,omitempty"`
+	History   *sql.Null[connection] `json:"history,omitempty"`
+}
+
+func connectionSchema(t *testing.T) *openapi3.Schema {
+	t.Helper()
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:    apix.MethodGet,
+		Path:      "/connection",
+		Responses: map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(connection{})}},
+	})
+	doc, err := openapi.NewBuilder().BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	component := doc.Components.Schemas["openapi_test_connection"]
+	if component == nil || component.Value == nil {
+		t.Fatalf("connection component missing")
+	}
+	return component.Value
+}
+
+func TestStdlibDurationAndRawMessage(t *testing.T) {
+	s := connectionSchema(t)
+
+	// encoding/json writes durations as nanosecond counts, and validation compares them as such.
+	timeout := s.Properties["timeout"].Value
+	if !timeout.Type.Is(openapi3.TypeInteger) || timeout.Format != "int64" || timeout.Min == nil || *timeout.Min != 1 {
+		t.Fatalf("expected int64 nanoseconds with a minimum, got %+v", timeout)
+	}
+	if items := s.Properties["retries"].Value.Items.Value; !items.Type.Is(openapi3.TypeInteger) || items.Format != "int64" {
+		t.Fatalf("expected slice of durations, got %+v", items)
+	}
+
+	metadata := s.Properties["metadata"].Value
+	if metadata.Type != nil || metadata.Format != "" {
+		t.Fatalf("expected any-value schema for json.RawMessage, got %+v", metadata)
+	}
+	if extra := s.Properties["extra"].Value; extra.Type != nil || !extra.Nullable {
+		t.Fatalf("expected nullable any-value schema, got %+v", extra)
+	}
+}
+
+func TestStdlibNetworkTypes(t *testing.T) {
+	s := connectionSchema(t)
+
+	for _, name := range []string{"address", "gateway"} {
+		ip := s.Properties[name].Value
+		if !ip.Type.Is(openapi3.TypeString) || len(ip.AnyOf) != 2 || ip.AnyOf[0].Value.Format != "ipv4" || ip.AnyOf[1].Value.Format != "ipv6" {
+			t.Fatalf("%s: expected ipv4/ipv6 string, got %+v", name, ip)
+		}
+	}
+	if peers := s.Properties["peers"].Value.Items.Value; len(peers.AnyOf) != 2 {
+		t.Fatalf("expected slice of IP strings, got %+v", peers)
+	}
+	if limit := s.Properties["limits"].Value.AdditionalProperties.Schema.Value; !limit.Nullable || len(limit.AnyOf) != 2 {
+		t.Fatalf("expected nullable IP map values, got %+v", limit)
+	}
+
+	// url.URL has no JSON marshaler, so it is written as its exported fields.
+	endpoint := s.Properties["endpoint"].Value
+	if !endpoint.Type.Is(openapi3.TypeObject) || endpoint.Properties["Host"] == nil || endpoint.Properties["RawQuery"] == nil {
+		t.Fatalf("expected url.URL object, got %+v", endpoint)
+	}
+	if callback := s.Properties["callback"].Value; !callback.Nullable {
+		t.Fatalf("expected nullable url.URL, got %+v", callback)
+	}
+}
+
+func TestStdlibSQLNullTypes(t *testing.T) {
+	s := connectionSchema(t)
+
+	// The sql.Null* types have no JSON marshaler: encoding/json writes the value and Valid.
+	cases := map[string]struct{ field, typ, format string }{
+		"label":   {"String", openapi3.TypeString, ""},
+		"port":    {"Int32", openapi3.TypeInteger, "int32"},
+		"weight":  {"Float64", openapi3.TypeNumber, ""},
+		"enabled": {"Bool", openapi3.TypeBoolean, ""},
+		"closed":  {"Time", openapi3.TypeString, "date-time"},
+		"owner":   {"V", openapi3.TypeString, ""},
+	}
+	for name, want := range cases {
+		got := s.Properties[name].Value
+		if !got.Type.Is(openapi3.TypeObject) || got.Nullable {
+			t.Fatalf("%s: expected non-nullable object, got %+v", name, got)
+		}
+		value := got.Properties[want.field]
+		if value == nil || !value.Value.Type.Is(want.typ) || value.Value.Format != want.format {
+			t.Fatalf("%s: expected %s %s/%s, got %+v", name, want.field, want.typ, want.format, got.Properties)
+		}
+		if valid := got.Properties["Valid"]; valid == nil || !valid.Value.Type.Is(openapi3.TypeBoolean) {
+			t.Fatalf("%s: expected Valid flag, got %+v", name, got.Properties)
+		}
+	}
+	if items := s.Properties["fallbacks"].Value.Items; items.Ref != "#/components/schemas/sql_NullString" {
+		t.Fatalf("expected slice of sql.NullString objects, got %+v", items)
+	}
+	if history := s.Properties["history"].Value; !history.Nullable || history.Properties["V"] == nil {
+		t.Fatalf("expected nullable sql.Null of a struct, got %+v", history)
+	}
+}
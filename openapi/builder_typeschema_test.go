@@ -0,0 +1,151 @@
+package openapi_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type amount struct {
+	units    int64
+	currency string
+}
+
+type objectID [4]byte
+
+func (id objectID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+type rawPayload struct {
+	data []byte
+}
+
+func (p rawPayload) MarshalJSON() ([]byte, error) {
+	return p.data, nil
+}
+
+type ledgerEntry struct {
+	Amount   amount            `json:"amount"`
+	Refund   *amount           `json:"refund,omitempty"`
+	ID       objectID          `json:"id"`
+	Related  []objectID        `json:"related"`
+	Balances map[objectID]int  `json:"balances"`
+	Payload  rawPayload        `json:"payload"`
+	Notes    map[string]string `json:"notes,omitempty"`
+}
+
+func moneySchema() *openapi3.Schema {
+	s := openapi3.NewStringSchema()
+	s.Format = "money"
+	s.Pattern = `^-?\d+(\.\d+)? [A-Z]{3}$`
+	return s
+}
+
+func buildLedger(t *testing.T, customize func(*openapi.Builder)) *openapi3.Schema {
+	t.Helper()
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:    apix.MethodGet,
+		Path:      "/ledger",
+		Responses: map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(ledgerEntry{})}},
+	})
+	b := openapi.NewBuilder()
+	if customize != nil {
+		customize(b)
+	}
+	doc, err := b.BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	component := doc.Components.Schemas["openapi_test_ledgerEntry"]
+	if component == nil || component.Value == nil {
+		t.Fatalf("ledger component missing")
+	}
+	return component.Value
+}
+
+func TestRegisterTypeSchemaReplacesReflection(t *testing.T) {
+	openapi.RegisterTypeSchema(reflect.TypeOf(amount{}), moneySchema)
+	t.Cleanup(func() { openapi.UnregisterTypeSchema(reflect.TypeOf(amount{})) })
+
+	entry := buildLedger(t, nil)
+	got := entry.Properties["amount"].Value
+	if !got.Type.Is(openapi3.TypeString) || got.Format != "money" {
+		t.Fatalf("expected registered money schema, got %+v", got)
+	}
+	refund := entry.Properties["refund"].Value
+	if !refund.Nullable || refund.Format != "money" {
+		t.Fatalf("expected nullable money schema for pointer, got %+v", refund)
+	}
+	if _, ok := entry.Properties["refund"].Value.Properties["units"]; ok {
+		t.Fatalf("struct fields must not leak into registered schema")
+	}
+
+	got.Description = "changed"
+	if again := buildLedger(t, nil).Properties["amount"].Value; again.Description != "" {
+		t.Fatalf("schema functions must be called per use")
+	}
+}
+
+func TestBuilderTypeSchemaTakesPrecedence(t *testing.T) {
+	openapi.RegisterTypeSchema(reflect.TypeOf(amount{}), moneySchema)
+	t.Cleanup(func() { openapi.UnregisterTypeSchema(reflect.TypeOf(amount{})) })
+
+	entry := buildLedger(t, func(b *openapi.Builder) {
+		b.RegisterTypeSchema(reflect.TypeOf(amount{}), openapi3.NewInt64Schema)
+	})
+	if got := entry.Properties["amount"].Value; !got.Type.Is(openapi3.TypeInteger) {
+		t.Fatalf("expected builder registration to win, got %+v", got)
+	}
+}
+
+func TestTextMarshalersAreStrings(t *testing.T) {
+	entry := buildLedger(t, nil)
+	if got := entry.Properties["id"].Value; !got.Type.Is(openapi3.TypeString) {
+		t.Fatalf("expected text marshaler to be a string, got %+v", got)
+	}
+	if items := entry.Properties["related"].Value.Items.Value; !items.Type.Is(openapi3.TypeString) {
+		t.Fatalf("expected slice of text marshalers to hold strings, got %+v", items)
+	}
+	if values := entry.Properties["balances"].Value.AdditionalProperties.Schema; values == nil || !values.Value.Type.Is(openapi3.TypeInteger) {
+		t.Fatalf("expected text marshaler map keys to be accepted")
+	}
+
+	entry = buildLedger(t, func(b *openapi.Builder) {
+		b.RegisterTypeSchema(reflect.TypeOf(objectID{}), func() *openapi3.Schema {
+			s := openapi3.NewStringSchema()
+			s.Format = "object-id"
+			return s
+		})
+	})
+	if got := entry.Properties["id"].Value; got.Format != "object-id" {
+		t.Fatalf("expected registration to override text marshaler detection, got %+v", got)
+	}
+}
+
+func TestInterfaceTypeSchemaAppliesToImplementers(t *testing.T) {
+	entry := buildLedger(t, nil)
+	if got := entry.Properties["payload"].Value; !got.Type.Is(openapi3.TypeObject) {
+		t.Fatalf("expected json marshalers to keep their reflected schema by default, got %+v", got)
+	}
+
+	entry = buildLedger(t, func(b *openapi.Builder) {
+		b.RegisterTypeSchema(reflect.TypeFor[json.Marshaler](), func() *openapi3.Schema {
+			return &openapi3.Schema{Description: "Any JSON value"}
+		})
+	})
+	got := entry.Properties["payload"].Value
+	if got.Type != nil || got.Description != "Any JSON value" {
+		t.Fatalf("expected json.Marshaler registration to apply, got %+v", got)
+	}
+	if notes := entry.Properties["notes"].Value; !notes.Type.Is(openapi3.TypeObject) {
+		t.Fatalf("types without the interface must be unaffected, got %+v", notes)
+	}
+}
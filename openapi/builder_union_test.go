@@ -0,0 +1,102 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type paymentMethod interface{ isPaymentMethod() }
+
+type cardPayment struct {
+	Type   string `json:"type"`
+	Last4  string `json:"last4"`
+	Expiry string `json:"expiry"`
+}
+
+func (cardPayment) isPaymentMethod() {}
+
+type bankPayment struct {
+	Type string `json:"type"`
+	IBAN string `json:"iban"`
+}
+
+func (*bankPayment) isPaymentMethod() {}
+
+type checkoutRequest struct {
+	Method   paymentMethod   `json:"method"`
+	Fallback []paymentMethod `json:"fallback"`
+}
+
+func buildCheckout(t *testing.T) *openapi3.T {
+	t.Helper()
+	ref := &apix.RouteRef{
+		Method:             apix.MethodPost,
+		Path:               "/checkout",
+		OperationID:        "checkout",
+		RequestType:        reflect.TypeOf(checkoutRequest{}),
+		RequestContentType: "application/json",
+		Responses:          map[int]*apix.ResponseRef{http.StatusNoContent: {}},
+	}
+	doc, err := openapi.NewBuilder().Build([]*apix.RouteRef{ref})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return doc
+}
+
+func TestBuilderDocumentsUnionsWithDiscriminator(t *testing.T) {
+	apix.RegisterUnion[paymentMethod](apix.Variant(cardPayment{}, "card"), apix.Variant(&bankPayment{}, "bank"), apix.Discriminator("type"))
+	defer apix.UnregisterUnion[paymentMethod]()
+
+	doc := buildCheckout(t)
+
+	union := doc.Components.Schemas["openapi_test_paymentMethod"]
+	if union == nil {
+		t.Fatalf("expected union component, got %v", doc.Components.Schemas)
+	}
+	if len(union.Value.OneOf) != 2 || union.Value.OneOf[0].Ref != "#/components/schemas/openapi_test_cardPayment" || union.Value.OneOf[1].Ref != "#/components/schemas/openapi_test_bankPayment" {
+		t.Fatalf("unexpected oneOf %+v", union.Value.OneOf)
+	}
+	want := map[string]string{
+		"card": "#/components/schemas/openapi_test_cardPayment",
+		"bank": "#/components/schemas/openapi_test_bankPayment",
+	}
+	if d := union.Value.Discriminator; d == nil || d.PropertyName != "type" || !reflect.DeepEqual(d.Mapping, want) {
+		t.Fatalf("unexpected discriminator %+v", union.Value.Discriminator)
+	}
+	for _, variant := range []string{"openapi_test_cardPayment", "openapi_test_bankPayment"} {
+		if doc.Components.Schemas[variant] == nil {
+			t.Fatalf("expected variant component %s", variant)
+		}
+	}
+
+	props := doc.Components.Schemas["openapi_test_checkoutRequest"].Value.Properties
+	if props["method"].Ref != "#/components/schemas/openapi_test_paymentMethod" {
+		t.Fatalf("expected method to reference the union, got %+v", props["method"])
+	}
+	if props["fallback"].Value.Items.Ref != "#/components/schemas/openapi_test_paymentMethod" {
+		t.Fatalf("expected fallback items to reference the union, got %+v", props["fallback"].Value.Items)
+	}
+}
+
+func TestBuilderDocumentsAnyOfUnions(t *testing.T) {
+	apix.RegisterUnion[paymentMethod](cardPayment{}, &bankPayment{}, apix.AnyOf())
+	defer apix.UnregisterUnion[paymentMethod]()
+
+	union := buildCheckout(t).Components.Schemas["openapi_test_paymentMethod"].Value
+	if len(union.AnyOf) != 2 || len(union.OneOf) != 0 || union.Discriminator != nil {
+		t.Fatalf("unexpected anyOf union %+v", union)
+	}
+}
+
+func TestBuilderKeepsUnregisteredInterfacesOpen(t *testing.T) {
+	props := buildCheckout(t).Components.Schemas["openapi_test_checkoutRequest"].Value.Properties
+	if !props["method"].Value.Type.Is("object") || len(props["method"].Value.OneOf) != 0 {
+		t.Fatalf("expected plain object schema, got %+v", props["method"].Value)
+	}
+}
@@ -0,0 +1,152 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+type accountState string
+
+func (accountState) EnumValues() []any { return []any{"active", "closed"} }
+
+type accountOwner struct {
+	Name string `json:"name"`
+}
+
+type account struct {
+	ID       string        `json:"id" example:"acc-1"`
+	Nickname *string       `json:"nickname,omitempty"`
+	State    accountState  `json:"state" description:"Lifecycle state"`
+	Previous *accountState `json:"previous,omitempty"`
+	Owner    *accountOwner `json:"owner,omitempty"`
+	Manager  accountOwner  `json:"manager" description:"Account manager"`
+	Balance  float64       `json:"balance" validate:"gt=0,lt=1000000"`
+	Kind     string        `json:"kind" validate:"oneof=personal"`
+}
+
+func versionedRegistry() *apix.Registry {
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:      apix.MethodGet,
+		Path:        "/accounts/:id",
+		OperationID: "getAccount",
+		Parameters: []apix.Parameter{
+			{Name: "id", In: "path", Required: true, SchemaType: "string"},
+		},
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {ModelType: reflect.TypeOf(account{})},
+		},
+	})
+	return registry
+}
+
+func buildVersioned(t *testing.T, version string) []byte {
+	t.Helper()
+	b := openapi.NewBuilder()
+	b.OpenAPIVersion = version
+	doc, err := b.BuildRegistry(versionedRegistry())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	data, _, err := openapi.EncodeDocument(doc, "json")
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	return data
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run go test -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s is out of date; run go test ./openapi -run Golden -update\ngot:\n%s", name, got)
+	}
+}
+
+func TestGoldenOpenAPI31(t *testing.T) {
+	data := buildVersioned(t, openapi.OpenAPIVersion31)
+	assertGolden(t, "account_3.1.golden.json", data)
+
+	for _, fragment := range []string{`"nullable"`, `"example"`, `"exclusiveMinimum": true`} {
+		if strings.Contains(string(data), fragment) {
+			t.Fatalf("3.1 document must not contain %s", fragment)
+		}
+	}
+}
+
+func TestGoldenOpenAPI30(t *testing.T) {
+	data := buildVersioned(t, openapi.OpenAPIVersion30)
+	assertGolden(t, "account_3.0.golden.json", data)
+
+	doc, err := openapi.DecodeDocument(data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("3.0 document is invalid: %v", err)
+	}
+	for _, fragment := range []string{`"const"`, `"examples"`, `"null"`} {
+		if strings.Contains(string(data), fragment) {
+			t.Fatalf("3.0 document must not contain %s", fragment)
+		}
+	}
+}
+
+func TestDecodeDocumentRoundTripsOpenAPI31(t *testing.T) {
+	data := buildVersioned(t, openapi.OpenAPIVersion31)
+	doc, err := openapi.DecodeDocument(data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("decoded document is invalid: %v", err)
+	}
+	again, _, err := openapi.EncodeDocument(doc, "json")
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("round trip changed the document:\n%s", again)
+	}
+
+	yamlData, _, err := openapi.EncodeDocument(doc, "yaml")
+	if err != nil {
+		t.Fatalf("encode yaml failed: %v", err)
+	}
+	fromYAML, err := openapi.DecodeDocument(yamlData)
+	if err != nil {
+		t.Fatalf("decode yaml failed: %v", err)
+	}
+	if report := openapi.Diff(doc, fromYAML); len(report.Changes) != 0 {
+		t.Fatalf("yaml round trip changed the document: %+v", report.Changes)
+	}
+}
+
+func TestBuilderRejectsUnknownOpenAPIVersion(t *testing.T) {
+	b := openapi.NewBuilder()
+	b.OpenAPIVersion = "2.0"
+	if _, err := b.BuildRegistry(versionedRegistry()); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+}
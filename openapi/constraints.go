@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/Infra-Forge/infra-apix/internal/validation"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type constraintTarget int
+
+const (
+	targetNone constraintTarget = iota
+	targetString
+	targetNumber
+	targetArray
+	targetObject
+)
+
+// applyValidationRules copies validate/binding rules onto an inline field schema so the
+// document advertises the same limits the server enforces. Component schemas are shared
+// between fields and are never modified.
+func applyValidationRules(ref *openapi3.SchemaRef, t reflect.Type, rules validation.Rules) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil || t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := ref.Value
+	target := constraintTargetFor(schema, t)
+	if target == targetNone {
+		return
+	}
+
+	for _, rule := range rules.Field {
+		switch rule.Name {
+		case "min", "gte":
+			setLowerBound(schema, target, rule.Param, false)
+		case "max", "lte":
+			setUpperBound(schema, target, rule.Param, false)
+		case "gt":
+			setLowerBound(schema, target, rule.Param, true)
+		case "lt":
+			setUpperBound(schema, target, rule.Param, true)
+		case "len":
+			setLowerBound(schema, target, rule.Param, false)
+			setUpperBound(schema, target, rule.Param, false)
+		case "oneof":
+			if target == targetString || target == targetNumber {
+				schema.Enum = nil
+				for _, v := range validation.OneOfValues(rule.Param) {
+					schema.Enum = append(schema.Enum, parseExampleValue(v, t))
+				}
+			}
+		case "unique":
+			if target == targetArray {
+				schema.UniqueItems = true
+			}
+		case "startswith", "endswith", "contains":
+			if target == targetString && schema.Pattern == "" {
+				schema.Pattern = affixPattern(rule.Name, rule.Param)
+			}
+		default:
+			if target != targetString {
+				continue
+			}
+			if format, ok := validation.Formats[rule.Name]; ok {
+				schema.Format = format
+			} else if pattern, ok := validation.Patterns[rule.Name]; ok && schema.Pattern == "" {
+				schema.Pattern = pattern
+			}
+		}
+	}
+
+	if rules.Items == nil {
+		return
+	}
+	switch target {
+	case targetArray:
+		applyValidationRules(schema.Items, t.Elem(), *rules.Items)
+	case targetObject:
+		if t.Kind() == reflect.Map {
+			applyValidationRules(schema.AdditionalProperties.Schema, t.Elem(), *rules.Items)
+		}
+	}
+}
+
+func constraintTargetFor(schema *openapi3.Schema, t reflect.Type) constraintTarget {
+	// time.Time, net.IP, uuid.UUID and decimal.Decimal render as strings but are validated
+	// as values.
+	if t.Kind() == reflect.Struct || schema.Type == nil || t == ipType {
+		return targetNone
+	}
+	switch {
+	case schema.Type.Is(openapi3.TypeString):
+		if schema.Format == "byte" || (schema.Format == "uuid" && t.Kind() != reflect.String) {
+			return targetNone
+		}
+		return targetString
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		return targetNumber
+	case schema.Type.Is(openapi3.TypeArray):
+		return targetArray
+	case schema.Type.Is(openapi3.TypeObject):
+		if t.Kind() == reflect.Map {
+			return targetObject
+		}
+	}
+	return targetNone
+}
+
+func setLowerBound(schema *openapi3.Schema, target constraintTarget, param string, exclusive bool) {
+	if target == targetNumber {
+		if v, err := strconv.ParseFloat(param, 64); err == nil {
+			schema.Min = &v
+			schema.ExclusiveMin = exclusive
+		}
+		return
+	}
+	n, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return
+	}
+	if exclusive {
+		n++
+	}
+	switch target {
+	case targetString:
+		schema.MinLength = n
+	case targetArray:
+		schema.MinItems = n
+	case targetObject:
+		schema.MinProps = n
+	}
+}
+
+func setUpperBound(schema *openapi3.Schema, target constraintTarget, param string, exclusive bool) {
+	if target == targetNumber {
+		if v, err := strconv.ParseFloat(param, 64); err == nil {
+			schema.Max = &v
+			schema.ExclusiveMax = exclusive
+		}
+		return
+	}
+	n, err := strconv.ParseUint(param, 10, 64)
+	if err != nil || (exclusive && n == 0) {
+		return
+	}
+	if exclusive {
+		n--
+	}
+	switch target {
+	case targetString:
+		schema.MaxLength = &n
+	case targetArray:
+		schema.MaxItems = &n
+	case targetObject:
+		schema.MaxProps = &n
+	}
+}
+
+func affixPattern(rule, param string) string {
+	quoted := regexp.QuoteMeta(param)
+	switch rule {
+	case "startswith":
+		return "^" + quoted
+	case "endswith":
+		return quoted + "$"
+	default:
+		return quoted
+	}
+}
@@ -0,0 +1,592 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChangeSeverity classifies the compatibility impact of a change.
+type ChangeSeverity string
+
+const (
+	// SeverityBreaking marks changes that can break existing clients.
+	SeverityBreaking ChangeSeverity = "breaking"
+	// SeverityNonBreaking marks additive or otherwise compatible changes.
+	SeverityNonBreaking ChangeSeverity = "non-breaking"
+)
+
+// Change kinds reported by Diff.
+const (
+	ChangeOperationRemoved        = "operation-removed"
+	ChangeOperationAdded          = "operation-added"
+	ChangeParameterAdded          = "parameter-added"
+	ChangeParameterRemoved        = "parameter-removed"
+	ChangeParameterRequired       = "parameter-became-required"
+	ChangeParameterTypeChanged    = "parameter-type-changed"
+	ChangeRequestBodyRequired     = "request-body-became-required"
+	ChangeRequestFieldAdded       = "request-field-added"
+	ChangeRequestFieldRequired    = "request-field-became-required"
+	ChangeRequestFieldRemoved     = "request-field-removed"
+	ChangeResponseRemoved         = "response-removed"
+	ChangeResponseAdded           = "response-added"
+	ChangeResponseFieldAdded      = "response-field-added"
+	ChangeResponseFieldRemoved    = "response-field-removed"
+	ChangeResponseFieldRenamed    = "response-field-renamed"
+	ChangeResponseFieldOptional   = "response-field-became-optional"
+	ChangeTypeChanged             = "type-changed"
+	ChangeEnumNarrowed            = "enum-narrowed"
+	ChangeEnumWidened             = "enum-widened"
+	ChangeBecameNullable          = "became-nullable"
+	ChangeBecameNonNullable       = "became-non-nullable"
+	ChangeVariantAdded            = "variant-added"
+	ChangeVariantRemoved          = "variant-removed"
+	ChangeMediaTypeRemoved        = "media-type-removed"
+	ChangeRequestMediaTypeRemoved = "request-media-type-removed"
+)
+
+// Change describes one difference between two documents.
+type Change struct {
+	Severity  ChangeSeverity `json:"severity"`
+	Kind      string         `json:"kind"`
+	Operation string         `json:"operation"`
+	Location  string         `json:"location,omitempty"`
+	Message   string         `json:"message"`
+}
+
+// DiffReport lists the changes between a base and a head document in a stable order.
+type DiffReport struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns only the breaking changes.
+func (r *DiffReport) Breaking() []Change {
+	var out []Change
+	for _, c := range r.Changes {
+		if c.Severity == SeverityBreaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// HasBreaking reports whether any change is breaking.
+func (r *DiffReport) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// WriteText writes a human-readable report.
+func (r *DiffReport) WriteText(w io.Writer) error {
+	breaking := 0
+	for _, c := range r.Changes {
+		label := "non-breaking"
+		if c.Severity == SeverityBreaking {
+			label = "BREAKING"
+			breaking++
+		}
+		line := fmt.Sprintf("%-12s %s", label, c.Operation)
+		if c.Location != "" {
+			line += " " + c.Location
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", line, c.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d breaking, %d non-breaking change(s)\n", breaking, len(r.Changes)-breaking)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *DiffReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Diff compares two OpenAPI documents and classifies every change by its impact on existing clients.
+// References must already be resolved (as done by openapi3.Loader).
+func Diff(base, head *openapi3.T) *DiffReport {
+	d := &differ{visited: map[[2]*openapi3.Schema]bool{}}
+	baseOps := collectOperations(base)
+	headOps := collectOperations(head)
+
+	for _, key := range sortedOperationKeys(baseOps) {
+		baseOp := baseOps[key]
+		headOp, ok := headOps[key]
+		if !ok {
+			d.add(SeverityBreaking, ChangeOperationRemoved, key, "", "operation removed")
+			continue
+		}
+		d.compareOperation(key, baseOp, headOp)
+	}
+	for _, key := range sortedOperationKeys(headOps) {
+		if _, ok := baseOps[key]; !ok {
+			d.add(SeverityNonBreaking, ChangeOperationAdded, key, "", "operation added")
+		}
+	}
+
+	return &DiffReport{Changes: d.changes}
+}
+
+type schemaDirection int
+
+const (
+	directionRequest schemaDirection = iota
+	directionResponse
+)
+
+type differ struct {
+	changes []Change
+	visited map[[2]*openapi3.Schema]bool
+}
+
+func (d *differ) add(severity ChangeSeverity, kind, op, location, message string) {
+	d.changes = append(d.changes, Change{Severity: severity, Kind: kind, Operation: op, Location: location, Message: message})
+}
+
+func collectOperations(doc *openapi3.T) map[string]*openapi3.Operation {
+	ops := map[string]*openapi3.Operation{}
+	if doc == nil || doc.Paths == nil {
+		return ops
+	}
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			ops[strings.ToUpper(method)+" "+path] = op
+		}
+		for method, op := range additionalOperations(item) {
+			ops[strings.ToUpper(method)+" "+path] = op
+		}
+	}
+	return ops
+}
+
+func sortedOperationKeys(ops map[string]*openapi3.Operation) []string {
+	keys := make([]string, 0, len(ops))
+	for k := range ops {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *differ) compareOperation(key string, base, head *openapi3.Operation) {
+	d.compareParameters(key, base.Parameters, head.Parameters)
+	d.compareRequestBody(key, base.RequestBody, head.RequestBody)
+	d.compareResponses(key, base.Responses, head.Responses)
+}
+
+func (d *differ) compareParameters(key string, base, head openapi3.Parameters) {
+	index := func(params openapi3.Parameters) map[string]*openapi3.Parameter {
+		out := map[string]*openapi3.Parameter{}
+		for _, p := range params {
+			if p != nil && p.Value != nil {
+				out[p.Value.In+" "+p.Value.Name] = p.Value
+			}
+		}
+		return out
+	}
+	baseParams, headParams := index(base), index(head)
+
+	for _, name := range sortedKeys(baseParams) {
+		bp := baseParams[name]
+		location := fmt.Sprintf("%s parameter %q", bp.In, bp.Name)
+		hp, ok := headParams[name]
+		if !ok {
+			d.add(SeverityNonBreaking, ChangeParameterRemoved, key, location, "parameter removed")
+			continue
+		}
+		if hp.Required && !bp.Required {
+			d.add(SeverityBreaking, ChangeParameterRequired, key, location, "parameter became required")
+		}
+		if bp.Schema != nil && hp.Schema != nil && bp.Schema.Value != nil && hp.Schema.Value != nil {
+			if bt, ht := typeSignature(bp.Schema.Value), typeSignature(hp.Schema.Value); bt != "" && ht != "" && bt != ht {
+				d.add(SeverityBreaking, ChangeParameterTypeChanged, key, location, fmt.Sprintf("type changed from %s to %s", bt, ht))
+			}
+			d.compareEnum(key, location, bp.Schema.Value, hp.Schema.Value, directionRequest)
+		}
+	}
+	for _, name := range sortedKeys(headParams) {
+		if _, ok := baseParams[name]; ok {
+			continue
+		}
+		hp := headParams[name]
+		location := fmt.Sprintf("%s parameter %q", hp.In, hp.Name)
+		if hp.Required {
+			d.add(SeverityBreaking, ChangeParameterAdded, key, location, "required parameter added")
+		} else {
+			d.add(SeverityNonBreaking, ChangeParameterAdded, key, location, "optional parameter added")
+		}
+	}
+}
+
+func (d *differ) compareRequestBody(key string, base, head *openapi3.RequestBodyRef) {
+	var bb, hb *openapi3.RequestBody
+	if base != nil {
+		bb = base.Value
+	}
+	if head != nil {
+		hb = head.Value
+	}
+	if hb == nil {
+		return
+	}
+	if bb == nil {
+		if hb.Required {
+			d.add(SeverityBreaking, ChangeRequestBodyRequired, key, "request body", "required request body added")
+		}
+		return
+	}
+	if hb.Required && !bb.Required {
+		d.add(SeverityBreaking, ChangeRequestBodyRequired, key, "request body", "request body became required")
+	}
+	for _, ct := range sortedKeys(bb.Content) {
+		hm, ok := hb.Content[ct]
+		if !ok {
+			d.add(SeverityBreaking, ChangeRequestMediaTypeRemoved, key, "request body", fmt.Sprintf("media type %s no longer accepted", ct))
+			continue
+		}
+		if bm := bb.Content[ct]; bm != nil && hm != nil {
+			d.compareSchema(key, "request body", bm.Schema, hm.Schema, directionRequest)
+		}
+	}
+}
+
+func (d *differ) compareResponses(key string, base, head *openapi3.Responses) {
+	if base == nil {
+		return
+	}
+	baseMap := base.Map()
+	var headMap map[string]*openapi3.ResponseRef
+	if head != nil {
+		headMap = head.Map()
+	}
+
+	for _, status := range sortedKeys(baseMap) {
+		location := "response " + status
+		br := baseMap[status]
+		hr, ok := headMap[status]
+		if !ok {
+			severity := SeverityNonBreaking
+			if isSuccessStatus(status) {
+				severity = SeverityBreaking
+			}
+			d.add(severity, ChangeResponseRemoved, key, location, "response removed")
+			continue
+		}
+		if br == nil || hr == nil || br.Value == nil || hr.Value == nil {
+			continue
+		}
+		for _, ct := range sortedKeys(br.Value.Content) {
+			hm, ok := hr.Value.Content[ct]
+			if !ok {
+				d.add(SeverityBreaking, ChangeMediaTypeRemoved, key, location, fmt.Sprintf("media type %s removed", ct))
+				continue
+			}
+			if bm := br.Value.Content[ct]; bm != nil && hm != nil {
+				d.compareSchema(key, location, bm.Schema, hm.Schema, directionResponse)
+			}
+		}
+	}
+	for _, status := range sortedKeys(headMap) {
+		if _, ok := baseMap[status]; !ok {
+			d.add(SeverityNonBreaking, ChangeResponseAdded, key, "response "+status, "response added")
+		}
+	}
+}
+
+func (d *differ) compareSchema(key, location string, baseRef, headRef *openapi3.SchemaRef, dir schemaDirection) {
+	if baseRef == nil || headRef == nil || baseRef.Value == nil || headRef.Value == nil {
+		return
+	}
+	base, head := baseRef.Value, headRef.Value
+	pair := [2]*openapi3.Schema{base, head}
+	if d.visited[pair] {
+		return
+	}
+	d.visited[pair] = true
+	defer delete(d.visited, pair)
+
+	if bt, ht := typeSignature(base), typeSignature(head); bt != "" && ht != "" && bt != ht {
+		d.add(SeverityBreaking, ChangeTypeChanged, key, location, fmt.Sprintf("type changed from %s to %s", bt, ht))
+		return
+	}
+	d.compareNullable(key, location, base, head, dir)
+	d.compareEnum(key, location, base, head, dir)
+	d.compareVariants(key, location, "oneOf", base.OneOf, head.OneOf, dir)
+	d.compareVariants(key, location, "anyOf", base.AnyOf, head.AnyOf, dir)
+
+	if base.Items != nil && head.Items != nil {
+		d.compareSchema(key, location+"[]", base.Items, head.Items, dir)
+	}
+
+	baseProps, baseRequired := flattenObject(base)
+	headProps, headRequired := flattenObject(head)
+
+	var removed, added []string
+	for _, name := range sortedKeys(baseProps) {
+		fieldLoc := joinLocation(location, name)
+		hp, ok := headProps[name]
+		if !ok {
+			removed = append(removed, name)
+			continue
+		}
+		switch dir {
+		case directionRequest:
+			if headRequired[name] && !baseRequired[name] {
+				d.add(SeverityBreaking, ChangeRequestFieldRequired, key, fieldLoc, "field became required")
+			}
+		case directionResponse:
+			if baseRequired[name] && !headRequired[name] {
+				d.add(SeverityBreaking, ChangeResponseFieldOptional, key, fieldLoc, "field is no longer guaranteed")
+			}
+		}
+		d.compareSchema(key, fieldLoc, baseProps[name], hp, dir)
+	}
+	for _, name := range sortedKeys(headProps) {
+		if _, ok := baseProps[name]; !ok {
+			added = append(added, name)
+		}
+	}
+
+	if dir == directionResponse {
+		// A single removal paired with a single addition of the same type is reported as a rename.
+		if len(removed) == 1 && len(added) == 1 &&
+			typeSignature(baseProps[removed[0]].Value) == typeSignature(headProps[added[0]].Value) {
+			d.add(SeverityBreaking, ChangeResponseFieldRenamed, key, joinLocation(location, removed[0]),
+				fmt.Sprintf("field renamed to %q", added[0]))
+			return
+		}
+		for _, name := range removed {
+			d.add(SeverityBreaking, ChangeResponseFieldRemoved, key, joinLocation(location, name), "field removed")
+		}
+		for _, name := range added {
+			d.add(SeverityNonBreaking, ChangeResponseFieldAdded, key, joinLocation(location, name), "field added")
+		}
+		return
+	}
+
+	for _, name := range removed {
+		d.add(SeverityBreaking, ChangeRequestFieldRemoved, key, joinLocation(location, name), "field no longer accepted")
+	}
+	for _, name := range added {
+		if headRequired[name] {
+			d.add(SeverityBreaking, ChangeRequestFieldRequired, key, joinLocation(location, name), "new required field")
+		} else {
+			d.add(SeverityNonBreaking, ChangeRequestFieldAdded, key, joinLocation(location, name), "optional field added")
+		}
+	}
+}
+
+func (d *differ) compareEnum(key, location string, base, head *openapi3.Schema, dir schemaDirection) {
+	if len(base.Enum) == 0 && len(head.Enum) == 0 {
+		return
+	}
+	headValues := enumSet(head.Enum)
+	baseValues := enumSet(base.Enum)
+
+	var removedValues, addedValues []string
+	if len(head.Enum) > 0 {
+		for _, v := range sortedKeys(baseValues) {
+			if !headValues[v] {
+				removedValues = append(removedValues, v)
+			}
+		}
+	}
+	if len(base.Enum) > 0 {
+		for _, v := range sortedKeys(headValues) {
+			if !baseValues[v] {
+				addedValues = append(addedValues, v)
+			}
+		}
+	}
+
+	narrowed := len(removedValues) > 0 || (len(base.Enum) == 0 && len(head.Enum) > 0)
+	widened := len(addedValues) > 0 || (len(base.Enum) > 0 && len(head.Enum) == 0)
+
+	if narrowed {
+		severity := SeverityNonBreaking
+		if dir == directionRequest {
+			severity = SeverityBreaking
+		}
+		msg := "enum restricted"
+		if len(removedValues) > 0 {
+			msg = "enum values removed: " + strings.Join(removedValues, ", ")
+		}
+		d.add(severity, ChangeEnumNarrowed, key, location, msg)
+	}
+	if widened {
+		// Clients switching over response values cannot handle new ones.
+		severity := SeverityNonBreaking
+		if dir == directionResponse {
+			severity = SeverityBreaking
+		}
+		msg := "enum restriction lifted"
+		if len(addedValues) > 0 {
+			msg = "enum values added: " + strings.Join(addedValues, ", ")
+		}
+		d.add(severity, ChangeEnumWidened, key, location, msg)
+	}
+}
+
+// compareNullable reports null becoming a possible response value, or no longer being
+// accepted in requests, as breaking.
+func (d *differ) compareNullable(key, location string, base, head *openapi3.Schema, dir schemaDirection) {
+	baseNull, headNull := isNullable(base), isNullable(head)
+	switch {
+	case !baseNull && headNull:
+		severity := SeverityNonBreaking
+		if dir == directionResponse {
+			severity = SeverityBreaking
+		}
+		d.add(severity, ChangeBecameNullable, key, location, "value may be null")
+	case baseNull && !headNull:
+		severity := SeverityNonBreaking
+		if dir == directionRequest {
+			severity = SeverityBreaking
+		}
+		d.add(severity, ChangeBecameNonNullable, key, location, "value may no longer be null")
+	}
+}
+
+// compareVariants compares the oneOf or anyOf members of two schemas. Members are matched
+// by their component reference, inline members by position. New response variants and
+// removed request variants are breaking.
+func (d *differ) compareVariants(key, location, keyword string, base, head openapi3.SchemaRefs, dir schemaDirection) {
+	if len(base) == 0 && len(head) == 0 {
+		return
+	}
+	if len(base) == 0 || len(head) == 0 {
+		d.add(SeverityBreaking, ChangeTypeChanged, key, location, keyword+" composition added or removed")
+		return
+	}
+	baseVariants, headVariants := variantSet(base), variantSet(head)
+	for _, name := range sortedKeys(baseVariants) {
+		variantLoc := location + "<" + name + ">"
+		hv, ok := headVariants[name]
+		if !ok {
+			severity := SeverityNonBreaking
+			if dir == directionRequest {
+				severity = SeverityBreaking
+			}
+			d.add(severity, ChangeVariantRemoved, key, variantLoc, keyword+" variant removed")
+			continue
+		}
+		d.compareSchema(key, variantLoc, baseVariants[name], hv, dir)
+	}
+	for _, name := range sortedKeys(headVariants) {
+		if _, ok := baseVariants[name]; !ok {
+			severity := SeverityNonBreaking
+			if dir == directionResponse {
+				severity = SeverityBreaking
+			}
+			d.add(severity, ChangeVariantAdded, key, location+"<"+name+">", keyword+" variant added")
+		}
+	}
+}
+
+func variantSet(refs openapi3.SchemaRefs) map[string]*openapi3.SchemaRef {
+	out := make(map[string]*openapi3.SchemaRef, len(refs))
+	for i, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		name := ref.Ref
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		} else {
+			name = strings.TrimPrefix(name, "#/components/schemas/")
+		}
+		out[name] = ref
+	}
+	return out
+}
+
+func isNullable(s *openapi3.Schema) bool {
+	if s.Nullable || (s.Type != nil && s.Type.Includes(openapi3.TypeNull)) {
+		return true
+	}
+	for _, v := range s.Enum {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenObject merges properties and required fields of a schema and its allOf members.
+func flattenObject(s *openapi3.Schema) (map[string]*openapi3.SchemaRef, map[string]bool) {
+	props := map[string]*openapi3.SchemaRef{}
+	required := map[string]bool{}
+	var walk func(*openapi3.Schema, int)
+	walk = func(s *openapi3.Schema, depth int) {
+		if s == nil || depth > 16 {
+			return
+		}
+		for name, p := range s.Properties {
+			props[name] = p
+		}
+		for _, r := range s.Required {
+			required[r] = true
+		}
+		for _, member := range s.AllOf {
+			if member != nil {
+				walk(member.Value, depth+1)
+			}
+		}
+	}
+	walk(s, 0)
+	return props, required
+}
+
+// typeSignature returns the schema's declared types without "null"; nullability changes are
+// reported by compareNullable instead.
+func typeSignature(s *openapi3.Schema) string {
+	if s == nil || s.Type == nil {
+		return ""
+	}
+	var types []string
+	for _, t := range *s.Type {
+		if t != openapi3.TypeNull {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return strings.Join(types, "|")
+}
+
+func enumSet(values []any) map[string]bool {
+	out := make(map[string]bool, len(values))
+	for _, v := range values {
+		out[fmt.Sprint(v)] = true
+	}
+	return out
+}
+
+func joinLocation(location, field string) string {
+	return location + "." + field
+}
+
+func isSuccessStatus(status string) bool {
+	if status == "default" {
+		return false
+	}
+	var code int
+	if _, err := fmt.Sscanf(status, "%d", &code); err != nil {
+		return strings.HasPrefix(status, "2")
+	}
+	return code >= http.StatusOK && code < http.StatusMultipleChoices
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,243 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const diffBaseSpec = `
+openapi: 3.0.3
+info: {title: API, version: 1.0.0}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+                role: {type: string, enum: [admin, member, guest]}
+      responses:
+        "201": {description: Created}
+  /users/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "204": {description: Deleted}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        email: {type: string}
+        age: {type: integer}
+`
+
+const diffHeadSpec = `
+openapi: 3.0.3
+info: {title: API, version: 1.1.0}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: string}}
+        - {name: cursor, in: query, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name, team]
+              properties:
+                name: {type: string}
+                team: {type: string}
+                role: {type: string, enum: [admin, member]}
+      responses:
+        "201": {description: Created}
+  /teams:
+    get:
+      responses:
+        "200": {description: OK}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        mail: {type: string}
+        age: {type: integer}
+`
+
+func loadDiffSpec(t *testing.T, src string) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(src))
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	return doc
+}
+
+func TestDiffClassifiesChanges(t *testing.T) {
+	report := openapi.Diff(loadDiffSpec(t, diffBaseSpec), loadDiffSpec(t, diffHeadSpec))
+
+	type key struct {
+		kind, op, location string
+		severity           openapi.ChangeSeverity
+	}
+	got := map[key]bool{}
+	for _, c := range report.Changes {
+		got[key{c.Kind, c.Operation, c.Location, c.Severity}] = true
+	}
+
+	expected := []key{
+		{openapi.ChangeOperationRemoved, "DELETE /users/{id}", "", openapi.SeverityBreaking},
+		{openapi.ChangeOperationAdded, "GET /teams", "", openapi.SeverityNonBreaking},
+		{openapi.ChangeParameterTypeChanged, "GET /users", `query parameter "limit"`, openapi.SeverityBreaking},
+		{openapi.ChangeParameterAdded, "GET /users", `query parameter "cursor"`, openapi.SeverityNonBreaking},
+		{openapi.ChangeRequestFieldRequired, "POST /users", "request body.team", openapi.SeverityBreaking},
+		{openapi.ChangeEnumNarrowed, "POST /users", "request body.role", openapi.SeverityBreaking},
+		{openapi.ChangeResponseFieldRenamed, "GET /users", "response 200.email", openapi.SeverityBreaking},
+	}
+	for _, want := range expected {
+		if !got[want] {
+			t.Errorf("missing change %+v in %+v", want, report.Changes)
+		}
+	}
+	if !report.HasBreaking() {
+		t.Fatal("expected breaking changes")
+	}
+}
+
+func TestDiffReportsResponseFieldRemovalAndAddition(t *testing.T) {
+	base := loadDiffSpec(t, strings.Replace(diffBaseSpec, "        age: {type: integer}\n", "", 1))
+	head := loadDiffSpec(t, diffBaseSpec)
+
+	report := openapi.Diff(base, head)
+	if report.HasBreaking() {
+		t.Fatalf("adding a response field must not be breaking: %+v", report.Breaking())
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Kind != openapi.ChangeResponseFieldAdded {
+		t.Fatalf("unexpected changes %+v", report.Changes)
+	}
+
+	report = openapi.Diff(head, base)
+	breaking := report.Breaking()
+	if len(breaking) != 1 || breaking[0].Kind != openapi.ChangeResponseFieldRemoved || breaking[0].Location != "response 200.age" {
+		t.Fatalf("unexpected breaking changes %+v", breaking)
+	}
+}
+
+const diffResponseSpec = `
+openapi: 3.0.3
+info: {title: API, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  status: {type: string, enum: [available, sold]}
+                  owner: {type: string}
+                  pet:
+                    oneOf:
+                      - {$ref: '#/components/schemas/Cat'}
+                      - {$ref: '#/components/schemas/Dog'}
+components:
+  schemas:
+    Cat: {type: object, properties: {name: {type: string}}}
+    Dog: {type: object, properties: {name: {type: string}}}
+    Bird: {type: object, properties: {name: {type: string}}}
+`
+
+func TestDiffClassifiesResponseWideningAsBreaking(t *testing.T) {
+	head := strings.NewReplacer(
+		"enum: [available, sold]", "enum: [available, sold, reserved]",
+		"owner: {type: string}", "owner: {type: string, nullable: true}",
+		"- {$ref: '#/components/schemas/Dog'}", "- {$ref: '#/components/schemas/Dog'}\n                      - {$ref: '#/components/schemas/Bird'}",
+	).Replace(diffResponseSpec)
+
+	report := openapi.Diff(loadDiffSpec(t, diffResponseSpec), loadDiffSpec(t, head))
+	got := map[string]string{}
+	for _, c := range report.Breaking() {
+		got[c.Kind] = c.Location
+	}
+	want := map[string]string{
+		openapi.ChangeEnumWidened:    "response 200.status",
+		openapi.ChangeBecameNullable: "response 200.owner",
+		openapi.ChangeVariantAdded:   "response 200.pet<Bird>",
+	}
+	for kind, location := range want {
+		if got[kind] != location {
+			t.Errorf("expected breaking %s at %s, got %+v", kind, location, report.Changes)
+		}
+	}
+
+	report = openapi.Diff(loadDiffSpec(t, head), loadDiffSpec(t, diffResponseSpec))
+	if report.HasBreaking() {
+		t.Fatalf("narrowing a response must not be breaking: %+v", report.Breaking())
+	}
+}
+
+func TestDiffIdenticalDocuments(t *testing.T) {
+	report := openapi.Diff(loadDiffSpec(t, diffBaseSpec), loadDiffSpec(t, diffBaseSpec))
+	if len(report.Changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", report.Changes)
+	}
+}
+
+func TestDiffReportWriters(t *testing.T) {
+	report := openapi.Diff(loadDiffSpec(t, diffBaseSpec), loadDiffSpec(t, diffHeadSpec))
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	if !strings.Contains(text.String(), "BREAKING     DELETE /users/{id}: operation removed") {
+		t.Fatalf("unexpected text report:\n%s", text.String())
+	}
+
+	var raw bytes.Buffer
+	if err := report.WriteJSON(&raw); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	var decoded openapi.DiffReport
+	if err := json.Unmarshal(raw.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode json report: %v", err)
+	}
+	if len(decoded.Changes) != len(report.Changes) {
+		t.Fatalf("expected %d changes, got %d", len(report.Changes), len(decoded.Changes))
+	}
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// promotedField is a field visible in the JSON encoding of a struct, possibly promoted
+// from an embedded struct.
+type promotedField struct {
+	field  reflect.StructField
+	name   string
+	tagged bool
+	index  []int
+	// optional is set for fields promoted through an embedded pointer, which encoding/json
+	// omits while the pointer is nil.
+	optional bool
+	// allOf marks an embedded struct kept as an allOf entry.
+	allOf bool
+}
+
+// populateFlattened documents t with embedded struct fields promoted into its properties.
+func (b *Builder) populateFlattened(schema *openapi3.Schema, t reflect.Type) error {
+	for _, f := range promotedFields(t) {
+		if f.allOf {
+			ref, err := b.schemaRefFromType(f.field.Type)
+			if err != nil {
+				return err
+			}
+			// Embedded components are referenced, not inlined into the composition.
+			if component := b.componentRef(ref); component != nil {
+				ref = component
+			}
+			schema.AllOf = append(schema.AllOf, ref)
+			continue
+		}
+		// Path/query/header/cookie fields are documented as operation parameters.
+		if _, _, ok := apix.ParameterTag(f.field); ok {
+			continue
+		}
+		if err := b.addProperty(schema, f.field, f.name, isFieldRequired(f.field) && !f.optional); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promotedFields lists the JSON fields of struct type t following encoding/json: fields of
+// untagged embedded structs are promoted, shallower fields shadow deeper ones, and among
+// fields with the same name at the same depth a single tagged field wins, otherwise none
+// is encoded.
+func promotedFields(t reflect.Type) []promotedField {
+	type embedded struct {
+		typ      reflect.Type
+		index    []int
+		optional bool
+	}
+
+	var fields []promotedField
+	claimed := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+	next := []embedded{{typ: t}}
+
+	for len(next) > 0 {
+		current := next
+		next = nil
+		var candidates []promotedField
+
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				ft := sf.Type
+				isPointer := ft.Kind() == reflect.Pointer
+				if isPointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					// Unexported embedded structs still promote their exported fields.
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, _, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(e.index), i)
+				optional := e.optional || (sf.Anonymous && isPointer)
+
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					if sf.Tag.Get("openapi") == "allOf" {
+						candidates = append(candidates, promotedField{field: sf, index: index, allOf: true})
+						continue
+					}
+					next = append(next, embedded{typ: ft, index: index, optional: optional})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				candidate := promotedField{field: sf, name: name, tagged: name != "", index: index, optional: e.optional}
+				if candidate.name == "" {
+					candidate.name = sf.Name
+				}
+				candidates = append(candidates, candidate)
+			}
+		}
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+
+		byName := map[string][]promotedField{}
+		for _, c := range candidates {
+			if c.allOf {
+				fields = append(fields, c)
+				continue
+			}
+			byName[c.name] = append(byName[c.name], c)
+		}
+		for name, group := range byName {
+			if claimed[name] {
+				continue
+			}
+			claimed[name] = true
+			if winner, ok := dominantField(group); ok {
+				fields = append(fields, winner)
+			}
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b promotedField) int { return slices.Compare(a.index, b.index) })
+	return fields
+}
+
+// dominantField picks the field encoded for a name among fields at the same depth.
+func dominantField(group []promotedField) (promotedField, bool) {
+	if len(group) == 1 {
+		return group[0], true
+	}
+	var winner promotedField
+	tagged := 0
+	for _, f := range group {
+		if f.tagged {
+			winner = f
+			tagged++
+		}
+	}
+	return winner, tagged == 1
+}
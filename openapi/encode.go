@@ -11,16 +11,31 @@ import (
 )
 
 // EncodeDocument serialises the OpenAPI document in the requested format and returns payload and content type.
+// Documents whose openapi field is 3.1.x are written with JSON Schema 2020-12 keywords
+// (type arrays with "null", $ref siblings, examples, const); 3.0.x documents are written as modelled.
 func EncodeDocument(doc *openapi3.T, format string) ([]byte, string, error) {
+	var payload any = doc
+	if isOpenAPI31(doc.OpenAPI) {
+		tree, err := documentTree(doc)
+		if err != nil {
+			return nil, "", err
+		}
+		upgradeSchemas(tree)
+		payload = tree
+	}
+
 	switch strings.ToLower(format) {
 	case "yaml", "yml", "":
-		data, err := yaml.Marshal(doc)
+		if tree, ok := payload.(map[string]any); ok {
+			payload = plainNumbers(tree)
+		}
+		data, err := yaml.Marshal(payload)
 		return data, "application/yaml", err
 	case "json":
 		buf := &bytes.Buffer{}
 		enc := json.NewEncoder(buf)
 		enc.SetIndent("", "  ")
-		if err := enc.Encode(doc); err != nil {
+		if err := enc.Encode(payload); err != nil {
 			return nil, "", fmt.Errorf("encode json: %w", err)
 		}
 		return buf.Bytes(), "application/json", nil
@@ -28,3 +43,58 @@ func EncodeDocument(doc *openapi3.T, format string) ([]byte, string, error) {
 		return nil, "", fmt.Errorf("unsupported format %q", format)
 	}
 }
+
+// DecodeDocument loads a JSON or YAML document produced by EncodeDocument. 3.1 schemas are
+// mapped back onto kin-openapi's model so the result can be diffed and validated.
+func DecodeDocument(data []byte) (*openapi3.T, error) {
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("decode openapi: %w", err)
+	}
+	if version, _ := tree["openapi"].(string); isOpenAPI31(version) {
+		downgradeSchemas(tree)
+		converted, err := json.Marshal(tree)
+		if err != nil {
+			return nil, fmt.Errorf("decode openapi: %w", err)
+		}
+		data = converted
+	}
+	return openapi3.NewLoader().LoadFromData(data)
+}
+
+// documentTree round-trips doc through JSON into generic maps, keeping numbers exact.
+func documentTree(doc *openapi3.T) (map[string]any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode openapi: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree map[string]any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("encode openapi: %w", err)
+	}
+	return tree, nil
+}
+
+// plainNumbers replaces json.Number values with int64 or float64 so YAML renders them unquoted.
+func plainNumbers(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			x[k] = plainNumbers(item)
+		}
+	case []any:
+		for i, item := range x {
+			x[i] = plainNumbers(item)
+		}
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n
+		}
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
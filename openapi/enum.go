@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// buildEnumSchema documents t as an enum. Named types become components referenced with
+// $ref; values are normalised through encoding/json so custom marshalers are honoured.
+func (b *Builder) buildEnumSchema(t reflect.Type, info apix.EnumInfo) (*openapi3.SchemaRef, error) {
+	schema := &openapi3.Schema{}
+	for _, v := range info.Values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("enum %s: %w", t, err)
+		}
+		var normalized any
+		if err := json.Unmarshal(data, &normalized); err != nil {
+			return nil, fmt.Errorf("enum %s: %w", t, err)
+		}
+		schema.Enum = append(schema.Enum, normalized)
+	}
+	schemaType(schema, enumSchemaType(t, schema.Enum))
+	if len(info.VarNames) == len(info.Values) {
+		schema.Extensions = map[string]any{"x-enum-varnames": info.VarNames}
+	}
+	if len(info.Descriptions) == len(info.Values) {
+		if schema.Extensions == nil {
+			schema.Extensions = map[string]any{}
+		}
+		schema.Extensions["x-enum-descriptions"] = info.Descriptions
+	}
+
+	name, err := b.componentName(t)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return schemaRef(schema), nil
+	}
+	if err := apix.ExecuteOnSchemaGenerate(name, schema); err != nil {
+		return nil, err
+	}
+	component := schemaRef(schema)
+	b.doc.Components.Schemas[name] = component
+	b.schemaCache[t] = component
+	return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name}, nil
+}
+
+// enumSchemaType derives the JSON type from the normalised values, falling back to t's kind.
+func enumSchemaType(t reflect.Type, values []any) string {
+	if len(values) == 0 {
+		switch t.Kind() {
+		case reflect.Bool:
+			return "boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return "integer"
+		case reflect.Float32, reflect.Float64:
+			return "number"
+		default:
+			return "string"
+		}
+	}
+	switch values[0].(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if k := t.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			return "number"
+		}
+		for _, v := range values {
+			if f, ok := v.(float64); !ok || f != math.Trunc(f) {
+				return "number"
+			}
+		}
+		return "integer"
+	default:
+		return "string"
+	}
+}
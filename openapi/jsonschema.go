@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target document versions accepted by Builder.OpenAPIVersion.
+const (
+	OpenAPIVersion31 = "3.1.0"
+	OpenAPIVersion30 = "3.0.3"
+)
+
+// isOpenAPI31 reports whether version names an OpenAPI 3.1 document.
+func isOpenAPI31(version string) bool {
+	return version == "3.1" || strings.HasPrefix(version, "3.1.")
+}
+
+func checkOpenAPIVersion(version string) error {
+	if isOpenAPI31(version) || version == "3.0" || strings.HasPrefix(version, "3.0.") {
+		return nil
+	}
+	return fmt.Errorf("unsupported OpenAPI version %q", version)
+}
+
+// The builder models schemas with kin-openapi, which follows OpenAPI 3.0 (nullable, boolean
+// exclusive bounds, example). upgradeSchemas rewrites a JSON-decoded document into JSON
+// Schema 2020-12 for 3.1 output; downgradeSchemas reverses it so 3.1 documents can be
+// loaded back into kin-openapi.
+
+// annotationKeys are lifted onto the anyOf wrapper when a typeless schema becomes nullable.
+var annotationKeys = []string{"title", "description", "default", "examples", "readOnly", "writeOnly", "deprecated"}
+
+func upgradeSchemas(doc map[string]any) {
+	forEachDocumentSchema(doc, upgradeSchema)
+}
+
+func downgradeSchemas(doc map[string]any) {
+	forEachDocumentSchema(doc, downgradeSchema)
+}
+
+func upgradeSchema(s map[string]any) map[string]any {
+	forEachSubschema(s, upgradeSchema)
+
+	// allOf wrappers around a single reference exist only to carry siblings in 3.0.
+	if ref, ok := soleAllOfRef(s); ok {
+		delete(s, "allOf")
+		s["$ref"] = ref
+	}
+	if example, ok := s["example"]; ok {
+		delete(s, "example")
+		s["examples"] = []any{example}
+	}
+	for bound, limit := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		exclusive, ok := s[bound]
+		if !ok {
+			continue
+		}
+		delete(s, bound)
+		if value, ok := s[limit]; ok && exclusive == true {
+			delete(s, limit)
+			s[bound] = value
+		}
+	}
+
+	if s["nullable"] == true {
+		delete(s, "nullable")
+		switch t := s["type"].(type) {
+		case string:
+			s["type"] = []any{t, "null"}
+		case []any:
+			if !containsValue(t, "null") {
+				s["type"] = append(t, "null")
+			}
+		default:
+			wrapper := map[string]any{}
+			for _, key := range annotationKeys {
+				if v, ok := s[key]; ok {
+					wrapper[key] = v
+					delete(s, key)
+				}
+			}
+			wrapper["anyOf"] = []any{s, map[string]any{"type": "null"}}
+			return wrapper
+		}
+		if enum, ok := s["enum"].([]any); ok && !containsValue(enum, nil) {
+			s["enum"] = append(enum, nil)
+		}
+	}
+
+	if enum, ok := s["enum"].([]any); ok && len(enum) == 1 {
+		delete(s, "enum")
+		s["const"] = enum[0]
+	}
+	return s
+}
+
+func downgradeSchema(s map[string]any) map[string]any {
+	forEachSubschema(s, downgradeSchema)
+
+	if ref, ok := s["$ref"]; ok && len(s) > 1 {
+		delete(s, "$ref")
+		s["allOf"] = []any{map[string]any{"$ref": ref}}
+	}
+	if examples, ok := s["examples"].([]any); ok {
+		delete(s, "examples")
+		if len(examples) > 0 {
+			s["example"] = examples[0]
+		}
+	}
+	for bound, limit := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		if value, ok := s[bound]; ok {
+			if _, isBool := value.(bool); !isBool {
+				s[limit] = value
+				s[bound] = true
+			}
+		}
+	}
+	if value, ok := s["const"]; ok {
+		delete(s, "const")
+		s["enum"] = []any{value}
+	}
+
+	if types, ok := s["type"].([]any); ok {
+		if containsValue(types, "null") {
+			s["nullable"] = true
+			types = removeValue(types, "null")
+		}
+		if len(types) == 1 {
+			s["type"] = types[0]
+		} else {
+			s["type"] = types
+		}
+		if enum, ok := s["enum"].([]any); ok && s["nullable"] == true {
+			s["enum"] = removeValue(enum, nil)
+		}
+	}
+	if inner, ok := nullableAnyOf(s); ok {
+		delete(s, "anyOf")
+		s["nullable"] = true
+		if _, isRef := inner["$ref"]; isRef {
+			s["allOf"] = []any{inner}
+		} else {
+			for key, value := range inner {
+				s[key] = value
+			}
+		}
+	}
+	return s
+}
+
+// soleAllOfRef returns the reference of a schema that only wraps a single $ref in allOf.
+func soleAllOfRef(s map[string]any) (any, bool) {
+	allOf, ok := s["allOf"].([]any)
+	if !ok || len(allOf) != 1 {
+		return nil, false
+	}
+	inner, ok := allOf[0].(map[string]any)
+	if !ok || len(inner) != 1 || inner["$ref"] == nil {
+		return nil, false
+	}
+	for _, key := range []string{"type", "properties", "items", "additionalProperties", "oneOf", "anyOf"} {
+		if _, ok := s[key]; ok {
+			return nil, false
+		}
+	}
+	return inner["$ref"], true
+}
+
+// nullableAnyOf matches anyOf: [schema, {type: null}] and returns schema.
+func nullableAnyOf(s map[string]any) (map[string]any, bool) {
+	anyOf, ok := s["anyOf"].([]any)
+	if !ok || len(anyOf) != 2 {
+		return nil, false
+	}
+	inner, ok := anyOf[0].(map[string]any)
+	null, isMap := anyOf[1].(map[string]any)
+	if !ok || !isMap || len(null) != 1 || null["type"] != "null" {
+		return nil, false
+	}
+	return inner, true
+}
+
+func containsValue(values []any, v any) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func removeValue(values []any, v any) []any {
+	out := make([]any, 0, len(values))
+	for _, x := range values {
+		if x != v {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+type schemaFunc func(map[string]any) map[string]any
+
+func forEachSubschema(s map[string]any, fn schemaFunc) {
+	if props, ok := s["properties"].(map[string]any); ok {
+		for name := range props {
+			visitSchema(props, name, fn)
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		visitSchema(s, key, fn)
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if list, ok := s[key].([]any); ok {
+			for i, item := range list {
+				if m, ok := item.(map[string]any); ok {
+					list[i] = fn(m)
+				}
+			}
+		}
+	}
+}
+
+// forEachDocumentSchema applies fn to every top-level schema of a JSON-decoded document:
+// components, parameters, headers, request bodies and responses.
+func forEachDocumentSchema(doc map[string]any, fn schemaFunc) {
+	if components, ok := doc["components"].(map[string]any); ok {
+		if schemas, ok := components["schemas"].(map[string]any); ok {
+			for name := range schemas {
+				visitSchema(schemas, name, fn)
+			}
+		}
+		eachObject(components["parameters"], func(p map[string]any) { visitParameter(p, fn) })
+		eachObject(components["headers"], func(h map[string]any) { visitParameter(h, fn) })
+		eachObject(components["requestBodies"], func(rb map[string]any) { visitContent(rb, fn) })
+		eachObject(components["responses"], func(r map[string]any) { visitResponse(r, fn) })
+	}
+
+	eachObject(doc["paths"], func(item map[string]any) {
+		eachListed(item["parameters"], func(p map[string]any) { visitParameter(p, fn) })
+		for method, value := range item {
+			op, ok := value.(map[string]any)
+			if !ok || method == "parameters" || method == "servers" {
+				continue
+			}
+			if method == AdditionalOperationsExtension {
+				eachObject(op, func(op map[string]any) { visitOperation(op, fn) })
+				continue
+			}
+			visitOperation(op, fn)
+		}
+	})
+}
+
+func visitOperation(op map[string]any, fn schemaFunc) {
+	eachListed(op["parameters"], func(p map[string]any) { visitParameter(p, fn) })
+	if rb, ok := op["requestBody"].(map[string]any); ok {
+		visitContent(rb, fn)
+	}
+	eachObject(op["responses"], func(r map[string]any) { visitResponse(r, fn) })
+}
+
+func visitSchema(parent map[string]any, key string, fn schemaFunc) {
+	if m, ok := parent[key].(map[string]any); ok {
+		parent[key] = fn(m)
+	}
+}
+
+func visitParameter(p map[string]any, fn schemaFunc) {
+	visitSchema(p, "schema", fn)
+	visitContent(p, fn)
+}
+
+func visitContent(obj map[string]any, fn schemaFunc) {
+	eachObject(obj["content"], func(media map[string]any) { visitSchema(media, "schema", fn) })
+}
+
+func visitResponse(r map[string]any, fn schemaFunc) {
+	visitContent(r, fn)
+	eachObject(r["headers"], func(h map[string]any) { visitParameter(h, fn) })
+}
+
+func eachObject(v any, fn func(map[string]any)) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, item := range m {
+		if obj, ok := item.(map[string]any); ok {
+			fn(obj)
+		}
+	}
+}
+
+func eachListed(v any, fn func(map[string]any)) {
+	list, ok := v.([]any)
+	if !ok {
+		return
+	}
+	for _, item := range list {
+		if obj, ok := item.(map[string]any); ok {
+			fn(obj)
+		}
+	}
+}
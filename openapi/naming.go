@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ComponentNamer returns the components/schemas key for a named Go type. Returning an
+// empty string falls back to DefaultComponentName.
+type ComponentNamer func(t reflect.Type) string
+
+// DefaultComponentName names t after the last element of its package path and its type
+// name, e.g. models_User. Generic instantiations get readable names built from their type
+// arguments: Page[myapp.User] becomes models_PageOfUser and Pair[string, []User] becomes
+// models_PairOfStringAndUserList. Unnamed types return "".
+func DefaultComponentName(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+	return qualifiedComponentName(t, 1)
+}
+
+// qualifiedComponentName prefixes the readable type name with the last depth elements of
+// t's package path.
+func qualifiedComponentName(t reflect.Type, depth int) string {
+	name := readableTypeName(t.Name())
+	if pkg := t.PkgPath(); pkg != "" {
+		parts := strings.Split(pkg, "/")
+		if depth > len(parts) {
+			depth = len(parts)
+		}
+		name = strings.Join(parts[len(parts)-depth:], "_") + "_" + name
+	}
+	return sanitizeComponentName(name)
+}
+
+// componentName returns the component name for t within the document being built, or ""
+// for unnamed types. Names are assigned once per type; a name already taken by another
+// type is qualified with more of the package path, then numbered, unless
+// StrictComponentNames is set. Build then plans the names again once every type is known,
+// so the outcome does not depend on the order routes are traversed.
+func (b *Builder) componentName(t reflect.Type) (string, error) {
+	if t.Name() == "" {
+		return "", nil
+	}
+	if name, ok := b.componentNames[t]; ok {
+		return name, nil
+	}
+
+	name := b.baseComponentName(t)
+	if owner, taken := b.componentOwners[name]; taken {
+		if b.StrictComponentNames {
+			return "", fmt.Errorf("component name %q is used by both %s and %s", name, typeIdentity(owner), typeIdentity(t))
+		}
+		name = b.disambiguate(t, owner, name)
+		b.nameCollision = true
+	}
+
+	b.claimComponentName(t, name)
+	b.componentOrder = append(b.componentOrder, t)
+	return name, nil
+}
+
+// baseComponentName returns the name t is given when no other type claims it.
+func (b *Builder) baseComponentName(t reflect.Type) string {
+	name := ""
+	if b.ComponentNamer != nil {
+		name = sanitizeComponentName(b.ComponentNamer(t))
+	}
+	if name == "" {
+		name = DefaultComponentName(t)
+	}
+	return name
+}
+
+func (b *Builder) claimComponentName(t reflect.Type, name string) {
+	if b.componentNames == nil {
+		b.componentNames = make(map[reflect.Type]string)
+		b.componentOwners = make(map[string]reflect.Type)
+	}
+	b.componentNames[t] = name
+	b.componentOwners[name] = t
+}
+
+// planComponentNames reassigns the names of every type named so far. Of the types sharing
+// a name, the one with the lowest package path keeps it and the others are qualified in
+// package path order.
+func (b *Builder) planComponentNames() {
+	types := b.componentOrder
+	sort.SliceStable(types, func(i, j int) bool {
+		return typeIdentity(types[i]) < typeIdentity(types[j])
+	})
+
+	b.componentNames = nil
+	b.componentOwners = nil
+	var later []reflect.Type
+	for _, t := range types {
+		name := b.baseComponentName(t)
+		if _, taken := b.componentOwners[name]; taken {
+			later = append(later, t)
+			continue
+		}
+		b.claimComponentName(t, name)
+	}
+	for _, t := range later {
+		name := b.baseComponentName(t)
+		b.claimComponentName(t, b.disambiguate(t, b.componentOwners[name], name))
+	}
+}
+
+func (b *Builder) disambiguate(t, owner reflect.Type, name string) string {
+	depth := strings.Count(t.PkgPath(), "/") + 1
+	if t.PkgPath() == owner.PkgPath() {
+		// Types declared inside functions share their package path and name.
+		depth = 0
+	}
+	for i := 1; i <= depth; i++ {
+		candidate := qualifiedComponentName(t, i)
+		if _, taken := b.componentOwners[candidate]; !taken {
+			return candidate
+		}
+	}
+	for n := 2; ; n++ {
+		candidate := name + "_" + strconv.Itoa(n)
+		if _, taken := b.componentOwners[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+func typeIdentity(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// readableTypeName turns a reflect type name, which spells out generic type arguments
+// with full package paths, into an identifier: Page[github.com/x/models.User] → PageOfUser.
+func readableTypeName(name string) string {
+	base, args, generic := strings.Cut(name, "[")
+	if !generic || !strings.HasSuffix(args, "]") {
+		return name
+	}
+	parts := splitTypeArgs(args[:len(args)-1])
+	for i, arg := range parts {
+		parts[i] = typeArgName(arg)
+	}
+	return base + "Of" + strings.Join(parts, "And")
+}
+
+// typeArgName names one type argument as spelled by reflect.
+func typeArgName(arg string) string {
+	arg = strings.TrimSpace(arg)
+	switch {
+	case strings.HasPrefix(arg, "*"):
+		return typeArgName(arg[1:])
+	case strings.HasPrefix(arg, "[]"):
+		return typeArgName(arg[2:]) + "List"
+	case strings.HasPrefix(arg, "["):
+		if end := strings.Index(arg, "]"); end > 0 {
+			return typeArgName(arg[end+1:]) + "List"
+		}
+	case strings.HasPrefix(arg, "map["):
+		if end := closingBracket(arg, len("map[")); end > 0 {
+			return typeArgName(arg[len("map["):end]) + "To" + typeArgName(arg[end+1:]) + "Map"
+		}
+	case strings.HasPrefix(arg, "struct {"):
+		return "Object"
+	case strings.HasPrefix(arg, "interface {"), arg == "any":
+		return "Any"
+	case strings.HasPrefix(arg, "func("), strings.HasPrefix(arg, "chan "):
+		return "Value"
+	}
+
+	// Strip the package path: github.com/x/models.User[...] → User[...].
+	head, rest, _ := strings.Cut(arg, "[")
+	if slash := strings.LastIndex(head, "/"); slash >= 0 {
+		head = head[slash+1:]
+	}
+	if dot := strings.Index(head, "."); dot >= 0 {
+		head = head[dot+1:]
+	}
+	if rest != "" {
+		head = readableTypeName(head + "[" + rest)
+	}
+	return upperFirst(head)
+}
+
+// splitTypeArgs splits a type argument list at top-level commas.
+func splitTypeArgs(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, list[start:])
+}
+
+// closingBracket returns the index of the ']' matching an opening bracket before from.
+func closingBracket(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,304 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+const responseValidationErrorCode = "RESPONSE_VALIDATION_FAILED"
+
+// ResponseValidator checks responses written by the adapters against the operation's
+// response definitions in the generated document: the status code must be documented,
+// documented headers must be present and well-formed, and JSON bodies must match their
+// schema. Error responses (4xx/5xx) are checked for a documented status only.
+//
+// The document is built once per registry, on first use, and rebuilt only when a route
+// registered later is seen, so the validator is cheap enough for integration test suites.
+// It is intended for development and tests; enable it through the adapters' Options.
+type ResponseValidator struct {
+	// FailOnViolation replaces an offending response with a 500 Problem Details listing each
+	// violation. When false, violations are logged and the response is sent unchanged.
+	FailOnViolation bool
+
+	// Logger receives violations when FailOnViolation is false. Default: slog.Default().
+	Logger *slog.Logger
+
+	// CustomizeBuilder allows additional tuning of the builder before building.
+	CustomizeBuilder func(*Builder)
+
+	mu   sync.Mutex
+	docs map[*apix.Registry]*openapi3.T
+}
+
+var responseFilterOptions = &openapi3filter.Options{
+	MultiError:            true,
+	IncludeResponseStatus: true,
+}
+
+var responseErrorFilterOptions = &openapi3filter.Options{
+	MultiError:            true,
+	IncludeResponseStatus: true,
+	ExcludeResponseBody:   true,
+}
+
+// ValidateResponse checks a response produced for ref. registry is the registry ref was
+// registered with (nil selects apix.DefaultRegistry()). It returns nil when the response
+// conforms or when violations were only logged; with FailOnViolation it returns an
+// *apix.ProblemDetails (500) describing the violations.
+func (v *ResponseValidator) ValidateResponse(ctx context.Context, registry *apix.Registry, ref *apix.RouteRef, status int, header http.Header, body []byte) error {
+	return v.validate(ctx, registry, ref, status, header, body, false)
+}
+
+// ValidateRejection checks a response the adapter wrote itself for a request it rejected
+// before the handler ran, because its parameters or body could not be decoded or failed
+// validation. It checks like ValidateResponse, except that an undocumented 400 or 422 passes:
+// operations do not document these responses. Responses written after the handler ran go
+// through ValidateResponse, so an undocumented 422 returned by a handler is still reported.
+func (v *ResponseValidator) ValidateRejection(ctx context.Context, registry *apix.Registry, ref *apix.RouteRef, status int, header http.Header, body []byte) error {
+	return v.validate(ctx, registry, ref, status, header, body, true)
+}
+
+func (v *ResponseValidator) validate(ctx context.Context, registry *apix.Registry, ref *apix.RouteRef, status int, header http.Header, body []byte, rejected bool) error {
+	if v == nil || ref == nil {
+		return nil
+	}
+	if registry == nil {
+		registry = apix.DefaultRegistry()
+	}
+
+	doc, op, err := v.operation(registry, ref)
+	if err != nil {
+		return v.report(ctx, ref, status, []apix.FieldError{{Rule: "document", Message: err.Error()}})
+	}
+	if op == nil {
+		return v.report(ctx, ref, status, []apix.FieldError{{Rule: "operation", Message: "operation is not documented"}})
+	}
+
+	if op.Responses.Status(status) == nil && !documentsDefault(op) {
+		if rejected && rejectionStatus(status) {
+			return nil
+		}
+		return v.report(ctx, ref, status, []apix.FieldError{{Rule: "status", Message: fmt.Sprintf("status %d is not documented", status)}})
+	}
+
+	if status == http.StatusCreated && header.Get("Location") == "" && defaultLocation(ref) {
+		op = withOptionalLocation(op)
+	}
+
+	options := responseFilterOptions
+	if status >= http.StatusBadRequest || binaryResponse(ref, status) {
+		options = responseErrorFilterOptions
+	}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: (&http.Request{Method: string(ref.Method), Header: http.Header{}}).WithContext(ctx),
+			Route: &routers.Route{
+				Spec:      doc,
+				Path:      normalizePath(ref.Path),
+				Method:    string(ref.Method),
+				Operation: op,
+			},
+		},
+		Status:  status,
+		Header:  header,
+		Body:    io.NopCloser(bytes.NewReader(body)),
+		Options: options,
+	}
+	if err := openapi3filter.ValidateResponse(ctx, input); err != nil {
+		return v.report(ctx, ref, status, FieldErrors(err))
+	}
+	return nil
+}
+
+// rejectionStatus reports whether the adapters answer rejected requests with status: 400 for
+// malformed bodies and parameters, 422 for failed validation.
+func rejectionStatus(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity
+}
+
+// defaultLocation reports whether the Location header of ref's 201 response is the one the
+// builder documents on every POST rather than one the route declared. Handlers returning a
+// plain body cannot set it, so its absence is not reported.
+func defaultLocation(ref *apix.RouteRef) bool {
+	if ref.Method != apix.MethodPost {
+		return false
+	}
+	headers := ref.SuccessHeaders[http.StatusCreated]
+	if resp := ref.Responses[http.StatusCreated]; resp != nil {
+		headers = append(slices.Clip(headers), resp.Headers...)
+	}
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, "Location") {
+			return false
+		}
+	}
+	return true
+}
+
+// withOptionalLocation returns a copy of op whose 201 Location header is not required. op
+// belongs to the cached document, so it is left untouched.
+func withOptionalLocation(op *openapi3.Operation) *openapi3.Operation {
+	created := op.Responses.Status(http.StatusCreated)
+	if created == nil || created.Value == nil || created.Value.Headers["Location"] == nil || created.Value.Headers["Location"].Value == nil {
+		return op
+	}
+	header := *created.Value.Headers["Location"].Value
+	header.Required = false
+	response := *created.Value
+	response.Headers = maps.Clone(response.Headers)
+	response.Headers["Location"] = &openapi3.HeaderRef{Value: &header}
+
+	out := *op
+	out.Responses = openapi3.NewResponsesWithCapacity(op.Responses.Len())
+	for code, ref := range op.Responses.Map() {
+		out.Responses.Set(code, ref)
+	}
+	out.Responses.Set(strconv.Itoa(http.StatusCreated), &openapi3.ResponseRef{Value: &response})
+	return &out
+}
+
+// binaryResponse reports whether ref answers status with a File or RawBody, whose bytes
+// are not checked against the format: binary schema.
+func binaryResponse(ref *apix.RouteRef, status int) bool {
+	resp := ref.Responses[status]
+	return resp != nil && apix.IsBinaryType(resp.ModelType)
+}
+
+// operation returns the document for registry and ref's operation within it, rebuilding the
+// document when ref was registered after the last build.
+func (v *ResponseValidator) operation(registry *apix.Registry, ref *apix.RouteRef) (*openapi3.T, *openapi3.Operation, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if doc, ok := v.docs[registry]; ok {
+		if op := lookupOperation(doc, ref); op != nil {
+			return doc, op, nil
+		}
+	}
+
+	b := NewBuilder()
+	if v.CustomizeBuilder != nil {
+		v.CustomizeBuilder(b)
+	}
+	doc, err := b.BuildRegistry(registry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build openapi: %w", err)
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return nil, nil, fmt.Errorf("resolve openapi refs: %w", err)
+	}
+	if v.docs == nil {
+		v.docs = make(map[*apix.Registry]*openapi3.T)
+	}
+	v.docs[registry] = doc
+	return doc, lookupOperation(doc, ref), nil
+}
+
+func lookupOperation(doc *openapi3.T, ref *apix.RouteRef) *openapi3.Operation {
+	return Operation(doc.Paths.Value(normalizePath(ref.Path)), string(ref.Method))
+}
+
+// documentsDefault reports whether op has a real default response rather than the empty
+// placeholder every generated operation carries.
+func documentsDefault(op *openapi3.Operation) bool {
+	def := op.Responses.Default()
+	return def != nil && def.Value != nil && def.Value.Description != nil && *def.Value.Description != ""
+}
+
+func (v *ResponseValidator) report(ctx context.Context, ref *apix.RouteRef, status int, violations []apix.FieldError) error {
+	if v.FailOnViolation {
+		problem := &apix.ProblemDetails{
+			Type:   "about:blank#" + responseValidationErrorCode,
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: fmt.Sprintf("%s %s: response %d does not match the API specification", ref.Method, ref.Path, status),
+		}
+		return problem.WithExtension("errors", violations)
+	}
+
+	logger := v.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.ErrorContext(ctx, "response does not match the API specification",
+		"method", string(ref.Method), "path", ref.Path, "status", status, "errors", violations)
+	return nil
+}
+
+// FieldErrors flattens kin-openapi request, response and schema validation errors into
+// field errors. Parameter failures use the parameter name as path; schema failures use
+// the JSON pointer of the offending value.
+func FieldErrors(err error) []apix.FieldError {
+	var out []apix.FieldError
+	collectFieldErrors(err, apix.FieldError{}, &out)
+	return out
+}
+
+func collectFieldErrors(err error, base apix.FieldError, out *[]apix.FieldError) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectFieldErrors(inner, base, out)
+		}
+	case *openapi3filter.RequestError:
+		fe := base
+		if e.Parameter != nil {
+			fe.Path = "/" + e.Parameter.Name
+		}
+		collectCause(e.Err, e.Reason, "request", fe, out)
+	case *openapi3filter.ResponseError:
+		collectCause(e.Err, e.Reason, "response", base, out)
+	case *openapi3.SchemaError:
+		fe := base
+		if ptr := e.JSONPointer(); len(ptr) > 0 {
+			tokens := make([]string, len(ptr))
+			for i, token := range ptr {
+				tokens[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+			}
+			fe.Path = base.Path + "/" + strings.Join(tokens, "/")
+		}
+		fe.Rule = e.SchemaField
+		fe.Message = e.Reason
+		*out = append(*out, fe)
+	case *openapi3filter.ParseError:
+		fe := base
+		fe.Rule = "parse"
+		fe.Message = e.Error()
+		*out = append(*out, fe)
+	default:
+		fe := base
+		if fe.Rule == "" {
+			fe.Rule = "invalid"
+		}
+		fe.Message = err.Error()
+		*out = append(*out, fe)
+	}
+}
+
+// collectCause records reason when there is no underlying error, otherwise descends into it.
+func collectCause(cause error, reason, rule string, base apix.FieldError, out *[]apix.FieldError) {
+	if cause == nil {
+		base.Rule = rule
+		base.Message = reason
+		*out = append(*out, base)
+		return
+	}
+	if reason != "" {
+		base.Message = reason
+	}
+	collectFieldErrors(cause, base, out)
+}
@@ -0,0 +1,204 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/openapi"
+)
+
+type invoiceResponse struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func invoiceRoute() (*apix.Registry, *apix.RouteRef) {
+	ref := &apix.RouteRef{
+		Method: apix.MethodGet,
+		Path:   "/invoices/:id",
+		Responses: map[int]*apix.ResponseRef{
+			http.StatusOK: {
+				ModelType:   reflect.TypeOf(invoiceResponse{}),
+				ContentType: "application/json",
+				Headers:     []apix.HeaderRef{{Name: "X-Request-Id", SchemaType: "string", Required: true}},
+			},
+			http.StatusNotFound: {ModelType: reflect.TypeOf(apix.ErrorResponse{})},
+		},
+	}
+	registry := apix.NewRegistry()
+	registry.Register(ref)
+	return registry, ref
+}
+
+func jsonHeader(extra ...string) http.Header {
+	h := http.Header{"Content-Type": []string{"application/json"}}
+	for i := 0; i+1 < len(extra); i += 2 {
+		h.Set(extra[i], extra[i+1])
+	}
+	return h
+}
+
+func responseViolations(t *testing.T, err error) []apix.FieldError {
+	t.Helper()
+	var problem *apix.ProblemDetails
+	if !errors.As(err, &problem) {
+		t.Fatalf("expected ProblemDetails, got %v", err)
+	}
+	if problem.Status != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", problem.Status)
+	}
+	violations, _ := problem.Extensions["errors"].([]apix.FieldError)
+	if len(violations) == 0 {
+		t.Fatalf("expected violations in %+v", problem)
+	}
+	return violations
+}
+
+func TestResponseValidatorAcceptsDocumentedResponse(t *testing.T) {
+	registry, ref := invoiceRoute()
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+
+	err := v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader("X-Request-Id", "abc"), []byte(`{"id":"inv-1","total":42}`))
+	if err != nil {
+		t.Fatalf("expected documented response to pass, got %v", err)
+	}
+	err = v.ValidateResponse(context.Background(), registry, ref, http.StatusNotFound, http.Header{"Content-Type": []string{"text/plain"}}, []byte("not found"))
+	if err != nil {
+		t.Fatalf("expected documented error status to pass, got %v", err)
+	}
+}
+
+func TestResponseValidatorReportsViolations(t *testing.T) {
+	registry, ref := invoiceRoute()
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+
+	violations := responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader("X-Request-Id", "abc"), []byte(`{"id":"inv-1","total":"42"}`)))
+	if violations[0].Path != "/total" || violations[0].Rule != "type" {
+		t.Fatalf("unexpected body violation %+v", violations)
+	}
+
+	violations = responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader(), []byte(`{"id":"inv-1","total":42}`)))
+	if !strings.Contains(violations[0].Message, "X-Request-Id") {
+		t.Fatalf("expected missing header violation, got %+v", violations)
+	}
+
+	violations = responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, http.StatusTeapot, jsonHeader(), nil))
+	if violations[0].Rule != "status" {
+		t.Fatalf("expected undocumented status violation, got %+v", violations)
+	}
+}
+
+func TestResponseValidatorLogsByDefault(t *testing.T) {
+	registry, ref := invoiceRoute()
+	var buf bytes.Buffer
+	v := &openapi.ResponseValidator{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+
+	err := v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader("X-Request-Id", "abc"), []byte(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("expected violations to be logged, got %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log entry, got %q", buf.String())
+	}
+	if entry["msg"] != "response does not match the API specification" || entry["path"] != "/invoices/:id" {
+		t.Fatalf("unexpected log entry %v", entry)
+	}
+}
+
+func TestResponseValidatorSeesLateRoutes(t *testing.T) {
+	registry, ref := invoiceRoute()
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+	if err := v.ValidateResponse(context.Background(), registry, ref, http.StatusNotFound, nil, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	late := &apix.RouteRef{Method: apix.MethodDelete, Path: "/invoices/:id", Responses: map[int]*apix.ResponseRef{http.StatusNoContent: {}}}
+	registry.Register(late)
+	if err := v.ValidateResponse(context.Background(), registry, late, http.StatusNoContent, nil, nil); err != nil {
+		t.Fatalf("expected late route to be documented, got %v", err)
+	}
+}
+
+func TestResponseValidatorResolvesComponentRefs(t *testing.T) {
+	ref := &apix.RouteRef{
+		Method:    apix.MethodGet,
+		Path:      "/transactions/latest",
+		Responses: map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(transaction{})}},
+	}
+	registry := apix.NewRegistry()
+	registry.Register(ref)
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+
+	err := v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader(), []byte(`{"type":"income","history":["expense"],"severity":1}`))
+	if err != nil {
+		t.Fatalf("expected enum values to pass, got %v", err)
+	}
+	violations := responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, http.StatusOK, jsonHeader(), []byte(`{"type":"gift","history":[],"severity":1}`)))
+	if violations[0].Path != "/type" || violations[0].Rule != "enum" {
+		t.Fatalf("unexpected enum violation %+v", violations)
+	}
+}
+
+func TestResponseValidatorAcceptsMissingDefaultLocation(t *testing.T) {
+	created := func(headers ...apix.HeaderRef) *apix.RouteRef {
+		return &apix.RouteRef{
+			Method: apix.MethodPost,
+			Path:   "/invoices",
+			Responses: map[int]*apix.ResponseRef{
+				http.StatusCreated: {ModelType: reflect.TypeOf(invoiceResponse{}), ContentType: "application/json", Headers: headers},
+			},
+		}
+	}
+	registry := apix.NewRegistry()
+	ref := created()
+	registry.Register(ref)
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+
+	doc, err := openapi.NewBuilder().BuildRegistry(registry)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if h := doc.Paths.Value("/invoices").Post.Responses.Status(http.StatusCreated).Value.Headers["Location"]; h == nil || !h.Value.Required {
+		t.Fatalf("expected the documented Location header to stay required, got %+v", h)
+	}
+	if err := v.ValidateResponse(context.Background(), registry, ref, http.StatusCreated, jsonHeader(), []byte(`{"id":"inv-1","total":42}`)); err != nil {
+		t.Fatalf("expected a missing default Location header to pass, got %v", err)
+	}
+
+	registry = apix.NewRegistry()
+	ref = created(apix.HeaderRef{Name: "Location", SchemaType: "string", Required: true})
+	ref.Path = "/declared"
+	registry.Register(ref)
+	violations := responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, http.StatusCreated, jsonHeader(), []byte(`{"id":"inv-1","total":42}`)))
+	if !strings.Contains(violations[0].Message, "Location") {
+		t.Fatalf("expected a declared Location header to be required, got %+v", violations)
+	}
+}
+
+func TestResponseValidatorAcceptsUndocumentedRejections(t *testing.T) {
+	registry, ref := invoiceRoute()
+	v := &openapi.ResponseValidator{FailOnViolation: true}
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnprocessableEntity} {
+		if err := v.ValidateRejection(context.Background(), registry, ref, status, jsonHeader(), nil); err != nil {
+			t.Fatalf("expected rejected request answered with %d to pass, got %v", status, err)
+		}
+		violations := responseViolations(t, v.ValidateResponse(context.Background(), registry, ref, status, jsonHeader(), nil))
+		if violations[0].Rule != "status" {
+			t.Fatalf("expected handler response %d to be reported, got %+v", status, violations)
+		}
+	}
+	violations := responseViolations(t, v.ValidateRejection(context.Background(), registry, ref, http.StatusTeapot, jsonHeader(), nil))
+	if violations[0].Rule != "status" {
+		t.Fatalf("expected undocumented status violation, got %+v", violations)
+	}
+}
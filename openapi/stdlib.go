@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net"
+	"net/netip"
+	"reflect"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	ipType         = reflect.TypeOf(net.IP(nil))
+	addrType       = reflect.TypeOf(netip.Addr{})
+)
+
+// stdlibTypeSchema documents standard-library types whose Go representation differs from
+// their documented wire format.
+func stdlibTypeSchema(t reflect.Type) (*openapi3.Schema, bool) {
+	switch t {
+	case durationType:
+		// encoding/json writes time.Duration as its int64 nanosecond count.
+		s := openapi3.NewInt64Schema()
+		s.Description = "Duration in nanoseconds"
+		return s, true
+	case rawMessageType:
+		// Any JSON value.
+		return &openapi3.Schema{}, true
+	case ipType, addrType:
+		ipv4 := openapi3.NewStringSchema()
+		ipv4.Format = "ipv4"
+		ipv6 := openapi3.NewStringSchema()
+		ipv6.Format = "ipv6"
+		s := openapi3.NewStringSchema()
+		s.AnyOf = openapi3.SchemaRefs{schemaRef(ipv4), schemaRef(ipv6)}
+		return s, true
+	}
+	return nil, false
+}
@@ -0,0 +1,6 @@
+// Package models holds CRM fixtures for component naming tests.
+package models
+
+type User struct {
+	Email string `json:"email"`
+}
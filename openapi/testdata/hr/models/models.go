@@ -0,0 +1,6 @@
+// Package models holds HR fixtures for component naming tests.
+package models
+
+type User struct {
+	EmployeeID int `json:"employee_id"`
+}
@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// TypeSchemaFunc returns the schema documenting a Go type. It is called for every use of
+// the type, so it must return a new schema each time.
+type TypeSchemaFunc func() *openapi3.Schema
+
+// typeSchemaSet holds schema overrides keyed by type. Interface types apply to every type
+// implementing them and are tried in registration order.
+type typeSchemaSet struct {
+	schemas    map[reflect.Type]TypeSchemaFunc
+	interfaces []reflect.Type
+}
+
+func (s *typeSchemaSet) set(t reflect.Type, fn TypeSchemaFunc) {
+	if t == nil || fn == nil {
+		panic("apix/openapi: RegisterTypeSchema requires a type and a schema function")
+	}
+	if s.schemas == nil {
+		s.schemas = make(map[reflect.Type]TypeSchemaFunc)
+	}
+	if _, exists := s.schemas[t]; !exists && t.Kind() == reflect.Interface {
+		s.interfaces = append(s.interfaces, t)
+	}
+	s.schemas[t] = fn
+}
+
+func (s *typeSchemaSet) remove(t reflect.Type) {
+	delete(s.schemas, t)
+	for i, iface := range s.interfaces {
+		if iface == t {
+			s.interfaces = append(s.interfaces[:i:i], s.interfaces[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *typeSchemaSet) exact(t reflect.Type) (TypeSchemaFunc, bool) {
+	fn, ok := s.schemas[t]
+	return fn, ok
+}
+
+func (s *typeSchemaSet) implemented(t reflect.Type) (TypeSchemaFunc, bool) {
+	for _, iface := range s.interfaces {
+		if implements(t, iface) {
+			return s.schemas[iface], true
+		}
+	}
+	return nil, false
+}
+
+var typeSchemas = struct {
+	mu  sync.RWMutex
+	set typeSchemaSet
+}{}
+
+// RegisterTypeSchema documents every value of type t with the schema returned by fn, for
+// types the builder cannot describe by reflection, such as money.Amount or ulid.ULID.
+// Registering an interface type, such as json.Marshaler, applies fn to every type
+// implementing it that has no registration of its own. Registration replaces any earlier
+// one for t.
+//
+// Example:
+//
+//	openapi.RegisterTypeSchema(reflect.TypeOf(ulid.ULID{}), func() *openapi3.Schema {
+//		s := openapi3.NewStringSchema()
+//		s.Format = "ulid"
+//		return s
+//	})
+//
+// Types implementing encoding.TextMarshaler but not json.Marshaler are documented as
+// strings unless a registration says otherwise.
+func RegisterTypeSchema(t reflect.Type, fn TypeSchemaFunc) {
+	typeSchemas.mu.Lock()
+	defer typeSchemas.mu.Unlock()
+	typeSchemas.set.set(t, fn)
+}
+
+// UnregisterTypeSchema removes a registration made with RegisterTypeSchema.
+func UnregisterTypeSchema(t reflect.Type) {
+	typeSchemas.mu.Lock()
+	defer typeSchemas.mu.Unlock()
+	typeSchemas.set.remove(t)
+}
+
+// RegisterTypeSchema is like the package-level RegisterTypeSchema but applies to documents
+// built by b only. Builder registrations take precedence over package-level ones.
+func (b *Builder) RegisterTypeSchema(t reflect.Type, fn TypeSchemaFunc) {
+	b.typeSchemas.set(t, fn)
+}
+
+// exactTypeSchema returns the schema registered for t itself.
+func (b *Builder) exactTypeSchema(t reflect.Type) (*openapi3.Schema, bool) {
+	if fn, ok := b.typeSchemas.exact(t); ok {
+		return callTypeSchema(t, fn)
+	}
+	typeSchemas.mu.RLock()
+	fn, ok := typeSchemas.set.exact(t)
+	typeSchemas.mu.RUnlock()
+	if ok {
+		return callTypeSchema(t, fn)
+	}
+	return nil, false
+}
+
+// implementedTypeSchema returns the schema registered for an interface t implements, or a
+// string schema for text marshalers.
+func (b *Builder) implementedTypeSchema(t reflect.Type) (*openapi3.Schema, bool) {
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if fn, ok := b.typeSchemas.implemented(t); ok {
+		return callTypeSchema(t, fn)
+	}
+	typeSchemas.mu.RLock()
+	fn, ok := typeSchemas.set.implemented(t)
+	typeSchemas.mu.RUnlock()
+	if ok {
+		return callTypeSchema(t, fn)
+	}
+	// encoding/json writes text marshalers as strings unless they marshal JSON themselves.
+	if implements(t, textMarshalerType) && !implements(t, jsonMarshalerType) {
+		return openapi3.NewStringSchema(), true
+	}
+	return nil, false
+}
+
+func callTypeSchema(t reflect.Type, fn TypeSchemaFunc) (*openapi3.Schema, bool) {
+	schema := fn()
+	if schema == nil {
+		panic(fmt.Sprintf("apix/openapi: schema function for %s returned nil", t))
+	}
+	return schema, true
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// implements reports whether t or *t implements iface, matching encoding/json, which
+// uses pointer-receiver methods on addressable values.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(iface))
+}
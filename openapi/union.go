@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"reflect"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// buildUnionSchema documents a registered union interface as oneOf (or anyOf) its variants.
+// Variants are always component references so the discriminator mapping can point at them.
+func (b *Builder) buildUnionSchema(t reflect.Type, info apix.UnionInfo) (*openapi3.SchemaRef, error) {
+	schema := &openapi3.Schema{}
+	name, err := b.componentName(t)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		// Register before visiting variants so self-referencing unions resolve to a $ref.
+		b.doc.Components.Schemas[name] = schemaRef(schema)
+		b.schemaCache[t] = b.doc.Components.Schemas[name]
+	}
+
+	var mapping map[string]string
+	if info.Discriminator != "" {
+		mapping = map[string]string{}
+	}
+	for _, variant := range info.Variants {
+		ref, err := b.schemaRefFromType(derefType(variant.Type))
+		if err != nil {
+			return nil, err
+		}
+		variantName, err := b.componentName(derefType(variant.Type))
+		if err != nil {
+			return nil, err
+		}
+		if variantName != "" {
+			ref = &openapi3.SchemaRef{Ref: "#/components/schemas/" + variantName}
+		}
+		if info.AnyOf {
+			schema.AnyOf = append(schema.AnyOf, ref)
+		} else {
+			schema.OneOf = append(schema.OneOf, ref)
+		}
+		if mapping != nil && ref.Ref != "" {
+			mapping[variant.Name] = ref.Ref
+		}
+	}
+	if info.Discriminator != "" {
+		schema.Discriminator = &openapi3.Discriminator{PropertyName: info.Discriminator, Mapping: mapping}
+	}
+
+	if name == "" {
+		return schemaRef(schema), nil
+	}
+	if err := apix.ExecuteOnSchemaGenerate(name, schema); err != nil {
+		return nil, err
+	}
+	return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name}, nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
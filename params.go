@@ -0,0 +1,354 @@
+package apix
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Infra-Forge/infra-apix/internal/validation"
+)
+
+// Parameter locations recognised in request struct tags.
+const (
+	ParamInPath   = "path"
+	ParamInQuery  = "query"
+	ParamInHeader = "header"
+	ParamInCookie = "cookie"
+)
+
+var parameterTagKeys = []string{ParamInPath, ParamInQuery, ParamInHeader, ParamInCookie}
+
+// ParameterField describes a request struct field bound from the path, query string, headers or cookies
+// rather than from the request body.
+//
+// Example:
+//
+//	type GetItemRequest struct {
+//	    ID     string `path:"id"`
+//	    Limit  int    `query:"limit"`
+//	    Tenant string `header:"X-Tenant" validate:"required"`
+//	}
+type ParameterField struct {
+	Name        string
+	In          string
+	Required    bool
+	Description string
+	Example     string
+	Type        reflect.Type
+	Index       []int
+}
+
+// ParameterSource exposes raw request values to BindParameters.
+// Each framework adapter provides an implementation backed by its own request context.
+type ParameterSource interface {
+	PathValue(name string) (string, bool)
+	QueryValues(name string) []string
+	HeaderValues(name string) []string
+	CookieValue(name string) (string, bool)
+}
+
+var (
+	parameterFieldCache sync.Map // map[reflect.Type][]ParameterField
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ParameterFields returns the tagged parameter fields of t in declaration order.
+// Fields of embedded structs are included. Non-struct types yield nil.
+func ParameterFields(t reflect.Type) []ParameterField {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := parameterFieldCache.Load(t); ok {
+		return cached.([]ParameterField)
+	}
+	fields := collectParameterFields(t, nil)
+	parameterFieldCache.Store(t, fields)
+	return fields
+}
+
+func collectParameterFields(t reflect.Type, index []int) []ParameterField {
+	var fields []ParameterField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if in, name, ok := ParameterTag(field); ok {
+			if field.PkgPath != "" {
+				continue
+			}
+			fields = append(fields, ParameterField{
+				Name:        name,
+				In:          in,
+				Required:    in == ParamInPath || hasRequiredRule(field),
+				Description: field.Tag.Get("description"),
+				Example:     field.Tag.Get("example"),
+				Type:        field.Type,
+				Index:       fieldIndex,
+			})
+			continue
+		}
+
+		if field.Anonymous {
+			if ft := derefType(field.Type); ft.Kind() == reflect.Struct {
+				fields = append(fields, collectParameterFields(ft, fieldIndex)...)
+			}
+		}
+	}
+	return fields
+}
+
+// ParameterTag reports whether field carries a path, query, header or cookie tag and returns its location and name.
+func ParameterTag(field reflect.StructField) (in string, name string, ok bool) {
+	for _, key := range parameterTagKeys {
+		tag, found := field.Tag.Lookup(key)
+		if !found {
+			continue
+		}
+		name = strings.Split(tag, ",")[0]
+		if name == "-" {
+			return "", "", false
+		}
+		if name == "" {
+			name = field.Name
+		}
+		return key, name, true
+	}
+	return "", "", false
+}
+
+// HasBodyFields reports whether t carries any data decoded from the request body.
+// Structs whose exported fields are all parameter-tagged (or skipped with json:"-") have no body.
+func HasBodyFields(t reflect.Type) bool {
+	t = derefType(t)
+	if t == nil {
+		return false
+	}
+	if t.Kind() != reflect.Struct || len(ParameterFields(t)) == 0 {
+		return true
+	}
+	return structHasBodyFields(t)
+}
+
+func structHasBodyFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, _, ok := ParameterTag(field); ok {
+			continue
+		}
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+		if field.Anonymous {
+			if ft := derefType(field.Type); ft.Kind() == reflect.Struct {
+				if structHasBodyFields(ft) {
+					return true
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// BindParameters copies path, query, header and cookie values from src into the tagged fields of dst.
+// dst must be a pointer to a struct. Conversion failures are reported as 400 Bad Request errors.
+func BindParameters(dst any, src ParameterSource) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+	fields := ParameterFields(rv.Type())
+	if len(fields) == 0 {
+		return nil
+	}
+	root := rv.Elem()
+
+	for _, pf := range fields {
+		values := lookupParameter(src, pf)
+		if len(values) == 0 {
+			if pf.Required {
+				return parameterError(pf, "is required")
+			}
+			continue
+		}
+		target := fieldByIndexAlloc(root, pf.Index)
+		if err := setParameterValue(target, values); err != nil {
+			return parameterError(pf, err.Error())
+		}
+	}
+	return nil
+}
+
+func lookupParameter(src ParameterSource, pf ParameterField) []string {
+	switch pf.In {
+	case ParamInPath:
+		if v, ok := src.PathValue(pf.Name); ok && v != "" {
+			return []string{v}
+		}
+	case ParamInQuery:
+		return src.QueryValues(pf.Name)
+	case ParamInHeader:
+		return src.HeaderValues(pf.Name)
+	case ParamInCookie:
+		if v, ok := src.CookieValue(pf.Name); ok {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+func parameterError(pf ParameterField, reason string) error {
+	return &HTTPError{
+		Status:  http.StatusBadRequest,
+		Message: fmt.Sprintf("%s parameter %q %s", pf.In, pf.Name, reason),
+		Code:    "INVALID_PARAMETER",
+	}
+}
+
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
+
+func setParameterValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setParameterValue(elem.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0])); err != nil {
+			return fmt.Errorf("is invalid: %v", err)
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		items := splitParameterValues(values)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setParameterValue(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	return setScalar(v, values[0])
+}
+
+// splitParameterValues accepts both repeated (?tag=a&tag=b) and comma-separated (?tag=a,b) encodings.
+func splitParameterValues(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func setScalar(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("has unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func hasRequiredRule(field reflect.StructField) bool {
+	return validation.FieldRules(field).Has("required")
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// requestSource adapts *http.Request to ParameterSource.
+type requestSource struct {
+	r         *http.Request
+	pathValue func(string) string
+}
+
+// NewRequestSource returns a ParameterSource backed by a net/http request.
+// pathValue resolves router path parameters; when nil, http.Request.PathValue is used.
+func NewRequestSource(r *http.Request, pathValue func(name string) string) ParameterSource {
+	if pathValue == nil {
+		pathValue = r.PathValue
+	}
+	return &requestSource{r: r, pathValue: pathValue}
+}
+
+func (s *requestSource) PathValue(name string) (string, bool) {
+	v := s.pathValue(name)
+	return v, v != ""
+}
+
+func (s *requestSource) QueryValues(name string) []string {
+	return s.r.URL.Query()[name]
+}
+
+func (s *requestSource) HeaderValues(name string) []string {
+	return s.r.Header.Values(name)
+}
+
+func (s *requestSource) CookieValue(name string) (string, bool) {
+	c, err := s.r.Cookie(name)
+	if err != nil {
+		return "", false
+	}
+	return c.Value, true
+}
@@ -0,0 +1,131 @@
+package apix_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/google/uuid"
+)
+
+type paramsRequest struct {
+	ID      uuid.UUID `path:"id"`
+	Limit   int       `query:"limit"`
+	Tags    []string  `query:"tag"`
+	Verbose *bool     `query:"verbose"`
+	Tenant  string    `header:"X-Tenant" validate:"required"`
+	Session string    `cookie:"session"`
+	Name    string    `json:"name"`
+}
+
+type paramsOnlyRequest struct {
+	ID    string `path:"id"`
+	Limit int    `query:"limit"`
+}
+
+func TestParameterFieldsFromTags(t *testing.T) {
+	fields := apix.ParameterFields(reflect.TypeOf(paramsRequest{}))
+	if len(fields) != 6 {
+		t.Fatalf("expected 6 parameter fields, got %d", len(fields))
+	}
+
+	byName := map[string]apix.ParameterField{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	if f := byName["id"]; f.In != apix.ParamInPath || !f.Required {
+		t.Fatalf("expected required path parameter, got %+v", f)
+	}
+	if f := byName["X-Tenant"]; f.In != apix.ParamInHeader || !f.Required {
+		t.Fatalf("expected required header parameter, got %+v", f)
+	}
+	if f := byName["limit"]; f.Required {
+		t.Fatalf("expected optional query parameter")
+	}
+
+	if !apix.HasBodyFields(reflect.TypeOf(paramsRequest{})) {
+		t.Fatalf("expected body fields for mixed request")
+	}
+	if apix.HasBodyFields(reflect.TypeOf(paramsOnlyRequest{})) {
+		t.Fatalf("expected parameter-only request to have no body")
+	}
+}
+
+func TestBindParametersConvertsValues(t *testing.T) {
+	id := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/items?limit=25&tag=a&tag=b,c&verbose=true", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s-1"})
+
+	var dst paramsRequest
+	src := apix.NewRequestSource(req, func(name string) string {
+		if name == "id" {
+			return id.String()
+		}
+		return ""
+	})
+	if err := apix.BindParameters(&dst, src); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+
+	if dst.ID != id || dst.Limit != 25 || dst.Tenant != "acme" || dst.Session != "s-1" {
+		t.Fatalf("unexpected binding result: %+v", dst)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected tags: %v", dst.Tags)
+	}
+	if dst.Verbose == nil || !*dst.Verbose {
+		t.Fatalf("expected verbose pointer to be set")
+	}
+}
+
+func TestBindParametersErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+	}{
+		{"invalid integer", "/items?limit=abc"},
+		{"missing required header", "/items?limit=1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.name != "missing required header" {
+				req.Header.Set("X-Tenant", "acme")
+			}
+			src := apix.NewRequestSource(req, func(string) string { return uuid.NewString() })
+
+			err := apix.BindParameters(&paramsRequest{}, src)
+			var httpErr *apix.HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected HTTPError, got %v", err)
+			}
+			if httpErr.Status != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", httpErr.Status)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONSkipsParameterFields(t *testing.T) {
+	body := `{"Tenant":"evil","ID":"00000000-0000-0000-0000-000000000001","Limit":5}`
+
+	var req paramsRequest
+	if err := apix.DecodeJSON(json.NewDecoder(strings.NewReader(body)), &req, false); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if req.Tenant != "" || req.Limit != 0 || req.ID != uuid.Nil {
+		t.Fatalf("expected parameter fields to stay unset, got %+v", req)
+	}
+
+	err := apix.DecodeJSON(json.NewDecoder(strings.NewReader(body)), &paramsRequest{}, true)
+	if err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Fatalf("expected parameter keys to be unknown in strict mode, got %v", err)
+	}
+}
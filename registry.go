@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -12,15 +13,20 @@ import (
 	"github.com/Infra-Forge/infra-apix/internal/logging"
 )
 
-// RouteMethod represents an HTTP method for an endpoint.
+// RouteMethod represents an HTTP method for an endpoint. Methods other than the constants
+// below (such as WebDAV's PROPFIND) may be registered too; see Builder for how they are
+// documented.
 type RouteMethod string
 
 const (
-	MethodGet    RouteMethod = http.MethodGet
-	MethodPost   RouteMethod = http.MethodPost
-	MethodPut    RouteMethod = http.MethodPut
-	MethodPatch  RouteMethod = http.MethodPatch
-	MethodDelete RouteMethod = http.MethodDelete
+	MethodGet     RouteMethod = http.MethodGet
+	MethodPost    RouteMethod = http.MethodPost
+	MethodPut     RouteMethod = http.MethodPut
+	MethodPatch   RouteMethod = http.MethodPatch
+	MethodDelete  RouteMethod = http.MethodDelete
+	MethodHead    RouteMethod = http.MethodHead
+	MethodOptions RouteMethod = http.MethodOptions
+	MethodTrace   RouteMethod = http.MethodTrace
 )
 
 // HandlerFunc is the canonical typed handler signature. The request pointer may be nil when the route has no body.
@@ -48,19 +54,40 @@ type RouteRef struct {
 	// Responses keyed by HTTP status code.
 	Responses map[int]*ResponseRef
 
-	// Security requirements (e.g., BearerAuth).
+	// Security requirements (e.g., BearerAuth). Each entry is an alternative.
 	Security []SecurityRequirement
+	// MiddlewareSecurity holds the requirements enforced by middleware wrapping the route,
+	// from MiddlewareSecurity and WithMiddleware. The middleware always runs, so these apply
+	// together, in addition to one of the Security alternatives.
+	MiddlewareSecurity []SecurityRequirement
+	// AllowUnverifiedSecurity lets requests presenting no credentials for a verified Security
+	// alternative through without a principal when another alternative is documentation only.
+	AllowUnverifiedSecurity bool
 
 	// Custom headers expected in success responses.
 	SuccessHeaders map[int][]HeaderRef
 	SuccessStatus  int
+	// Media type of success responses, from WithResponseContentType.
+	SuccessContentType string
+	// Further statuses a handler returning Response may send, from WithResponseStatus.
+	ResponseStatuses []int
 
 	// Request body requirements
 	BodyRequired bool
 
+	// Streaming marks server-sent event routes, whose success response is text/event-stream.
+	Streaming bool
+
 	// Parameter metadata (path/query/header)
 	Parameters []Parameter
 
+	// Interceptors run around the handler, from WithInterceptors. They are not documented.
+	Interceptors []Interceptor
+
+	// Middleware wraps the handler, from WithMiddleware. Adapters apply values of their
+	// framework's middleware type.
+	Middleware []any
+
 	// Underlying handler reflection info (for debugging / advanced extensions).
 	HandlerType reflect.Type
 }
@@ -97,47 +124,58 @@ type Parameter struct {
 type SecurityRequirement struct {
 	Name   string
 	Scopes []string
+	// Scheme is set by SecurityScheme.Require and enforced by adapters. Requirements
+	// added by name are enforced when a scheme of that name is registered.
+	Scheme *SecurityScheme
 }
 
-type routeRegistry struct {
-	mu     sync.RWMutex
-	routes []*RouteRef
+// Registry stores route metadata for one API surface. Each Registry produces its own
+// OpenAPI document, so a single binary can serve several independent APIs.
+// The zero value is ready to use.
+type Registry struct {
+	mu      sync.RWMutex
+	routes  []*RouteRef
+	schemes map[string]*SecurityScheme
 }
 
-var globalRegistry = &routeRegistry{}
+// NewRegistry returns an empty, isolated route registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
 
-// ResetRegistry clears registry content (primarily for tests/CLI runs).
-func ResetRegistry() {
-	globalRegistry.mu.Lock()
-	defer globalRegistry.mu.Unlock()
-	globalRegistry.routes = nil
+var globalRegistry = NewRegistry()
+
+// DefaultRegistry returns the package-level registry used by RegisterRoute, Snapshot and ResetRegistry.
+// Adapters write to it unless configured with their own Registry.
+func DefaultRegistry() *Registry {
+	return globalRegistry
 }
 
-// RegisterRoute registers a new route metadata entry.
+// Register adds a route metadata entry.
 // Executes plugin hooks before adding the route to the registry.
-func RegisterRoute(ref *RouteRef) {
+func (r *Registry) Register(ref *RouteRef) {
 	// Execute plugin hooks before registration
 	if err := executeOnRouteRegister(ref); err != nil {
 		panic(fmt.Sprintf("apix: plugin hook failed during route registration: %v", err))
 	}
 
-	globalRegistry.mu.Lock()
-	defer globalRegistry.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if ref.SuccessStatus == 0 {
 		ref.SuccessStatus = DefaultSuccessStatus(ref.Method)
 	}
-	globalRegistry.routes = append(globalRegistry.routes, ref)
+	r.routes = append(r.routes, ref)
 
 	// Log route registration
 	logging.GetLogger().RouteRegistered(string(ref.Method), ref.Path, "summary", ref.Summary)
 }
 
 // Snapshot returns a copy of registered routes sorted by path+method for deterministic output.
-func Snapshot() []*RouteRef {
-	globalRegistry.mu.RLock()
-	defer globalRegistry.mu.RUnlock()
-	out := make([]*RouteRef, len(globalRegistry.routes))
-	copy(out, globalRegistry.routes)
+func (r *Registry) Snapshot() []*RouteRef {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*RouteRef, len(r.routes))
+	copy(out, r.routes)
 	sort.Slice(out, func(i, j int) bool {
 		if out[i].Path == out[j].Path {
 			return strings.Compare(string(out[i].Method), string(out[j].Method)) < 0
@@ -147,6 +185,29 @@ func Snapshot() []*RouteRef {
 	return out
 }
 
+// Reset clears registry content.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = nil
+	r.schemes = nil
+}
+
+// ResetRegistry clears the default registry (primarily for tests/CLI runs).
+func ResetRegistry() {
+	globalRegistry.Reset()
+}
+
+// RegisterRoute registers a new route metadata entry in the default registry.
+func RegisterRoute(ref *RouteRef) {
+	globalRegistry.Register(ref)
+}
+
+// Snapshot returns the sorted routes of the default registry.
+func Snapshot() []*RouteRef {
+	return globalRegistry.Snapshot()
+}
+
 // Options -------------------------------------------------------------------
 
 type RouteOption func(*RouteRef)
@@ -187,6 +248,14 @@ func WithSecurity(name string, scopes ...string) RouteOption {
 	}
 }
 
+// AllowUnverifiedSecurity opts the route into passing requests that present no credentials
+// for any verified Security alternative when another alternative has no Verifier, leaving
+// that one to middleware or the handler. Without it such requests are rejected with 401.
+// Credentials presented for a verified alternative are checked either way.
+func AllowUnverifiedSecurity() RouteOption {
+	return func(r *RouteRef) { r.AllowUnverifiedSecurity = true }
+}
+
 // WithSuccessHeaders documents headers for the given status code.
 func WithSuccessHeaders(status int, headers ...HeaderRef) RouteOption {
 	return func(r *RouteRef) {
@@ -372,6 +441,36 @@ func DefaultOperationID(method RouteMethod, path string) string {
 	return defaultOperationID(method, path)
 }
 
+// HeadRoute returns the metadata of the HEAD route derived from a GET route: the same
+// parameters, statuses and headers, documented without response bodies. The operation ID
+// gains a "_head" suffix when get carries a custom one. The HEAD route gets its own copies
+// of get's slices, so options applied to one route do not change the other.
+func HeadRoute(get *RouteRef) *RouteRef {
+	head := *get
+	head.Method = MethodHead
+	if get.OperationID == "" || get.OperationID == DefaultOperationID(get.Method, get.Path) {
+		head.OperationID = DefaultOperationID(MethodHead, get.Path)
+	} else {
+		head.OperationID = get.OperationID + "_head"
+	}
+	head.Tags = slices.Clone(get.Tags)
+	head.Security = slices.Clone(get.Security)
+	head.MiddlewareSecurity = slices.Clone(get.MiddlewareSecurity)
+	head.ResponseStatuses = slices.Clone(get.ResponseStatuses)
+	head.Parameters = slices.Clone(get.Parameters)
+	head.Interceptors = slices.Clone(get.Interceptors)
+	head.Middleware = slices.Clone(get.Middleware)
+	head.Responses = make(map[int]*ResponseRef, len(get.Responses))
+	for status, resp := range get.Responses {
+		head.Responses[status] = resp
+	}
+	head.SuccessHeaders = make(map[int][]HeaderRef, len(get.SuccessHeaders))
+	for status, headers := range get.SuccessHeaders {
+		head.SuccessHeaders[status] = slices.Clone(headers)
+	}
+	return &head
+}
+
 func DefaultSuccessStatus(method RouteMethod) int {
 	switch method {
 	case MethodPost:
@@ -107,3 +107,90 @@ func TestEnsureResponse(t *testing.T) {
 		t.Fatalf("expected header applied")
 	}
 }
+
+func TestRegistryInstancesAreIsolated(t *testing.T) {
+	t.Parallel()
+
+	public := apix.NewRegistry()
+	admin := apix.NewRegistry()
+
+	public.Register(&apix.RouteRef{Method: apix.MethodGet, Path: "/items"})
+	public.Register(&apix.RouteRef{Method: apix.MethodPost, Path: "/items"})
+	admin.Register(&apix.RouteRef{Method: apix.MethodDelete, Path: "/admin/items"})
+
+	if got := len(public.Snapshot()); got != 2 {
+		t.Fatalf("expected 2 public routes, got %d", got)
+	}
+	adminRoutes := admin.Snapshot()
+	if len(adminRoutes) != 1 || adminRoutes[0].Path != "/admin/items" {
+		t.Fatalf("unexpected admin routes: %+v", adminRoutes)
+	}
+	if adminRoutes[0].SuccessStatus != http.StatusNoContent {
+		t.Fatalf("expected default success status to be applied")
+	}
+
+	public.Reset()
+	if len(public.Snapshot()) != 0 {
+		t.Fatalf("expected reset registry to be empty")
+	}
+	if len(admin.Snapshot()) != 1 {
+		t.Fatalf("reset must not affect other registries")
+	}
+}
+
+func TestDefaultRegistryBacksGlobalFunctions(t *testing.T) {
+	apix.ResetRegistry()
+	apix.RegisterRoute(&apix.RouteRef{Method: apix.MethodGet, Path: "/global"})
+
+	if len(apix.DefaultRegistry().Snapshot()) != 1 {
+		t.Fatalf("expected RegisterRoute to write to the default registry")
+	}
+	apix.DefaultRegistry().Reset()
+	if len(apix.Snapshot()) != 0 {
+		t.Fatalf("expected Snapshot to read the default registry")
+	}
+}
+
+func TestHeadRouteCopiesGetMetadata(t *testing.T) {
+	get := &apix.RouteRef{
+		Method:         apix.MethodGet,
+		Path:           "/items/{id}",
+		Summary:        "Get item",
+		Responses:      map[int]*apix.ResponseRef{http.StatusOK: {ModelType: reflect.TypeOf(sampleResp{})}},
+		SuccessHeaders: map[int][]apix.HeaderRef{http.StatusOK: {{Name: "ETag", SchemaType: "string"}}},
+		SuccessStatus:  http.StatusOK,
+	}
+	get.OperationID = apix.DefaultOperationID(get.Method, get.Path)
+
+	head := apix.HeadRoute(get)
+	if head.Method != apix.MethodHead || head.OperationID != "head_items_id" || head.Summary != "Get item" {
+		t.Fatalf("unexpected head route %+v", head)
+	}
+	if len(head.SuccessHeaders[http.StatusOK]) != 1 || head.Responses[http.StatusOK] == nil {
+		t.Fatalf("expected responses and headers to be copied")
+	}
+	head.Responses[http.StatusNotFound] = &apix.ResponseRef{}
+	if _, ok := get.Responses[http.StatusNotFound]; ok {
+		t.Fatalf("expected head responses not to alias the GET route")
+	}
+
+	get.Tags = append(make([]string, 0, 4), "items")
+	get.Security = append(make([]apix.SecurityRequirement, 0, 4), apix.SecurityRequirement{Name: "BearerAuth"})
+	head = apix.HeadRoute(get)
+	apix.WithTags("head-only")(head)
+	apix.WithSecurity("ApiKey")(head)
+	head.Tags[0] = "renamed"
+	apix.WithTags("get-only")(get)
+	apix.WithSecurity("SessionCookie")(get)
+	if !reflect.DeepEqual(get.Tags, []string{"items", "get-only"}) || get.Security[1].Name != "SessionCookie" {
+		t.Fatalf("expected GET slices to be unaffected by the HEAD route, got %v %v", get.Tags, get.Security)
+	}
+	if !reflect.DeepEqual(head.Tags, []string{"renamed", "head-only"}) || head.Security[1].Name != "ApiKey" {
+		t.Fatalf("expected HEAD slices to be unaffected by the GET route, got %v %v", head.Tags, head.Security)
+	}
+
+	get.OperationID = "getItem"
+	if got := apix.HeadRoute(get).OperationID; got != "getItem_head" {
+		t.Fatalf("expected custom operation ID to gain a suffix, got %s", got)
+	}
+}
@@ -0,0 +1,160 @@
+package apix
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// Response lets a handler choose the status code, headers and cookies sent with its body.
+// Return it as the handler's response type; the body type T is what the OpenAPI document
+// describes.
+//
+// Example:
+//
+//	func createUser(ctx context.Context, req *CreateUser) (apix.Response[User], error) {
+//	    user := save(req)
+//	    return apix.Created(user, "/users/"+user.ID), nil
+//	}
+//
+// A zero Status sends the route's success status. Statuses other than the success status
+// should be declared with WithResponseStatus so they appear in the document.
+type Response[T any] struct {
+	Status  int
+	Headers http.Header
+	Cookies []*http.Cookie
+	Body    T
+}
+
+// NewResponse returns a Response sending body with the given status.
+func NewResponse[T any](status int, body T) Response[T] {
+	return Response[T]{Status: status, Body: body}
+}
+
+// Created returns a 201 Response with the Location header set.
+func Created[T any](body T, location string) Response[T] {
+	return NewResponse(http.StatusCreated, body).WithHeader("Location", location)
+}
+
+// WithHeader returns a copy of r with the header value added.
+func (r Response[T]) WithHeader(key, value string) Response[T] {
+	headers := r.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Add(key, value)
+	r.Headers = headers
+	return r
+}
+
+// WithCookie returns a copy of r that also sets cookie.
+func (r Response[T]) WithCookie(cookie *http.Cookie) Response[T] {
+	r.Cookies = append(r.Cookies[:len(r.Cookies):len(r.Cookies)], cookie)
+	return r
+}
+
+func (r Response[T]) parts() (int, http.Header, []*http.Cookie, any) {
+	return r.Status, r.Headers, r.Cookies, r.Body
+}
+
+func (Response[T]) bodyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+type responseEnvelope interface {
+	parts() (int, http.Header, []*http.Cookie, any)
+	bodyType() reflect.Type
+}
+
+var responseEnvelopeType = reflect.TypeOf((*responseEnvelope)(nil)).Elem()
+
+// ResponseBodyType returns the body type documented for a handler response type: T for
+// Response[T] or *Response[T], t otherwise. Pointer body types are dereferenced.
+func ResponseBodyType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Pointer && t.Elem().Implements(responseEnvelopeType) {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer || !t.Implements(responseEnvelopeType) {
+		return t
+	}
+	body := reflect.Zero(t).Interface().(responseEnvelope).bodyType()
+	if body.Kind() == reflect.Pointer {
+		body = body.Elem()
+	}
+	return body
+}
+
+// ResolveResponse splits a handler result into what adapters write. Results that are not
+// a Response are sent as the body with defaultStatus and no extra headers. For a Response,
+// the returned header holds its headers plus one Set-Cookie value per cookie.
+func ResolveResponse(result any, defaultStatus int) (status int, header http.Header, body any, err error) {
+	env, ok := result.(responseEnvelope)
+	if !ok {
+		return defaultStatus, nil, result, nil
+	}
+	if v := reflect.ValueOf(result); v.Kind() == reflect.Pointer && v.IsNil() {
+		return defaultStatus, nil, nil, nil
+	}
+
+	status, headers, cookies, body := env.parts()
+	if status == 0 {
+		status = defaultStatus
+	}
+	if status < 100 || status > 999 {
+		return 0, nil, nil, fmt.Errorf("apix: invalid response status %d", status)
+	}
+	header = headers.Clone()
+	for _, cookie := range cookies {
+		if cookie == nil {
+			continue
+		}
+		if err := cookie.Valid(); err != nil {
+			return 0, nil, nil, fmt.Errorf("apix: invalid response cookie: %w", err)
+		}
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Add("Set-Cookie", cookie.String())
+	}
+	return status, header, body, nil
+}
+
+// WithResponseStatus declares that the handler may also answer with status by returning a
+// Response. The documented body is the handler's body type, or none for 204 No Content.
+func WithResponseStatus(status int, opts ...ResponseOption) RouteOption {
+	return func(r *RouteRef) {
+		if status < 100 {
+			return
+		}
+		r.ResponseStatuses = append(r.ResponseStatuses, status)
+		EnsureResponse(r, status, nil, opts...)
+	}
+}
+
+// EnsureSuccessResponses records the handler's body type for the success status and every
+// status declared with WithResponseStatus. Adapters call it once options are applied.
+// Binary bodies (File, RawBody) are documented under SuccessContentType, or
+// application/octet-stream when none was declared.
+func EnsureSuccessResponses(r *RouteRef, bodyType reflect.Type) {
+	ensureSuccessResponse(r, r.SuccessStatus, bodyType)
+	for _, status := range r.ResponseStatuses {
+		if status == http.StatusNoContent {
+			continue
+		}
+		ensureSuccessResponse(r, status, bodyType)
+	}
+}
+
+func ensureSuccessResponse(r *RouteRef, status int, bodyType reflect.Type) {
+	EnsureResponse(r, status, bodyType)
+	resp := r.Responses[status]
+	switch {
+	case r.SuccessContentType != "":
+		resp.ContentType = r.SuccessContentType
+	case IsBinaryType(resp.ModelType) && resp.ContentType == "application/json":
+		resp.ContentType = ContentTypeOctetStream
+	}
+}
@@ -0,0 +1,102 @@
+package apix_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func TestResolveResponsePassesPlainResultsThrough(t *testing.T) {
+	body := sampleResp{ID: "1"}
+	status, header, got, err := apix.ResolveResponse(body, http.StatusOK)
+	if err != nil || status != http.StatusOK || header != nil || got != body {
+		t.Fatalf("unexpected resolution: %d %v %v %v", status, header, got, err)
+	}
+}
+
+func TestResolveResponseUnwrapsEnvelope(t *testing.T) {
+	resp := apix.Created(sampleResp{ID: "1"}, "/items/1").
+		WithCookie(&http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+
+	status, header, body, err := apix.ResolveResponse(&resp, http.StatusOK)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if header.Get("Location") != "/items/1" || header.Get("Set-Cookie") != "session=abc; HttpOnly" {
+		t.Fatalf("unexpected headers: %v", header)
+	}
+	if body != (sampleResp{ID: "1"}) {
+		t.Fatalf("expected unwrapped body, got %#v", body)
+	}
+	if resp.Headers.Get("Set-Cookie") != "" {
+		t.Fatalf("cookies must not be written into the response's own headers")
+	}
+}
+
+func TestResolveResponseDefaultsAndErrors(t *testing.T) {
+	status, _, _, err := apix.ResolveResponse(apix.Response[sampleResp]{}, http.StatusAccepted)
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("expected route status for zero Status, got %d (%v)", status, err)
+	}
+	var missing *apix.Response[sampleResp]
+	if status, _, body, err := apix.ResolveResponse(missing, http.StatusOK); err != nil || status != http.StatusOK || body != nil {
+		t.Fatalf("expected nil response to send no body, got %d %v %v", status, body, err)
+	}
+	if _, _, _, err := apix.ResolveResponse(apix.NewResponse(42, sampleResp{}), http.StatusOK); err == nil {
+		t.Fatalf("expected invalid status error")
+	}
+	bad := apix.Response[sampleResp]{}.WithCookie(&http.Cookie{Name: "bad name", Value: "x"})
+	if _, _, _, err := apix.ResolveResponse(bad, http.StatusOK); err == nil {
+		t.Fatalf("expected invalid cookie error")
+	}
+}
+
+func TestResponseWithHeaderDoesNotShareHeaders(t *testing.T) {
+	base := apix.NewResponse(http.StatusOK, sampleResp{}).WithHeader("ETag", `"a"`)
+	other := base.WithHeader("ETag", `"b"`)
+	if got := base.Headers.Values("ETag"); len(got) != 1 {
+		t.Fatalf("expected base response untouched, got %v", got)
+	}
+	if got := other.Headers.Values("ETag"); len(got) != 2 {
+		t.Fatalf("expected both values on the copy, got %v", got)
+	}
+}
+
+func TestResponseBodyType(t *testing.T) {
+	want := reflect.TypeOf(sampleResp{})
+	cases := []reflect.Type{
+		reflect.TypeOf(sampleResp{}),
+		reflect.TypeOf(apix.Response[sampleResp]{}),
+		reflect.TypeOf(&apix.Response[sampleResp]{}),
+		reflect.TypeOf(apix.Response[*sampleResp]{}),
+	}
+	for _, tc := range cases {
+		if got := apix.ResponseBodyType(tc); got != want {
+			t.Fatalf("ResponseBodyType(%s) = %v", tc, got)
+		}
+	}
+}
+
+func TestWithResponseStatusDeclaresAlternatives(t *testing.T) {
+	ref := &apix.RouteRef{Method: apix.MethodPut, Path: "/items/{id}", SuccessStatus: http.StatusOK}
+	apix.WithResponseStatus(http.StatusCreated, apix.WithDescriptionResponse("Created"))(ref)
+	apix.WithResponseStatus(http.StatusNoContent)(ref)
+	apix.EnsureSuccessResponses(ref, reflect.TypeOf(sampleResp{}))
+
+	for _, status := range []int{http.StatusOK, http.StatusCreated} {
+		if resp := ref.Responses[status]; resp == nil || resp.ModelType != reflect.TypeOf(sampleResp{}) {
+			t.Fatalf("expected %d to carry the body type", status)
+		}
+	}
+	if ref.Responses[http.StatusCreated].Description != "Created" {
+		t.Fatalf("expected response options applied")
+	}
+	if resp := ref.Responses[http.StatusNoContent]; resp == nil || resp.ModelType != nil {
+		t.Fatalf("expected bodiless 204 alternative")
+	}
+}
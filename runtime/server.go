@@ -45,6 +45,9 @@ type Config struct {
 
 	// CustomizeBuilder allows additional tuning of the builder before building.
 	CustomizeBuilder func(*openapi.Builder)
+
+	// Registry selects the route registry documented by this handler. Default: apix.DefaultRegistry().
+	Registry *apix.Registry
 }
 
 // Handler serves OpenAPI docs with optional caching and Swagger UI.
@@ -163,14 +166,18 @@ func (h *Handler) getSpec(ctx context.Context) ([]byte, string, error) {
 	b := h.builderPool.Get().(*openapi.Builder)
 	defer h.builderPool.Put(b)
 
-	routes := apix.Snapshot()
+	registry := h.cfg.Registry
+	if registry == nil {
+		registry = apix.DefaultRegistry()
+	}
+	routes := registry.Snapshot()
 	if len(routes) == 0 {
 		return nil, "", errors.New("no routes registered")
 	}
 	// sort servers for deterministic output
 	sort.SliceStable(b.Servers, func(i, j int) bool { return b.Servers[i].URL < b.Servers[j].URL })
 
-	doc, err := b.Build(routes)
+	doc, err := b.BuildRegistry(registry)
 	if err != nil {
 		return nil, "", fmt.Errorf("build openapi: %w", err)
 	}
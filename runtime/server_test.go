@@ -142,3 +142,25 @@ func TestRegisterEcho(t *testing.T) {
 		t.Fatalf("expected swagger ui to respond 200, got %d", respUI.Code)
 	}
 }
+
+func TestHandlerServesConfiguredRegistry(t *testing.T) {
+	apix.ResetRegistry()
+	apix.RegisterRoute(&apix.RouteRef{Method: apix.MethodGet, Path: "/public", Responses: map[int]*apix.ResponseRef{http.StatusOK: {}}})
+
+	admin := apix.NewRegistry()
+	admin.Register(&apix.RouteRef{Method: apix.MethodGet, Path: "/admin/stats", Responses: map[int]*apix.ResponseRef{http.StatusOK: {}}})
+
+	h, err := runtime.NewHandler(runtime.Config{Format: "json", Registry: admin, SpecPath: "/admin/openapi.json"})
+	if err != nil {
+		t.Fatalf("new handler failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/openapi.json", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	body := resp.Body.String()
+	if !strings.Contains(body, "/admin/stats") || strings.Contains(body, "/public") {
+		t.Fatalf("expected spec to document only the admin registry, got %s", body)
+	}
+}
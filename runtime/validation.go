@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/internal/errorhandler"
+	"github.com/Infra-Forge/infra-apix/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const requestValidationErrorCode = "REQUEST_VALIDATION_FAILED"
+
+// RequestValidationConfig controls runtime request validation.
+type RequestValidationConfig struct {
+	// Registry selects the documented routes. Default: apix.DefaultRegistry().
+	Registry *apix.Registry
+
+	// CustomizeBuilder allows additional tuning of the builder before building.
+	// Servers added here become part of route matching.
+	CustomizeBuilder func(*openapi.Builder)
+
+	// FilterOptions are passed to openapi3filter. Default: report every failure, leave
+	// defaults unset and skip security requirements (enforced elsewhere).
+	FilterOptions *openapi3filter.Options
+}
+
+// RequestValidator checks live requests against the generated OpenAPI document.
+// The document is built once, on the first request, so routes registered after
+// the validator is created are still covered.
+type RequestValidator struct {
+	cfg RequestValidationConfig
+
+	once   sync.Once
+	router routers.Router
+	err    error
+}
+
+// NewRequestValidator returns a RequestValidator. Wire it in with Middleware (chi, mux,
+// net/http) or the ValidateRequests middleware of the echo, gin and fiber adapters.
+func NewRequestValidator(cfg RequestValidationConfig) *RequestValidator {
+	if cfg.FilterOptions == nil {
+		cfg.FilterOptions = &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		}
+	}
+	return &RequestValidator{cfg: cfg}
+}
+
+// Validate checks path, query, header and cookie parameters and the request body of r.
+// Requests that match no documented operation pass unchanged. Mismatches are returned as
+// *apix.ProblemDetails (400) listing each failure in the "errors" member.
+func (v *RequestValidator) Validate(r *http.Request) error {
+	v.once.Do(v.build)
+	if v.err != nil {
+		return apix.WrapError(v.err, http.StatusInternalServerError, "request validation unavailable")
+	}
+
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+			return nil
+		}
+		return apix.WrapError(err, http.StatusInternalServerError, "request validation failed")
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.cfg.FilterOptions,
+	}
+	if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+		return requestProblem(err)
+	}
+	return nil
+}
+
+// Middleware validates requests for net/http based routers such as chi and gorilla/mux.
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Validate(r); err != nil {
+			errorhandler.HandleError(w, r, err, true)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *RequestValidator) build() {
+	b := openapi.NewBuilder()
+	if v.cfg.CustomizeBuilder != nil {
+		v.cfg.CustomizeBuilder(b)
+	}
+	registry := v.cfg.Registry
+	if registry == nil {
+		registry = apix.DefaultRegistry()
+	}
+	doc, err := b.BuildRegistry(registry)
+	if err != nil {
+		v.err = fmt.Errorf("build openapi: %w", err)
+		return
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		v.err = fmt.Errorf("resolve openapi refs: %w", err)
+		return
+	}
+	v.router, v.err = gorillamux.NewRouter(doc)
+}
+
+// requestProblem converts openapi3filter errors into a Problem Details response.
+func requestProblem(err error) *apix.ProblemDetails {
+	problem := &apix.ProblemDetails{
+		Type:   "about:blank#" + requestValidationErrorCode,
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: "request does not match the API specification",
+	}
+	return problem.WithExtension("errors", openapi.FieldErrors(err))
+}
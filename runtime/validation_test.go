@@ -0,0 +1,116 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+	"github.com/Infra-Forge/infra-apix/runtime"
+)
+
+type widgetRequest struct {
+	ID    int    `path:"id"`
+	Limit int    `query:"limit" validate:"omitempty,lte=100"`
+	Name  string `json:"name" validate:"required,min=2"`
+}
+
+func widgetRegistry() *apix.Registry {
+	registry := apix.NewRegistry()
+	registry.Register(&apix.RouteRef{
+		Method:             apix.MethodPost,
+		Path:               "/widgets/{id}",
+		RequestType:        reflect.TypeOf(widgetRequest{}),
+		RequestContentType: "application/json",
+		BodyRequired:       true,
+		Responses:          map[int]*apix.ResponseRef{http.StatusCreated: {}},
+	})
+	return registry
+}
+
+type problemBody struct {
+	Status int               `json:"status"`
+	Type   string            `json:"type"`
+	Errors []apix.FieldError `json:"errors"`
+}
+
+func decodeProblem(t *testing.T, contentType string, body io.Reader) problemBody {
+	t.Helper()
+	if contentType != "application/problem+json" {
+		t.Fatalf("expected problem+json content type, got %q", contentType)
+	}
+	var problem problemBody
+	if err := json.NewDecoder(body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return problem
+}
+
+func badWidgetRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/widgets/abc?limit=500", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func goodWidgetRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/widgets/7?limit=5", strings.NewReader(`{"name":"widget"}`))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestRequestValidatorMiddleware(t *testing.T) {
+	validator := runtime.NewRequestValidator(runtime.RequestValidationConfig{Registry: widgetRegistry()})
+
+	var body string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusCreated)
+	})
+	h := validator.Middleware(next)
+
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, goodWidgetRequest())
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected valid request to pass, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if body != `{"name":"widget"}` {
+		t.Fatalf("expected body to be restored for the handler, got %q", body)
+	}
+
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, badWidgetRequest())
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", resp.Code, resp.Body.String())
+	}
+	problem := decodeProblem(t, resp.Header().Get("Content-Type"), resp.Body)
+	if problem.Type != "about:blank#REQUEST_VALIDATION_FAILED" {
+		t.Fatalf("unexpected problem type %q", problem.Type)
+	}
+	paths := map[string]bool{}
+	for _, fe := range problem.Errors {
+		paths[fe.Path] = true
+	}
+	for _, want := range []string{"/id", "/limit", "/name"} {
+		if !paths[want] {
+			t.Fatalf("expected an error for %s, got %+v", want, problem.Errors)
+		}
+	}
+}
+
+func TestRequestValidatorIgnoresUndocumentedRoutes(t *testing.T) {
+	validator := runtime.NewRequestValidator(runtime.RequestValidationConfig{Registry: widgetRegistry()})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest(http.MethodDelete, "/widgets/7", nil),
+	} {
+		if err := validator.Validate(req); err != nil {
+			t.Fatalf("expected %s %s to pass, got %v", req.Method, req.URL.Path, err)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package apix
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Security scheme types, as written in OpenAPI documents.
+const (
+	SecurityTypeHTTP          = "http"
+	SecurityTypeAPIKey        = "apiKey"
+	SecurityTypeOAuth2        = "oauth2"
+	SecurityTypeOpenIDConnect = "openIdConnect"
+	// SecurityTypeMutualTLS requires OpenAPI 3.1.
+	SecurityTypeMutualTLS = "mutualTLS"
+)
+
+// SecurityScheme ties a documented security scheme to the Verifier enforcing it. Create
+// one with a constructor such as HTTPBearer or APIKeyHeader, register it with
+// Registry.RegisterSecurityScheme and require it on routes with Require or WithSecurity:
+//
+//	bearer := apix.HTTPBearer("BearerAuth", "JWT", apix.VerifierFunc(verifyJWT))
+//	apix.RegisterSecurityScheme(bearer)
+//	chiadapter.Get(adapter, "/me", getProfile, bearer.Require())
+//
+// The builder adds the definition to components/securitySchemes, and adapters reject
+// requests to routes requiring the scheme unless the Verifier accepts their credentials.
+type SecurityScheme struct {
+	// Name keys the scheme in components/securitySchemes and in security requirements.
+	Name       string
+	Definition *openapi3.SecurityScheme
+	// Verifier authenticates requests. A nil Verifier leaves the scheme documentation only.
+	Verifier Verifier
+}
+
+// Credentials are what a request presented for a security scheme.
+type Credentials struct {
+	// Scheme is the name of the scheme being verified.
+	Scheme string
+	// Token is the bearer token, API key or access token. Empty for MutualTLS.
+	Token string
+	// Certificates is the client certificate chain for MutualTLS, leaf first.
+	Certificates []*x509.Certificate
+}
+
+// Principal is the identity a Verifier authenticated.
+type Principal struct {
+	Subject string
+	// Scheme is the name of the scheme that authenticated the request.
+	Scheme string
+	// Scopes are checked against the scopes routes require.
+	Scopes []string
+	Claims map[string]any
+}
+
+// HasScopes reports whether p holds every scope in scopes.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, want := range scopes {
+		found := false
+		for _, have := range p.Scopes {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Verifier authenticates credentials. Returning an error rejects the request with
+// 401 Unauthorized, unless the error is a StatusCoder carrying another status.
+type Verifier interface {
+	Verify(ctx context.Context, creds Credentials) (*Principal, error)
+}
+
+// VerifierFunc adapts a function to Verifier.
+type VerifierFunc func(ctx context.Context, creds Credentials) (*Principal, error)
+
+// Verify calls f.
+func (f VerifierFunc) Verify(ctx context.Context, creds Credentials) (*Principal, error) {
+	return f(ctx, creds)
+}
+
+// OAuthFlows configures the flows of an OAuth2 scheme. Set the ones the server supports.
+type OAuthFlows struct {
+	Implicit          *OAuthFlow
+	Password          *OAuthFlow
+	ClientCredentials *OAuthFlow
+	AuthorizationCode *OAuthFlow
+}
+
+// OAuthFlow describes one OAuth2 flow. Scopes maps scope names to descriptions.
+type OAuthFlow struct {
+	AuthorizationURL string
+	TokenURL         string
+	RefreshURL       string
+	Scopes           map[string]string
+}
+
+// HTTPBearer returns a scheme reading a bearer token from the Authorization header.
+// bearerFormat, such as "JWT", is documentation only and may be empty.
+func HTTPBearer(name, bearerFormat string, verifier Verifier) *SecurityScheme {
+	def := openapi3.NewSecurityScheme().WithType(SecurityTypeHTTP).WithScheme("bearer")
+	def.BearerFormat = bearerFormat
+	return &SecurityScheme{Name: name, Definition: def, Verifier: verifier}
+}
+
+// APIKeyHeader returns a scheme reading an API key from the named request header.
+func APIKeyHeader(name, header string, verifier Verifier) *SecurityScheme {
+	return apiKeyScheme(name, "header", header, verifier)
+}
+
+// APIKeyQuery returns a scheme reading an API key from the named query parameter.
+func APIKeyQuery(name, param string, verifier Verifier) *SecurityScheme {
+	return apiKeyScheme(name, "query", param, verifier)
+}
+
+// APIKeyCookie returns a scheme reading an API key from the named cookie.
+func APIKeyCookie(name, cookie string, verifier Verifier) *SecurityScheme {
+	return apiKeyScheme(name, "cookie", cookie, verifier)
+}
+
+func apiKeyScheme(name, in, key string, verifier Verifier) *SecurityScheme {
+	def := openapi3.NewSecurityScheme().WithType(SecurityTypeAPIKey).WithIn(in).WithName(key)
+	return &SecurityScheme{Name: name, Definition: def, Verifier: verifier}
+}
+
+// OAuth2 returns a scheme reading an access token from the Authorization header. The
+// verifier reports the token's scopes in Principal.Scopes.
+func OAuth2(name string, flows OAuthFlows, verifier Verifier) *SecurityScheme {
+	def := openapi3.NewSecurityScheme().WithType(SecurityTypeOAuth2)
+	def.Flows = &openapi3.OAuthFlows{
+		Implicit:          flows.Implicit.definition(),
+		Password:          flows.Password.definition(),
+		ClientCredentials: flows.ClientCredentials.definition(),
+		AuthorizationCode: flows.AuthorizationCode.definition(),
+	}
+	return &SecurityScheme{Name: name, Definition: def, Verifier: verifier}
+}
+
+func (f *OAuthFlow) definition() *openapi3.OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	scopes := f.Scopes
+	if scopes == nil {
+		scopes = map[string]string{}
+	}
+	return &openapi3.OAuthFlow{
+		AuthorizationURL: f.AuthorizationURL,
+		TokenURL:         f.TokenURL,
+		RefreshURL:       f.RefreshURL,
+		Scopes:           scopes,
+	}
+}
+
+// OpenIDConnect returns a scheme reading an ID or access token from the Authorization
+// header. discoveryURL is the provider's OpenID Connect discovery document.
+func OpenIDConnect(name, discoveryURL string, verifier Verifier) *SecurityScheme {
+	def := openapi3.NewSecurityScheme().WithType(SecurityTypeOpenIDConnect)
+	def.OpenIdConnectUrl = discoveryURL
+	return &SecurityScheme{Name: name, Definition: def, Verifier: verifier}
+}
+
+// MutualTLS returns a scheme authenticating the client certificate of the TLS connection.
+// The server must request client certificates through its tls.Config. It is documented
+// only in OpenAPI 3.1 documents.
+func MutualTLS(name string, verifier Verifier) *SecurityScheme {
+	def := &openapi3.SecurityScheme{Type: SecurityTypeMutualTLS}
+	return &SecurityScheme{Name: name, Definition: def, Verifier: verifier}
+}
+
+// Describe sets the description of the documented scheme and returns s.
+func (s *SecurityScheme) Describe(description string) *SecurityScheme {
+	s.Definition.Description = description
+	return s
+}
+
+// Require returns a RouteOption requiring the scheme with the given scopes. Like
+// WithSecurity, it may be passed several times to accept any of the schemes, and applies
+// to routes and groups.
+func (s *SecurityScheme) Require(scopes ...string) RouteOption {
+	return func(r *RouteRef) {
+		r.Security = append(r.Security, SecurityRequirement{Name: s.Name, Scopes: scopes, Scheme: s})
+	}
+}
+
+// Credentials extracts the credentials call presents for the scheme. ok is false when
+// the request carries none.
+func (s *SecurityScheme) Credentials(call *Call) (creds Credentials, ok bool) {
+	creds.Scheme = s.Name
+	def := s.Definition
+	switch def.Type {
+	case SecurityTypeAPIKey:
+		creds.Token = apiKeyValue(call.Params, def.In, def.Name)
+	case SecurityTypeMutualTLS:
+		if call.TLS == nil || len(call.TLS.PeerCertificates) == 0 {
+			return creds, false
+		}
+		creds.Certificates = call.TLS.PeerCertificates
+		return creds, true
+	default:
+		creds.Token = bearerToken(firstValue(call.Params.HeaderValues("Authorization")))
+	}
+	return creds, creds.Token != ""
+}
+
+func apiKeyValue(params ParameterSource, in, name string) string {
+	switch in {
+	case "query":
+		return firstValue(params.QueryValues(name))
+	case "cookie":
+		value, _ := params.CookieValue(name)
+		return value
+	}
+	return firstValue(params.HeaderValues(name))
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// RegisterSecurityScheme adds s to the default registry.
+func RegisterSecurityScheme(s *SecurityScheme) {
+	globalRegistry.RegisterSecurityScheme(s)
+}
+
+// RegisterSecurityScheme adds s to the registry, replacing a scheme with the same name.
+// Routes of the registry that name s in WithSecurity are then enforced with its Verifier.
+func (r *Registry) RegisterSecurityScheme(s *SecurityScheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.schemes == nil {
+		r.schemes = make(map[string]*SecurityScheme)
+	}
+	r.schemes[s.Name] = s
+}
+
+// SecurityScheme returns the registered scheme called name.
+func (r *Registry) SecurityScheme(name string) (*SecurityScheme, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.schemes[name]
+	return s, ok
+}
+
+// SecuritySchemes returns the registered schemes sorted by name.
+func (r *Registry) SecuritySchemes() []*SecurityScheme {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*SecurityScheme, 0, len(r.schemes))
+	for _, s := range r.schemes {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal adapters stored for the authenticated request.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// SecurityInterceptor returns the interceptor adapters run first on routes with security
+// requirements; ok is false for routes without any. A request passes when one
+// requirement's scheme verifies its credentials and the principal holds the required
+// scopes; the principal is then stored in the context. Requirements of middleware all
+// apply and are checked one by one before the route's own. Schemes named by requirements
+// are looked up in registry per request, so they may be registered after the routes.
+// Requirements whose scheme is unknown or has no Verifier are documentation only: routes
+// with no enforced requirement pass unauthenticated, and routes mixing both kinds reject
+// requests without credentials for a verified alternative unless AllowUnverifiedSecurity
+// is set.
+func SecurityInterceptor(registry *Registry, ref *RouteRef) (in Interceptor, ok bool) {
+	if len(ref.Security) == 0 && len(ref.MiddlewareSecurity) == 0 {
+		return Interceptor{}, false
+	}
+	return Interceptor{
+		BeforeDecode: func(ctx context.Context, call *Call) (context.Context, error) {
+			for _, req := range ref.MiddlewareSecurity {
+				var err error
+				if ctx, err = authenticate(ctx, registry, []SecurityRequirement{req}, call, false); err != nil {
+					return ctx, err
+				}
+			}
+			return authenticate(ctx, registry, ref.Security, call, ref.AllowUnverifiedSecurity)
+		},
+	}, true
+}
+
+func requirementScheme(registry *Registry, req SecurityRequirement) *SecurityScheme {
+	if req.Scheme != nil {
+		return req.Scheme
+	}
+	if registry == nil {
+		return nil
+	}
+	s, _ := registry.SecurityScheme(req.Name)
+	return s
+}
+
+// authenticate tries the requirements in order. It passes without a principal when no
+// requirement is enforced, or when allowUnverified is set, a requirement is documentation
+// only and the request presented no credentials for the enforced ones. Failures are
+// reported as 403 when a principal lacked scopes, as the verifier's status when it returned
+// a StatusCoder, and as 401 otherwise.
+func authenticate(ctx context.Context, registry *Registry, requirements []SecurityRequirement, call *Call, allowUnverified bool) (context.Context, error) {
+	var failure error
+	enforced, unverified := false, false
+	for _, req := range requirements {
+		s := requirementScheme(registry, req)
+		if s == nil || s.Verifier == nil {
+			unverified = true
+			continue
+		}
+		enforced = true
+		creds, ok := s.Credentials(call)
+		if !ok {
+			continue
+		}
+		principal, err := s.Verifier.Verify(ctx, creds)
+		if err != nil || principal == nil {
+			if failure == nil || statusOf(failure) == http.StatusUnauthorized {
+				failure = verifyError(err)
+			}
+			continue
+		}
+		if !principal.HasScopes(req.Scopes...) {
+			failure = Forbidden("insufficient scope")
+			continue
+		}
+		if principal.Scheme == "" {
+			p := *principal
+			p.Scheme = s.Name
+			principal = &p
+		}
+		return WithPrincipal(ctx, principal), nil
+	}
+	if !enforced || (failure == nil && unverified && allowUnverified) {
+		return ctx, nil
+	}
+	if failure == nil {
+		failure = Unauthorized("authentication required")
+	}
+	return ctx, failure
+}
+
+func verifyError(err error) error {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return err
+	}
+	return &HTTPError{Status: http.StatusUnauthorized, Message: "invalid credentials", Code: "UNAUTHORIZED", Err: err}
+}
+
+func statusOf(err error) int {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.HTTPStatus()
+	}
+	return 0
+}
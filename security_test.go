@@ -0,0 +1,231 @@
+package apix_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func tokenVerifier(tokens map[string][]string) apix.Verifier {
+	return apix.VerifierFunc(func(ctx context.Context, creds apix.Credentials) (*apix.Principal, error) {
+		scopes, ok := tokens[creds.Token]
+		if !ok {
+			return nil, errors.New("unknown token")
+		}
+		return &apix.Principal{Subject: "user-" + creds.Token, Scopes: scopes}, nil
+	})
+}
+
+func authenticateRequest(t *testing.T, registry *apix.Registry, ref *apix.RouteRef, r *http.Request) (*apix.Principal, error) {
+	t.Helper()
+	in, ok := apix.SecurityInterceptor(registry, ref)
+	if !ok {
+		t.Fatalf("expected route %s %s to be secured", ref.Method, ref.Path)
+	}
+	call := &apix.Call{Route: ref, Params: apix.NewRequestSource(r, nil), TLS: r.TLS}
+	ctx, err := in.BeforeDecode(context.Background(), call)
+	if err != nil {
+		return nil, err
+	}
+	principal, _ := apix.PrincipalFromContext(ctx)
+	return principal, nil
+}
+
+func TestSecuritySchemeEnforcesBearerTokensAndScopes(t *testing.T) {
+	bearer := apix.HTTPBearer("BearerAuth", "JWT", tokenVerifier(map[string][]string{"reader": {"items:read"}}))
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	bearer.Require("items:read")(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.Header.Set("Authorization", "Bearer reader")
+	principal, err := authenticateRequest(t, nil, ref, r)
+	if err != nil || principal.Subject != "user-reader" || principal.Scheme != "BearerAuth" {
+		t.Fatalf("expected authenticated principal, got %+v %v", principal, err)
+	}
+
+	cases := map[string]struct {
+		header string
+		scopes []string
+		status int
+	}{
+		"missing credentials": {"", []string{"items:read"}, http.StatusUnauthorized},
+		"unknown token":       {"Bearer guest", []string{"items:read"}, http.StatusUnauthorized},
+		"other scheme":        {"Basic cmVhZGVy", []string{"items:read"}, http.StatusUnauthorized},
+		"insufficient scope":  {"Bearer reader", []string{"items:write"}, http.StatusForbidden},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+			bearer.Require(tc.scopes...)(ref)
+			r := httptest.NewRequest(http.MethodGet, "/items", nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			_, err := authenticateRequest(t, nil, ref, r)
+			var coder apix.StatusCoder
+			if !errors.As(err, &coder) || coder.HTTPStatus() != tc.status {
+				t.Fatalf("expected status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+func TestSecuritySchemeReadsAPIKeysFromTheirLocation(t *testing.T) {
+	verifier := tokenVerifier(map[string][]string{"secret": nil})
+	cases := map[string]struct {
+		scheme *apix.SecurityScheme
+		set    func(r *http.Request)
+	}{
+		"header": {apix.APIKeyHeader("HeaderKey", "X-API-Key", verifier), func(r *http.Request) { r.Header.Set("X-API-Key", "secret") }},
+		"query":  {apix.APIKeyQuery("QueryKey", "api_key", verifier), func(r *http.Request) { r.URL.RawQuery = "api_key=secret" }},
+		"cookie": {apix.APIKeyCookie("CookieKey", "session", verifier), func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: "secret"}) }},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+			tc.scheme.Require()(ref)
+			r := httptest.NewRequest(http.MethodGet, "/items", nil)
+			tc.set(r)
+			if principal, err := authenticateRequest(t, nil, ref, r); err != nil || principal.Subject != "user-secret" {
+				t.Fatalf("expected API key to authenticate, got %+v %v", principal, err)
+			}
+		})
+	}
+}
+
+func TestSecuritySchemeVerifiesClientCertificates(t *testing.T) {
+	mtls := apix.MutualTLS("ClientCert", apix.VerifierFunc(func(ctx context.Context, creds apix.Credentials) (*apix.Principal, error) {
+		return &apix.Principal{Subject: creds.Certificates[0].Subject.CommonName}, nil
+	}))
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	mtls.Require()(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if _, err := authenticateRequest(t, nil, ref, r); err == nil {
+		t.Fatalf("expected plain request to be rejected")
+	}
+
+	cert := &x509.Certificate{}
+	cert.Subject.CommonName = "billing-service"
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if principal, err := authenticateRequest(t, nil, ref, r); err != nil || principal.Subject != "billing-service" {
+		t.Fatalf("expected certificate to authenticate, got %+v %v", principal, err)
+	}
+}
+
+func TestSecurityInterceptorResolvesRegisteredSchemes(t *testing.T) {
+	registry := apix.NewRegistry()
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	apix.WithSecurity("ApiKey")(ref)
+	apix.WithSecurity("BearerAuth")(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if _, err := authenticateRequest(t, registry, ref, r); err != nil {
+		t.Fatalf("expected documentation-only requirements to pass, got %v", err)
+	}
+
+	registry.RegisterSecurityScheme(apix.HTTPBearer("BearerAuth", "", tokenVerifier(map[string][]string{"t1": nil})))
+	if _, err := authenticateRequest(t, registry, ref, r); err == nil {
+		t.Fatalf("expected registered scheme to be enforced")
+	}
+	r.Header.Set("Authorization", "Bearer t1")
+	if principal, err := authenticateRequest(t, registry, ref, r); err != nil || principal.Scheme != "BearerAuth" {
+		t.Fatalf("expected alternative requirement to authenticate, got %+v %v", principal, err)
+	}
+
+	if schemes := registry.SecuritySchemes(); len(schemes) != 1 || schemes[0].Name != "BearerAuth" {
+		t.Fatalf("unexpected registered schemes %v", schemes)
+	}
+	registry.Reset()
+	if _, ok := registry.SecurityScheme("BearerAuth"); ok {
+		t.Fatalf("expected reset to clear security schemes")
+	}
+}
+
+func TestSecurityInterceptorKeepsVerifierStatus(t *testing.T) {
+	bearer := apix.HTTPBearer("BearerAuth", "", apix.VerifierFunc(func(ctx context.Context, creds apix.Credentials) (*apix.Principal, error) {
+		return nil, apix.Forbidden("account suspended")
+	}))
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	bearer.Require()(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.Header.Set("Authorization", "Bearer t1")
+	var httpErr *apix.HTTPError
+	if _, err := authenticateRequest(t, nil, ref, r); !errors.As(err, &httpErr) || httpErr.Message != "account suspended" {
+		t.Fatalf("expected verifier error, got %v", err)
+	}
+}
+
+func TestSecurityInterceptorRequiresMiddlewareRequirements(t *testing.T) {
+	registry := apix.NewRegistry()
+	registry.RegisterSecurityScheme(apix.HTTPBearer("BearerAuth", "", tokenVerifier(map[string][]string{"t1": nil})))
+	apiKey := apix.APIKeyHeader("ApiKey", "X-API-Key", tokenVerifier(map[string][]string{"secret": nil}))
+
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	apix.MiddlewareSecurity(apix.SecuredBy(func() {}, "BearerAuth"))(ref)
+	apiKey.Require()(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.Header.Set("X-API-Key", "secret")
+	if _, err := authenticateRequest(t, registry, ref, r); err == nil {
+		t.Fatalf("expected the middleware requirement to apply as well")
+	}
+	r.Header.Set("Authorization", "Bearer t1")
+	if principal, err := authenticateRequest(t, registry, ref, r); err != nil || principal.Scheme != "ApiKey" {
+		t.Fatalf("expected both requirements to pass, got %+v %v", principal, err)
+	}
+}
+
+func TestSecurityInterceptorChecksVerifiedAlternativesNextToUnverifiedOnes(t *testing.T) {
+	bearer := apix.HTTPBearer("BearerAuth", "", tokenVerifier(map[string][]string{"t1": nil}))
+	for _, allow := range []bool{false, true} {
+		ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+		bearer.Require()(ref)
+		apix.WithSecurity("ApiKey")(ref)
+		if allow {
+			apix.AllowUnverifiedSecurity()(ref)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/items", nil)
+		r.Header.Set("Authorization", "Bearer forged")
+		var coder apix.StatusCoder
+		if _, err := authenticateRequest(t, nil, ref, r); !errors.As(err, &coder) || coder.HTTPStatus() != http.StatusUnauthorized {
+			t.Fatalf("allow=%v: expected an invalid bearer token to get 401, got %v", allow, err)
+		}
+
+		r.Header.Del("Authorization")
+		principal, err := authenticateRequest(t, nil, ref, r)
+		if allow && (err != nil || principal != nil) {
+			t.Fatalf("expected the opt-in to pass requests without credentials, got %+v %v", principal, err)
+		}
+		if !allow && (!errors.As(err, &coder) || coder.HTTPStatus() != http.StatusUnauthorized) {
+			t.Fatalf("expected requests without credentials to get 401, got %v", err)
+		}
+	}
+}
+
+func TestSecurityInterceptorCopiesVerifierPrincipal(t *testing.T) {
+	shared := &apix.Principal{Subject: "service"}
+	bearer := apix.HTTPBearer("BearerAuth", "", apix.VerifierFunc(func(ctx context.Context, creds apix.Credentials) (*apix.Principal, error) {
+		return shared, nil
+	}))
+	ref := &apix.RouteRef{Method: apix.MethodGet, Path: "/items"}
+	bearer.Require()(ref)
+
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.Header.Set("Authorization", "Bearer t1")
+	principal, err := authenticateRequest(t, nil, ref, r)
+	if err != nil || principal.Scheme != "BearerAuth" || principal.Subject != "service" {
+		t.Fatalf("expected authenticated principal, got %+v %v", principal, err)
+	}
+	if principal == shared || shared.Scheme != "" {
+		t.Fatalf("expected the verifier's principal to stay untouched, got %+v", shared)
+	}
+}
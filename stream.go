@@ -0,0 +1,179 @@
+package apix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentTypeEventStream is the media type of server-sent event streams.
+const ContentTypeEventStream = "text/event-stream"
+
+// StreamHandlerFunc is the typed handler signature for server-sent event endpoints. The
+// handler sends events until it returns; the stream is closed afterwards.
+//
+// Example:
+//
+//	func jobProgress(ctx context.Context, req *JobRequest, events *apix.EventSender[Progress]) error {
+//	    for p := range jobs.Watch(ctx, req.ID) {
+//	        if err := events.Send(p); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	}
+//
+// An error returned before the first event is handled like any handler error. Once the
+// stream is open the status is committed, so a later error is sent as a final "error"
+// event instead.
+type StreamHandlerFunc[TReq any, TEvent any] func(ctx context.Context, req *TReq, events *EventSender[TEvent]) error
+
+// Event is a server-sent event with optional id, event name and reconnection delay.
+type Event[T any] struct {
+	ID    string
+	Name  string
+	Retry time.Duration
+	Data  T
+}
+
+// EventSender writes server-sent events whose data is the JSON encoding of T. It is safe
+// for concurrent use.
+//
+// Events reach the client as they are sent, so streaming routes have no response for
+// interceptors to inspect: adapters run their BeforeDecode, AfterDecode and OnError hooks,
+// but not AfterHandler.
+type EventSender[T any] struct {
+	ctx   context.Context
+	w     io.Writer
+	open  func()
+	flush func() error
+
+	mu     sync.Mutex
+	opened bool
+}
+
+// NewEventSender returns a sender writing frames to w. open is called once, before the
+// first frame, so adapters can commit the status and headers; a nil open means the stream
+// is already open. flush pushes each frame to the client. Sends fail once ctx is done.
+func NewEventSender[T any](ctx context.Context, w io.Writer, open func(), flush func() error) *EventSender[T] {
+	return &EventSender[T]{ctx: ctx, w: w, open: open, flush: flush, opened: open == nil}
+}
+
+// Send sends data as an unnamed event.
+func (s *EventSender[T]) Send(data T) error {
+	return s.SendEvent(Event[T]{Data: data})
+}
+
+// SendEvent sends ev, including its id, name and retry fields when set.
+func (s *EventSender[T]) SendEvent(ev Event[T]) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return fmt.Errorf("apix: encode event: %w", err)
+	}
+	var frame strings.Builder
+	if err := writeField(&frame, "id", ev.ID); err != nil {
+		return err
+	}
+	if err := writeField(&frame, "event", ev.Name); err != nil {
+		return err
+	}
+	if ev.Retry > 0 {
+		frame.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	frame.WriteString("data: ")
+	frame.Write(data)
+	frame.WriteString("\n\n")
+	return s.write(frame.String())
+}
+
+// Comment sends a comment line, which clients ignore. Use it as a keep-alive or to open
+// the stream before the first event is ready.
+func (s *EventSender[T]) Comment(text string) error {
+	if strings.ContainsAny(text, "\r\n") {
+		return errors.New("apix: event comment must be a single line")
+	}
+	return s.write(": " + text + "\n\n")
+}
+
+// Opened reports whether the status and headers have been sent.
+func (s *EventSender[T]) Opened() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opened
+}
+
+// Close ends the stream after the handler returned err. It opens the stream if nothing was
+// sent and reports err, unless it is a cancellation, as an "error" event carrying
+// {"error": message}. Adapters call it once the handler returns.
+func (s *EventSender[T]) Close(err error) error {
+	if err == nil || s.ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.openLocked()
+		return nil
+	}
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return s.write("event: error\ndata: " + string(data) + "\n\n")
+}
+
+func (s *EventSender[T]) write(frame string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.openLocked()
+	if _, err := io.WriteString(s.w, frame); err != nil {
+		return err
+	}
+	if s.flush != nil {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *EventSender[T]) openLocked() {
+	if s.opened {
+		return
+	}
+	s.opened = true
+	s.open()
+}
+
+func writeField(frame *strings.Builder, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("apix: event %s must be a single line", name)
+	}
+	frame.WriteString(name + ": " + value + "\n")
+	return nil
+}
+
+// SetEventStreamHeaders sets the headers that open a server-sent event stream.
+func SetEventStreamHeaders(h http.Header) {
+	h.Set("Content-Type", ContentTypeEventStream)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+}
+
+// EnsureEventStreamResponse documents the success response of a streaming route as a
+// text/event-stream whose events carry eventType. Adapters call it once options are applied.
+func EnsureEventStreamResponse(r *RouteRef, eventType reflect.Type) {
+	EnsureResponse(r, r.SuccessStatus, eventType)
+	resp := r.Responses[r.SuccessStatus]
+	resp.ContentType = ContentTypeEventStream
+	if resp.Description == "" {
+		resp.Description = "Server-sent event stream"
+	}
+	r.Streaming = true
+}
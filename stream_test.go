@@ -0,0 +1,88 @@
+package apix_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+func TestEventSenderWritesFrames(t *testing.T) {
+	var out strings.Builder
+	opened, flushes := 0, 0
+	events := apix.NewEventSender[sampleResp](context.Background(), &out,
+		func() { opened++ },
+		func() error { flushes++; return nil })
+
+	if events.Opened() {
+		t.Fatalf("expected stream to open lazily")
+	}
+	if err := events.Send(sampleResp{ID: "1"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if err := events.SendEvent(apix.Event[sampleResp]{ID: "2", Name: "update", Retry: 3 * time.Second, Data: sampleResp{ID: "2"}}); err != nil {
+		t.Fatalf("send event: %v", err)
+	}
+	if err := events.Comment("ping"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if err := events.Close(nil); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	want := "data: {\"id\":\"1\"}\n\n" +
+		"id: 2\nevent: update\nretry: 3000\ndata: {\"id\":\"2\"}\n\n" +
+		": ping\n\n"
+	if out.String() != want {
+		t.Fatalf("unexpected frames:\n%q\nwant\n%q", out.String(), want)
+	}
+	if opened != 1 || flushes != 3 {
+		t.Fatalf("expected one open and a flush per frame, got %d opens %d flushes", opened, flushes)
+	}
+	if err := events.SendEvent(apix.Event[sampleResp]{Name: "bad\nname"}); err == nil {
+		t.Fatalf("expected multi-line event name to be rejected")
+	}
+}
+
+func TestEventSenderCloseReportsErrors(t *testing.T) {
+	var out strings.Builder
+	events := apix.NewEventSender[sampleResp](context.Background(), &out, nil, nil)
+	if !events.Opened() {
+		t.Fatalf("expected nil open to mean an open stream")
+	}
+	if err := events.Close(errors.New("job failed")); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if out.String() != "event: error\ndata: {\"error\":\"job failed\"}\n\n" {
+		t.Fatalf("unexpected error frame %q", out.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out.Reset()
+	events = apix.NewEventSender[sampleResp](ctx, &out, nil, nil)
+	if err := events.Send(sampleResp{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled send, got %v", err)
+	}
+	if err := events.Close(context.Canceled); err != nil || out.Len() != 0 {
+		t.Fatalf("expected cancellation to close silently, got %v %q", err, out.String())
+	}
+}
+
+func TestEnsureEventStreamResponse(t *testing.T) {
+	ref := &apix.RouteRef{Method: apix.MethodGet, SuccessStatus: http.StatusOK}
+	apix.EnsureEventStreamResponse(ref, reflect.TypeOf(sampleResp{}))
+
+	resp := ref.Responses[http.StatusOK]
+	if resp == nil || resp.ContentType != apix.ContentTypeEventStream || resp.ModelType != reflect.TypeOf(sampleResp{}) {
+		t.Fatalf("unexpected stream response %+v", resp)
+	}
+	if !ref.Streaming {
+		t.Fatalf("expected route marked as streaming")
+	}
+}
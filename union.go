@@ -0,0 +1,119 @@
+package apix
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// UnionInfo describes the concrete variants registered for an interface type.
+type UnionInfo struct {
+	// Discriminator is the JSON property that names the variant. Empty when the union has
+	// no discriminator; decoders then pick the first variant that decodes strictly.
+	Discriminator string
+	// AnyOf documents the union with anyOf instead of oneOf.
+	AnyOf    bool
+	Variants []UnionVariant
+}
+
+// UnionVariant is one concrete type of a union together with its discriminator value.
+type UnionVariant struct {
+	Type reflect.Type
+	Name string
+}
+
+// UnionOption configures a union registered with RegisterUnion.
+type UnionOption func(*UnionInfo)
+
+// Discriminator names the JSON property that identifies the variant of a union.
+func Discriminator(property string) UnionOption {
+	return func(u *UnionInfo) { u.Discriminator = property }
+}
+
+// AnyOf documents the union with anyOf instead of oneOf.
+func AnyOf() UnionOption {
+	return func(u *UnionInfo) { u.AnyOf = true }
+}
+
+// Variant declares a union variant with an explicit discriminator value. Variants passed
+// to RegisterUnion without Variant use their type name.
+func Variant(value any, name string) UnionVariant {
+	return UnionVariant{Type: reflect.TypeOf(value), Name: name}
+}
+
+var unionRegistry = struct {
+	mu     sync.RWMutex
+	unions map[reflect.Type]UnionInfo
+}{unions: make(map[reflect.Type]UnionInfo)}
+
+// RegisterUnion declares the concrete variants of the interface type T. Arguments are
+// variant values (Circle{}, &Square{}), Variant declarations or UnionOptions. The OpenAPI
+// builder documents T as oneOf the variants, and the adapters' default decoders decode
+// T-typed request fields into the variant selected by the discriminator.
+//
+// Example:
+//
+//	apix.RegisterUnion[Shape](Circle{}, apix.Variant(Square{}, "square"), apix.Discriminator("kind"))
+//
+// RegisterUnion panics when T is not an interface or a variant does not implement T.
+func RegisterUnion[T any](variants ...any) {
+	iface := reflect.TypeFor[T]()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("apix: RegisterUnion requires an interface type, got %s", iface))
+	}
+
+	var info UnionInfo
+	for _, arg := range variants {
+		var variant UnionVariant
+		switch v := arg.(type) {
+		case UnionOption:
+			v(&info)
+			continue
+		case UnionVariant:
+			variant = v
+		default:
+			variant = UnionVariant{Type: reflect.TypeOf(arg)}
+		}
+		if variant.Type == nil || !variant.Type.Implements(iface) {
+			panic(fmt.Sprintf("apix: union variant %v does not implement %s", variant.Type, iface))
+		}
+		if variant.Name == "" {
+			variant.Name = derefType(variant.Type).Name()
+		}
+		info.Variants = append(info.Variants, variant)
+	}
+
+	unionRegistry.mu.Lock()
+	defer unionRegistry.mu.Unlock()
+	unionRegistry.unions[iface] = info
+	fieldDecodeCache.Clear()
+}
+
+// UnregisterUnion removes the registration for T.
+func UnregisterUnion[T any]() {
+	unionRegistry.mu.Lock()
+	defer unionRegistry.mu.Unlock()
+	delete(unionRegistry.unions, reflect.TypeFor[T]())
+	fieldDecodeCache.Clear()
+}
+
+// LookupUnion returns the registration for the interface type t.
+func LookupUnion(t reflect.Type) (UnionInfo, bool) {
+	if t == nil || t.Kind() != reflect.Interface {
+		return UnionInfo{}, false
+	}
+	unionRegistry.mu.RLock()
+	defer unionRegistry.mu.RUnlock()
+	info, ok := unionRegistry.unions[t]
+	return info, ok
+}
+
+// variantFor returns the variant registered under name.
+func (u UnionInfo) variantFor(name string) (UnionVariant, bool) {
+	for _, v := range u.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return UnionVariant{}, false
+}
@@ -0,0 +1,124 @@
+package apix_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (c circle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Kind string  `json:"kind"`
+	Side float64 `json:"side"`
+}
+
+func (s *square) area() float64 { return s.Side * s.Side }
+
+type drawing struct {
+	Title   string           `json:"title"`
+	Primary shape            `json:"primary"`
+	Shapes  []shape          `json:"shapes"`
+	Named   map[string]shape `json:"named,omitempty"`
+	Spare   *shape           `json:"spare,omitempty"`
+}
+
+func registerShapes(t *testing.T, opts ...any) {
+	t.Helper()
+	args := append([]any{circle{}, apix.Variant(&square{}, "square")}, opts...)
+	apix.RegisterUnion[shape](args...)
+	t.Cleanup(apix.UnregisterUnion[shape])
+}
+
+func decodeDrawing(body string) (drawing, error) {
+	var d drawing
+	err := apix.DecodeJSON(json.NewDecoder(strings.NewReader(body)), &d, true)
+	return d, err
+}
+
+func TestRegisterUnion(t *testing.T) {
+	registerShapes(t, apix.Discriminator("kind"))
+
+	info, ok := apix.LookupUnion(reflect.TypeFor[shape]())
+	if !ok || info.Discriminator != "kind" || info.AnyOf {
+		t.Fatalf("unexpected union %+v", info)
+	}
+	if len(info.Variants) != 2 || info.Variants[0].Name != "circle" || info.Variants[1].Type != reflect.TypeOf(&square{}) {
+		t.Fatalf("unexpected variants %+v", info.Variants)
+	}
+
+	for name, register := range map[string]func(){
+		"not an interface":   func() { apix.RegisterUnion[circle](circle{}) },
+		"does not implement": func() { apix.RegisterUnion[shape](square{}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: expected panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+func TestDecodeJSONUsesDiscriminator(t *testing.T) {
+	registerShapes(t, apix.Discriminator("kind"))
+
+	d, err := decodeDrawing(`{"title":"t","primary":{"kind":"circle","radius":2},"shapes":[{"kind":"square","side":3},{"kind":"circle","radius":1}],"named":{"a":{"kind":"square","side":1}},"spare":{"kind":"circle","radius":5}}`)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if c, ok := d.Primary.(circle); !ok || c.Radius != 2 {
+		t.Fatalf("unexpected primary %#v", d.Primary)
+	}
+	if s, ok := d.Shapes[0].(*square); !ok || s.Side != 3 {
+		t.Fatalf("expected pointer variant, got %#v", d.Shapes[0])
+	}
+	if _, ok := d.Named["a"].(*square); !ok {
+		t.Fatalf("unexpected map value %#v", d.Named)
+	}
+	if d.Spare == nil || (*d.Spare).area() != 75 {
+		t.Fatalf("unexpected spare %#v", d.Spare)
+	}
+
+	for body, want := range map[string]string{
+		`{"primary":{"radius":2}}`:                       `missing discriminator "kind"`,
+		`{"primary":{"kind":"hexagon"}}`:                 `unknown kind "hexagon"`,
+		`{"primary":{"kind":"circle","radius":2,"x":1}}`: `unknown field "x"`,
+		`{"primary":{"kind":"circle"},"colour":"red"}`:   `unknown field "colour"`,
+		`{"primary":{"kind":"circle","radius":"wide"}}`:  `cannot unmarshal string`,
+	} {
+		if _, err := decodeDrawing(body); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected error containing %q, got %v", body, want, err)
+		}
+	}
+}
+
+func TestDecodeJSONWithoutDiscriminatorPicksFirstMatch(t *testing.T) {
+	registerShapes(t)
+
+	d, err := decodeDrawing(`{"primary":{"side":4},"shapes":[{"radius":1}]}`)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := d.Primary.(*square); !ok {
+		t.Fatalf("expected square, got %#v", d.Primary)
+	}
+	if _, ok := d.Shapes[0].(circle); !ok {
+		t.Fatalf("expected circle, got %#v", d.Shapes[0])
+	}
+	if _, err := decodeDrawing(`{"primary":{"edges":6}}`); err == nil {
+		t.Fatal("expected no variant to match")
+	}
+}
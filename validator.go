@@ -0,0 +1,303 @@
+package apix
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Infra-Forge/infra-apix/internal/validation"
+)
+
+const validationErrorCode = "VALIDATION_FAILED"
+
+// Validator validates a decoded request. Adapters call it after the body has been decoded and
+// parameters have been bound; failures are reported as 422 Unprocessable Entity.
+type Validator interface {
+	Validate(any) error
+}
+
+// ValidatorFunc adapts an ordinary function to the Validator interface.
+//
+// Example (wrapping go-playground/validator):
+//
+//	v := validator.New()
+//	opts := chiadapter.Options{Validator: apix.ValidatorFunc(v.Struct)}
+type ValidatorFunc func(any) error
+
+// Validate implements Validator.
+func (f ValidatorFunc) Validate(v any) error { return f(v) }
+
+// FieldError describes one failed validation rule.
+type FieldError struct {
+	// Path is a JSON pointer to the offending value, e.g. "/items/0/name".
+	// Parameters use their parameter name, e.g. "/limit".
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every failed rule of a request. It implements StatusCoder (422)
+// and is rendered with per-field details in both plain and Problem Details modes.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.Path + " " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// HTTPStatus implements the StatusCoder interface.
+func (e *ValidationError) HTTPStatus() int {
+	return http.StatusUnprocessableEntity
+}
+
+// ErrorResponse returns the plain-mode body for the error, with field errors as details.
+func (e *ValidationError) ErrorResponse() ErrorResponse {
+	return ErrorResponse{
+		Code:    validationErrorCode,
+		Message: "request validation failed",
+		Details: e.Errors,
+	}
+}
+
+// ProblemDetails returns the RFC 9457 body for the error, with field errors in the "errors" member.
+func (e *ValidationError) ProblemDetails() *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:   "about:blank#" + validationErrorCode,
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: "request validation failed",
+	}
+	return problem.WithExtension("errors", e.Errors)
+}
+
+// TagValidator enforces the `validate` and `binding` struct tags that the OpenAPI builder
+// documents: required, omitempty, min, max, gt, gte, lt, lte, len, oneof, unique, dive and
+// string formats such as email, url, uuid, hostname, ipv4, ipv6, alpha and alphanum.
+// Unknown rules are ignored so tags written for go-playground/validator remain usable.
+type TagValidator struct {
+	plans sync.Map // reflect.Type -> []fieldPlan
+}
+
+type fieldPlan struct {
+	index    int
+	name     string
+	rules    validation.Rules
+	embedded bool
+}
+
+// NewTagValidator constructs a TagValidator.
+func NewTagValidator() *TagValidator {
+	return &TagValidator{}
+}
+
+var defaultValidator = NewTagValidator()
+
+// DefaultValidator returns the validator adapters use when Options.Validator is nil.
+func DefaultValidator() Validator {
+	return defaultValidator
+}
+
+var noValidation = ValidatorFunc(func(any) error { return nil })
+
+// NoValidation returns a validator that accepts every request. Set it as Options.Validator
+// to turn request validation off.
+func NoValidation() Validator {
+	return noValidation
+}
+
+// Validate implements Validator. It returns a *ValidationError listing every failed rule.
+func (tv *TagValidator) Validate(v any) error {
+	if v == nil {
+		return nil
+	}
+	var errs []FieldError
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Struct {
+		tv.validateStruct("", val, &errs)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// Validate runs v against dst, falling back to DefaultValidator when v is nil. Errors that do
+// not carry an HTTP status are wrapped as 422 HTTPErrors so every adapter reports them alike.
+func Validate(v Validator, dst any) error {
+	if v == nil {
+		v = defaultValidator
+	}
+	err := v.Validate(dst)
+	if err == nil {
+		return nil
+	}
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		return err
+	}
+	return &HTTPError{
+		Status:  http.StatusUnprocessableEntity,
+		Message: err.Error(),
+		Code:    validationErrorCode,
+		Err:     err,
+	}
+}
+
+func (tv *TagValidator) validateStruct(prefix string, val reflect.Value, errs *[]FieldError) {
+	for _, plan := range tv.plansFor(val.Type()) {
+		field := val.Field(plan.index)
+		if plan.embedded {
+			for field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					break
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				tv.validateStruct(prefix, field, errs)
+			}
+			continue
+		}
+		tv.validateValue(prefix+"/"+escapePointer(plan.name), field, plan.rules, errs)
+	}
+}
+
+func (tv *TagValidator) validateValue(path string, val reflect.Value, rules validation.Rules, errs *[]FieldError) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			if rules.Has("required") {
+				*errs = append(*errs, FieldError{Path: path, Rule: "required", Message: "is required"})
+			}
+			return
+		}
+		val = val.Elem()
+	}
+
+	if rules.Has("omitempty") && val.IsZero() {
+		return
+	}
+
+	for _, rule := range rules.Field {
+		switch rule.Name {
+		case "omitempty":
+			continue
+		case "required":
+			if isMissing(val) {
+				*errs = append(*errs, FieldError{Path: path, Rule: rule.Name, Message: "is required"})
+				return
+			}
+			continue
+		}
+		if msg, ok := validation.Check(rule, val); !ok {
+			*errs = append(*errs, FieldError{Path: path, Rule: rule.Name, Param: rule.Param, Message: msg})
+			return
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		tv.validateStruct(path, val, errs)
+	case reflect.Slice, reflect.Array:
+		if rules.Items == nil {
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			tv.validateValue(path+"/"+strconv.Itoa(i), val.Index(i), *rules.Items, errs)
+		}
+	case reflect.Map:
+		if rules.Items == nil {
+			return
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			tv.validateValue(path+"/"+escapePointer(mapKeyString(iter.Key())), iter.Value(), *rules.Items, errs)
+		}
+	}
+}
+
+func (tv *TagValidator) plansFor(t reflect.Type) []fieldPlan {
+	if cached, ok := tv.plans.Load(t); ok {
+		return cached.([]fieldPlan)
+	}
+	var plans []fieldPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Like encoding/json, promote fields of embedded structs even when the type is unexported.
+		if field.Anonymous && field.Tag.Get("json") == "" && derefType(field.Type).Kind() == reflect.Struct {
+			plans = append(plans, fieldPlan{index: i, embedded: true})
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := validationFieldName(field)
+		if skip {
+			continue
+		}
+		plans = append(plans, fieldPlan{index: i, name: name, rules: validation.FieldRules(field)})
+	}
+	actual, _ := tv.plans.LoadOrStore(t, plans)
+	return actual.([]fieldPlan)
+}
+
+// validationFieldName reports the name used in error paths: the parameter name for
+// path/query/header/cookie fields, the form name for form-tagged fields, otherwise the
+// JSON name.
+func validationFieldName(field reflect.StructField) (string, bool) {
+	if _, name, ok := ParameterTag(field); ok {
+		return name, false
+	}
+	if _, ok := field.Tag.Lookup("form"); ok {
+		return FormFieldName(field)
+	}
+	tag := field.Tag.Get("json")
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", true
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// isMissing mirrors go-playground's "required": nil for reference kinds, zero otherwise.
+func isMissing(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if key.CanInterface() {
+		return fmt.Sprint(key.Interface())
+	}
+	return key.String()
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,161 @@
+package apix_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	apix "github.com/Infra-Forge/infra-apix"
+)
+
+type validatedAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=5,numeric"`
+}
+
+type validatedBase struct {
+	Tenant string `json:"tenant" validate:"required"`
+}
+
+type validatedRequest struct {
+	validatedBase
+	Limit    int                `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Name     string             `json:"name" validate:"required,min=2,max=10"`
+	Email    string             `json:"email" validate:"required,email"`
+	Kind     string             `json:"kind" binding:"oneof=income expense"`
+	Website  *string            `json:"website,omitempty" validate:"omitempty,url"`
+	Owner    string             `json:"owner" validate:"omitempty,uuid4"`
+	Tags     []string           `json:"tags" validate:"max=2,unique,dive,alpha"`
+	Address  validatedAddress   `json:"address"`
+	Contacts []validatedAddress `json:"contacts" validate:"dive"`
+	Scores   map[string]int     `json:"scores" validate:"dive,gte=0"`
+	Skipped  string             `json:"-" validate:"required"`
+}
+
+func validRequest() validatedRequest {
+	return validatedRequest{
+		validatedBase: validatedBase{Tenant: "acme"},
+		Name:          "widget",
+		Email:         "ops@example.com",
+		Kind:          "income",
+		Owner:         "0b1c2d3e-4f50-4a61-8b72-9c8d7e6f5a4b",
+		Tags:          []string{"red"},
+		Address:       validatedAddress{City: "Oslo", Zip: "01234"},
+	}
+}
+
+func TestTagValidatorAcceptsValidRequest(t *testing.T) {
+	req := validRequest()
+	if err := apix.NewTagValidator().Validate(&req); err != nil {
+		t.Fatalf("expected valid request, got %v", err)
+	}
+}
+
+func TestTagValidatorReportsFieldErrors(t *testing.T) {
+	website := "not a url"
+	req := validRequest()
+	req.Tenant = ""
+	req.Limit = 500
+	req.Name = "x"
+	req.Email = "nope"
+	req.Kind = "gift"
+	req.Website = &website
+	req.Owner = "0b1c2d3e-4f50-1a61-8b72-9c8d7e6f5a4b"
+	req.Tags = []string{"a1", "b", "c"}
+	req.Address.City = ""
+	req.Contacts = []validatedAddress{{City: "Bergen"}, {Zip: "12"}}
+	req.Scores = map[string]int{"a/b": -1}
+
+	err := apix.NewTagValidator().Validate(&req)
+	var verr *apix.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	got := map[string]string{}
+	for _, fe := range verr.Errors {
+		got[fe.Path] = fe.Rule
+	}
+	want := map[string]string{
+		"/tenant":          "required",
+		"/limit":           "lte",
+		"/name":            "min",
+		"/email":           "email",
+		"/kind":            "oneof",
+		"/website":         "url",
+		"/owner":           "uuid4",
+		"/tags":            "max",
+		"/address/city":    "required",
+		"/contacts/1/city": "required",
+		"/contacts/1/zip":  "len",
+		"/scores/a~1b":     "gte",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected field errors:\n got %v\nwant %v", got, want)
+	}
+	if verr.HTTPStatus() != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", verr.HTTPStatus())
+	}
+}
+
+func TestTagValidatorDivesIntoItems(t *testing.T) {
+	req := validRequest()
+	req.Tags = []string{"ok", "n0"}
+
+	err := apix.NewTagValidator().Validate(&req)
+	var verr *apix.ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 {
+		t.Fatalf("expected a single error, got %v", err)
+	}
+	if fe := verr.Errors[0]; fe.Path != "/tags/1" || fe.Rule != "alpha" || fe.Message != "must contain only letters" {
+		t.Fatalf("unexpected field error %+v", fe)
+	}
+}
+
+func TestValidateWrapsForeignErrors(t *testing.T) {
+	err := apix.Validate(apix.ValidatorFunc(func(any) error { return errors.New("bad input") }), struct{}{})
+	var httpErr *apix.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusUnprocessableEntity || httpErr.Code != "VALIDATION_FAILED" {
+		t.Fatalf("expected 422 HTTPError, got %#v", err)
+	}
+
+	if err := apix.Validate(nil, &validatedAddress{City: "Oslo"}); err != nil {
+		t.Fatalf("default validator rejected valid value: %v", err)
+	}
+	if err := apix.Validate(nil, &validatedAddress{}); err == nil {
+		t.Fatal("expected default validator to enforce tags")
+	}
+	if err := apix.Validate(apix.NoValidation(), &validatedAddress{}); err != nil {
+		t.Fatalf("expected NoValidation to accept every value, got %v", err)
+	}
+}
+
+func TestValidationErrorRendering(t *testing.T) {
+	verr := &apix.ValidationError{Errors: []apix.FieldError{{Path: "/name", Rule: "required", Message: "is required"}}}
+
+	if verr.Error() != "validation failed: /name is required" {
+		t.Fatalf("unexpected message %q", verr.Error())
+	}
+
+	plain := verr.ErrorResponse()
+	if plain.Code != "VALIDATION_FAILED" || !reflect.DeepEqual(plain.Details, verr.Errors) {
+		t.Fatalf("unexpected plain response %+v", plain)
+	}
+
+	data, err := json.Marshal(apix.ToProblemDetails(verr))
+	if err != nil {
+		t.Fatalf("marshal problem: %v", err)
+	}
+	var decoded struct {
+		Status int               `json:"status"`
+		Errors []apix.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if decoded.Status != http.StatusUnprocessableEntity || len(decoded.Errors) != 1 || decoded.Errors[0].Path != "/name" {
+		t.Fatalf("unexpected problem details %s", data)
+	}
+}